@@ -717,8 +717,11 @@ func fetchTimezoneOffset(tz *TzInfo, ts int64) (*TTInfo, int64) {
 		return nil, 0
 	}
 
-	// If there are no transitions, use type 0 or POSIX info
+	// If there are no transitions, use POSIX info or type 0
 	if tz.Bit64.Timecnt == 0 || len(tz.Trans) == 0 {
+		if tz.PosixInfo != nil {
+			return fetchPosixTimezoneOffset(tz, ts)
+		}
 		if tz.Bit64.Typecnt == 1 && len(tz.Type) > 0 {
 			return &tz.Type[0], INT64_MIN
 		}
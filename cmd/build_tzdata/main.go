@@ -0,0 +1,80 @@
+// Command build_tzdata generates the embedded timezone database files
+// (tzdata_index.bin, tzdata_data.bin and tzdata_version.txt) from a compiled
+// zoneinfo directory, a zip archive of TZif files or the IANA tzdata source
+// files.
+//
+// Usage:
+//
+//	go run ./cmd/build_tzdata -zoneinfo /usr/share/zoneinfo
+//	go run ./cmd/build_tzdata -zip $(go env GOROOT)/lib/time/zoneinfo.zip -version 2025b
+//	go run ./cmd/build_tzdata -source ./tzdata-2025b -version 2025.2
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	timelib "github.com/eutychus/timelib"
+)
+
+func main() {
+	zoneinfo := flag.String("zoneinfo", "", "compiled zoneinfo directory to read")
+	archive := flag.String("zip", "", "zip archive of compiled zoneinfo files to read")
+	source := flag.String("source", "", "directory with IANA tzdata source files (tzdata.zi or africa, europe, ...)")
+	version := flag.String("version", "", "version string to stamp (default: detected from the input)")
+	output := flag.String("output", ".", "directory to write the database files to")
+	flag.Parse()
+
+	inputs := 0
+	for _, input := range []string{*zoneinfo, *archive, *source} {
+		if input != "" {
+			inputs++
+		}
+	}
+	if inputs != 1 {
+		fmt.Fprintln(os.Stderr, "Exactly one of -zoneinfo, -zip and -source must be given")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var tzdb *timelib.TzDB
+	var err error
+	switch {
+	case *zoneinfo != "":
+		tzdb, err = timelib.ZoneinfoDir(*zoneinfo)
+	case *archive != "":
+		tzdb, err = timelib.ZoneinfoZip(*archive)
+	default:
+		tzdb, err = timelib.CompileTzSourceDir(*source)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *version != "" {
+		tzdb.Version = *version
+	}
+	if tzdb.Version == "" || tzdb.Version == "custom" {
+		fmt.Fprintln(os.Stderr, "Error: cannot determine the database version, use -version")
+		os.Exit(1)
+	}
+
+	if err := timelib.WriteTzDBFiles(*output, tzdb); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	info, err := os.Stat(filepath.Join(*output, "tzdata_data.bin"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("Extracted timezone database:")
+	fmt.Printf("  Index: %d entries\n", len(tzdb.Index))
+	fmt.Printf("  Data: %d bytes\n", info.Size())
+	fmt.Printf("  Version: %s\n", tzdb.Version)
+}
@@ -0,0 +1,84 @@
+// Command diff_tzdata compares two timezone databases zone by zone and
+// reports added and removed identifiers and changed transitions, POSIX rules
+// and abbreviations, as text or JSON. It exits with status 1 if the databases
+// differ, like diff(1).
+//
+// A database is "builtin" for the embedded database, a compiled zoneinfo
+// directory or a zip archive of TZif files.
+//
+// Usage:
+//
+//	go run ./cmd/diff_tzdata -new /usr/share/zoneinfo
+//	go run ./cmd/diff_tzdata -old builtin -new $(go env GOROOT)/lib/time/zoneinfo.zip -from 2000 -to 2040
+//	go run ./cmd/diff_tzdata -new /usr/share/zoneinfo -json
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	timelib "github.com/eutychus/timelib"
+)
+
+func main() {
+	oldSpec := flag.String("old", "builtin", "database to compare from: builtin, a zoneinfo directory or a zip archive")
+	newSpec := flag.String("new", "", "database to compare to: builtin, a zoneinfo directory or a zip archive")
+	from := flag.Int64("from", 1970, "first year of the compared range")
+	to := flag.Int64("to", 2038, "year after the compared range")
+	asJSON := flag.Bool("json", false, "write the report as JSON")
+	flag.Parse()
+
+	if *newSpec == "" {
+		fmt.Fprintln(os.Stderr, "The -new database must be given")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	oldDB, err := loadDatabase(*oldSpec)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	newDB, err := loadDatabase(*newSpec)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	diff, err := timelib.DiffTzDB(oldDB, newDB, timelib.TsAtStartOfYear(*from), timelib.TsAtStartOfYear(*to))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(diff)
+	} else {
+		err = diff.WriteText(os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	if !diff.Empty() {
+		os.Exit(1)
+	}
+}
+
+// loadDatabase opens the database named on the command line
+func loadDatabase(spec string) (*timelib.TzDB, error) {
+	switch {
+	case spec == "builtin":
+		return timelib.BuiltinDB(), nil
+	case strings.HasSuffix(strings.ToLower(spec), ".zip"):
+		return timelib.ZoneinfoZip(spec)
+	default:
+		return timelib.ZoneinfoDir(spec)
+	}
+}
@@ -0,0 +1,471 @@
+package timelib
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// cronMacros are the predefined schedules
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronMonthNames and cronDayNames are the names of the month and day of the
+// week fields, starting at their lowest value
+var cronMonthNames = []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+var cronDayNames = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+
+// cronSearchDays limits the search for the next or previous fire time to one
+// 400 year Gregorian cycle, after which every schedule repeats
+const cronSearchDays = 146097
+
+// CronSchedule is a parsed cron expression such as "30 2 * * 1-5". It has
+// five fields (minute, hour, day of month, month, day of week) or six, with
+// the seconds first. The fields accept lists, ranges, steps ("*/15"), month
+// and day names, "?" for "*" and the extensions "L" (last day of the month,
+// or "L-3" three days before it), "15W" (nearest weekday to the 15th), "LW"
+// (last weekday of the month), "5L" (last Friday) and "5#3" (third Friday).
+// As in Vixie cron, a day matches if either the day of month or the day of
+// week matches when both are restricted.
+//
+// Fire times are local wall clock times in a zone, converted to instants the
+// way UpdateTS does: a time in the gap when DST starts fires after the gap
+// (02:30 becomes 03:30), and a time in the hour repeated when DST ends fires
+// once, in the first pass of that hour.
+type CronSchedule struct {
+	Spec string
+
+	second, minute, hour uint64 // bit per value
+	dom, month, dow      uint64 // bit per day 1-31, month 1-12 and weekday 0-6
+	domStar, dowStar     bool
+
+	domLast        []int64        // "L" and "L-n" as days before the last day
+	domWeekday     []int64        // "nW" as the day n
+	domLastWeekday bool           // "LW"
+	dowNth         []RRuleWeekday // "d#n", and "dL" with N set to -1
+}
+
+// ParseCron parses a cron expression with five or six fields, or one of the
+// macros @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly
+func ParseCron(spec string) (*CronSchedule, error) {
+	c := &CronSchedule{Spec: strings.TrimSpace(spec)}
+
+	expr := c.Spec
+	if strings.HasPrefix(expr, "@") {
+		var ok bool
+		if expr, ok = cronMacros[strings.ToLower(expr)]; !ok {
+			return nil, fmt.Errorf("unknown cron macro '%s'", c.Spec)
+		}
+	}
+
+	fields := strings.Fields(expr)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("cron expression '%s' must have 5 or 6 fields, not %d", c.Spec, len(fields))
+	}
+
+	var err error
+	if c.second, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("invalid cron seconds '%s': %v", fields[0], err)
+	}
+	if c.minute, err = parseCronField(fields[1], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("invalid cron minutes '%s': %v", fields[1], err)
+	}
+	if c.hour, err = parseCronField(fields[2], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("invalid cron hours '%s': %v", fields[2], err)
+	}
+	if err = c.parseDayOfMonth(fields[3]); err != nil {
+		return nil, fmt.Errorf("invalid cron day of month '%s': %v", fields[3], err)
+	}
+	if c.month, err = parseCronField(fields[4], 1, 12, cronMonthNames); err != nil {
+		return nil, fmt.Errorf("invalid cron month '%s': %v", fields[4], err)
+	}
+	if err = c.parseDayOfWeek(fields[5]); err != nil {
+		return nil, fmt.Errorf("invalid cron day of week '%s': %v", fields[5], err)
+	}
+
+	return c, nil
+}
+
+// parseCronField parses a list of values, ranges and steps into a bit set
+func parseCronField(field string, min, max int64, names []string) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(item, "/")
+
+		step := int64(1)
+		if hasStep {
+			var err error
+			if step, err = strconv.ParseInt(stepStr, 10, 64); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step '%s'", stepStr)
+			}
+		}
+
+		var lo, hi int64
+		var err error
+		if rng == "*" || rng == "?" {
+			lo, hi = min, max
+		} else if a, b, isRange := strings.Cut(rng, "-"); isRange {
+			if lo, err = cronValue(a, min, max, names); err != nil {
+				return 0, err
+			}
+			if hi, err = cronValue(b, min, max, names); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range '%s'", rng)
+			}
+		} else {
+			if lo, err = cronValue(rng, min, max, names); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if hasStep {
+				hi = max
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// cronValue parses a number or a name of a field
+func cronValue(s string, min, max int64, names []string) (int64, error) {
+	if i := indexOfName(names, s); i >= 0 {
+		return min + int64(i), nil
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value '%s'", s)
+	}
+	if v < min || v > max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", v, min, max)
+	}
+	return v, nil
+}
+
+// parseDayOfMonth parses the day of month field with its "L" and "W" extensions
+func (c *CronSchedule) parseDayOfMonth(field string) error {
+	c.domStar = field == "*" || field == "?"
+
+	var plain []string
+	for _, item := range strings.Split(field, ",") {
+		upper := strings.ToUpper(item)
+		switch {
+		case upper == "L":
+			c.domLast = append(c.domLast, 0)
+		case upper == "LW":
+			c.domLastWeekday = true
+		case strings.HasPrefix(upper, "L-"):
+			n, err := strconv.ParseInt(upper[2:], 10, 64)
+			if err != nil || n < 0 || n > 30 {
+				return fmt.Errorf("invalid offset '%s'", item)
+			}
+			c.domLast = append(c.domLast, n)
+		case strings.HasSuffix(upper, "W"):
+			n, err := cronValue(upper[:len(upper)-1], 1, 31, nil)
+			if err != nil {
+				return err
+			}
+			c.domWeekday = append(c.domWeekday, n)
+		default:
+			plain = append(plain, item)
+		}
+	}
+
+	if len(plain) > 0 {
+		var err error
+		if c.dom, err = parseCronField(strings.Join(plain, ","), 1, 31, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseDayOfWeek parses the day of week field with its "L" and "#" extensions.
+// Both 0 and 7 are Sunday.
+func (c *CronSchedule) parseDayOfWeek(field string) error {
+	c.dowStar = field == "*" || field == "?"
+
+	var plain []string
+	for _, item := range strings.Split(field, ",") {
+		upper := strings.ToUpper(item)
+		if day, n, ok := strings.Cut(upper, "#"); ok {
+			d, err := cronValue(day, 0, 7, cronDayNames)
+			if err != nil {
+				return err
+			}
+			nth, err := strconv.ParseInt(n, 10, 64)
+			if err != nil || nth < 1 || nth > 5 {
+				return fmt.Errorf("invalid occurrence '%s'", item)
+			}
+			c.dowNth = append(c.dowNth, RRuleWeekday{Day: d % 7, N: nth})
+		} else if len(upper) > 1 && strings.HasSuffix(upper, "L") {
+			d, err := cronValue(upper[:len(upper)-1], 0, 7, cronDayNames)
+			if err != nil {
+				return err
+			}
+			c.dowNth = append(c.dowNth, RRuleWeekday{Day: d % 7, N: -1})
+		} else {
+			plain = append(plain, item)
+		}
+	}
+
+	if len(plain) > 0 {
+		bits, err := parseCronField(strings.Join(plain, ","), 0, 7, cronDayNames)
+		if err != nil {
+			return err
+		}
+		if bits&(1<<7) != 0 {
+			bits = bits&^(1<<7) | 1
+		}
+		c.dow = bits
+	}
+	return nil
+}
+
+// String returns the expression the schedule was parsed from
+func (c *CronSchedule) String() string {
+	return c.Spec
+}
+
+// Next returns the first fire time after the given time in tz, or in UTC if
+// tz is nil. It returns false if the schedule never fires again.
+func (c *CronSchedule) Next(after *Time, tz *TzInfo) (*Time, bool) {
+	a := cronSse(after)
+
+	// Start early enough to find times moved forward by a recent DST gap
+	w, ok := c.nextWall(a+min(cronOffset(a, tz), cronOffset(a-SECS_PER_DAY, tz))+1, true)
+
+	var best *Time
+	var bestWall int64
+	for ok {
+		if best != nil && w >= bestWall {
+			break
+		}
+		t, wall := cronInstant(w, tz)
+		if t.Sse > a && (best == nil || t.Sse < best.Sse) {
+			best, bestWall = t, wall
+		}
+		w, ok = c.nextWall(w+1, true)
+	}
+	return best, best != nil
+}
+
+// Prev returns the last fire time before the given time in tz, or in UTC if
+// tz is nil. It returns false if the schedule never fired before.
+func (c *CronSchedule) Prev(before *Time, tz *TzInfo) (*Time, bool) {
+	b := cronSse(before)
+
+	w, ok := c.nextWall(b+max(cronOffset(b, tz), cronOffset(b-SECS_PER_DAY, tz))-1, false)
+
+	var best *Time
+	for ok {
+		t, wall := cronInstant(w, tz)
+		// Times before a DST gap are earlier than those moved forward by it
+		if best != nil && wall == w {
+			break
+		}
+		if t.Sse < b && (best == nil || t.Sse > best.Sse) {
+			best = t
+		}
+		w, ok = c.nextWall(w-1, false)
+	}
+	return best, best != nil
+}
+
+// nextWall returns the first matching local wall clock time, as seconds since
+// 1970-01-01 00:00, at or after w if forward is set, or at or before w otherwise
+func (c *CronSchedule) nextWall(w int64, forward bool) (int64, bool) {
+	day := floorDiv(w, SECS_PER_DAY)
+	tod := w - day*SECS_PER_DAY
+
+	var y, m, d int64
+	Unixtime2date(day*SECS_PER_DAY, &y, &m, &d)
+
+	for i := 0; i < cronSearchDays; i++ {
+		if c.month&(1<<uint(m)) != 0 && c.dayMatches(y, m, d) {
+			if t, ok := c.nextTimeOfDay(tod, forward); ok {
+				return day*SECS_PER_DAY + t, true
+			}
+		}
+
+		if forward {
+			day++
+			tod = 0
+			if d++; d > DaysInMonth(y, m) {
+				d = 1
+				if m++; m > 12 {
+					m = 1
+					y++
+				}
+			}
+		} else {
+			day--
+			tod = SECS_PER_DAY - 1
+			if d--; d < 1 {
+				if m--; m < 1 {
+					m = 12
+					y--
+				}
+				d = DaysInMonth(y, m)
+			}
+		}
+	}
+	return 0, false
+}
+
+// nextTimeOfDay returns the first matching second of the day at or after tod
+// if forward is set, or at or before tod otherwise
+func (c *CronSchedule) nextTimeOfDay(tod int64, forward bool) (int64, bool) {
+	// order maps the k-th value of n in search order to the value
+	order := func(k, n int64) int64 {
+		if forward {
+			return k
+		}
+		return n - 1 - k
+	}
+
+	for hk := int64(0); hk < 24; hk++ {
+		h := order(hk, 24)
+		if c.hour&(1<<uint(h)) == 0 || (forward && h < tod/3600) || (!forward && h > tod/3600) {
+			continue
+		}
+		for ik := int64(0); ik < 60; ik++ {
+			i := order(ik, 60)
+			if c.minute&(1<<uint(i)) == 0 {
+				continue
+			}
+			for sk := int64(0); sk < 60; sk++ {
+				s := order(sk, 60)
+				t := h*3600 + i*60 + s
+				if c.second&(1<<uint(s)) != 0 && ((forward && t >= tod) || (!forward && t <= tod)) {
+					return t, true
+				}
+			}
+		}
+	}
+	return 0, false
+}
+
+// dayMatches checks the day of month and day of week fields for a date
+func (c *CronSchedule) dayMatches(y, m, d int64) bool {
+	domMatch := c.domStar || c.domMatches(y, m, d)
+	dowMatch := c.dowStar || c.dowMatches(y, m, d)
+	if !c.domStar && !c.dowStar {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+// domMatches checks the day of month field for a date
+func (c *CronSchedule) domMatches(y, m, d int64) bool {
+	if c.dom&(1<<uint(d)) != 0 {
+		return true
+	}
+
+	dim := DaysInMonth(y, m)
+	for _, n := range c.domLast {
+		if d == dim-n {
+			return true
+		}
+	}
+	for _, n := range c.domWeekday {
+		if n <= dim && d == nearestWeekday(y, m, n) {
+			return true
+		}
+	}
+	if c.domLastWeekday && d == nearestWeekday(y, m, dim) {
+		return true
+	}
+	return false
+}
+
+// dowMatches checks the day of week field for a date
+func (c *CronSchedule) dowMatches(y, m, d int64) bool {
+	dow := DayOfWeek(y, m, d)
+	if c.dow&(1<<uint(dow)) != 0 {
+		return true
+	}
+	for _, nth := range c.dowNth {
+		if nth.Day != dow {
+			continue
+		}
+		if (nth.N > 0 && (d-1)/7+1 == nth.N) || (nth.N < 0 && d+7 > DaysInMonth(y, m)) {
+			return true
+		}
+	}
+	return false
+}
+
+// nearestWeekday returns the weekday of a month nearest to day d, without
+// leaving the month
+func nearestWeekday(y, m, d int64) int64 {
+	switch DayOfWeek(y, m, d) {
+	case 6:
+		if d == 1 {
+			return d + 2
+		}
+		return d - 1
+	case 0:
+		if d == DaysInMonth(y, m) {
+			return d - 2
+		}
+		return d + 1
+	}
+	return d
+}
+
+// cronSse returns the seconds since the epoch of a time
+func cronSse(t *Time) int64 {
+	if t.SseUptodate {
+		return t.Sse
+	}
+	c := t.Clone()
+	c.UpdateTS(c.TzInfo)
+	return c.Sse
+}
+
+// cronOffset returns the UTC offset of tz at a time
+func cronOffset(ts int64, tz *TzInfo) int64 {
+	if tz == nil {
+		return 0
+	}
+	if offset := GetTimeZoneInfo(ts, tz); offset != nil {
+		return int64(offset.Offset)
+	}
+	return 0
+}
+
+// cronInstant converts a local wall clock time in tz to a time, and returns
+// it with its actual wall clock time, which differs from w in a DST gap
+func cronInstant(w int64, tz *TzInfo) (*Time, int64) {
+	day := floorDiv(w, SECS_PER_DAY)
+	tod := w - day*SECS_PER_DAY
+
+	t := TimeCtor()
+	Unixtime2date(day*SECS_PER_DAY, &t.Y, &t.M, &t.D)
+	t.H, t.I, t.S = tod/3600, tod/60%60, tod%60
+	t.HaveDate, t.HaveTime = true, true
+	if tz != nil {
+		// Without HaveZone, UpdateTS takes the first pass of a repeated hour
+		t.TzInfo = tz
+		t.ZoneType = TIMELIB_ZONETYPE_ID
+	}
+	t.UpdateTS(tz)
+	t.Unixtime2local(t.Sse)
+	return t, rruleWall(t.Y, t.M, t.D, t.H, t.I, t.S)
+}
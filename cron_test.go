@@ -0,0 +1,146 @@
+package timelib
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestCronNext tests fire times in UTC
+func TestCronNext(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"0 9 * * MON-FRI", "2024-01-01 09:00:00, 2024-01-02 09:00:00, 2024-01-03 09:00:00"},
+		{"@hourly", "2024-01-01 01:00:00, 2024-01-01 02:00:00, 2024-01-01 03:00:00"},
+		{"*/20 * * * * *", "2024-01-01 00:00:20, 2024-01-01 00:00:40, 2024-01-01 00:01:00"},
+		{"0 12 * jan,JUL SUN", "2024-01-07 12:00:00, 2024-01-14 12:00:00, 2024-01-21 12:00:00"},
+		{"15 10-14/2 * * *", "2024-01-01 10:15:00, 2024-01-01 12:15:00, 2024-01-01 14:15:00"},
+		{"0 0 L * *", "2024-01-31 00:00:00, 2024-02-29 00:00:00, 2024-03-31 00:00:00"},
+		{"0 0 L-2 * *", "2024-01-29 00:00:00, 2024-02-27 00:00:00, 2024-03-29 00:00:00"},
+		{"0 0 15W 6 *", "2024-06-14 00:00:00, 2025-06-16 00:00:00, 2026-06-15 00:00:00"},
+		{"0 0 1W 6,9 ?", "2024-06-03 00:00:00, 2024-09-02 00:00:00, 2025-06-02 00:00:00"},
+		{"0 0 LW * *", "2024-01-31 00:00:00, 2024-02-29 00:00:00, 2024-03-29 00:00:00"},
+		{"0 0 * * 5L", "2024-01-26 00:00:00, 2024-02-23 00:00:00, 2024-03-29 00:00:00"},
+		{"0 0 ? * FRI#3", "2024-01-19 00:00:00, 2024-02-16 00:00:00, 2024-03-15 00:00:00"},
+		{"0 0 * * 7", "2024-01-07 00:00:00, 2024-01-14 00:00:00, 2024-01-21 00:00:00"},
+		// Either the day of month or the day of week
+		{"0 0 1,15 * MON", "2024-01-08 00:00:00, 2024-01-15 00:00:00, 2024-01-22 00:00:00, 2024-01-29 00:00:00, 2024-02-01 00:00:00"},
+		{"0 0 29 2 *", "2024-02-29 00:00:00, 2028-02-29 00:00:00, 2032-02-29 00:00:00"},
+		{"0 0 30 2 *", ""},
+	}
+
+	start := testTime(2024, 1, 1, 0, 0, 0, nil)
+	for _, test := range tests {
+		c, err := ParseCron(test.spec)
+		if err != nil {
+			t.Errorf("%s: %v", test.spec, err)
+			continue
+		}
+
+		var times []*Time
+		for cur, ok := c.Next(start, nil); ok && len(times) < strings.Count(test.want, ",")+1; cur, ok = c.Next(cur, nil) {
+			times = append(times, cur)
+		}
+		var got []string
+		for _, tm := range times {
+			got = append(got, fmt.Sprintf("%04d-%02d-%02d %02d:%02d:%02d", tm.Y, tm.M, tm.D, tm.H, tm.I, tm.S))
+		}
+		if strings.Join(got, ", ") != test.want {
+			t.Errorf("%s:\n got %s\nwant %s", test.spec, strings.Join(got, ", "), test.want)
+		}
+
+		// Prev walks the same fire times backwards
+		for i := 1; i < len(times); i++ {
+			if prev, ok := c.Prev(times[i], nil); !ok || prev.Sse != times[i-1].Sse {
+				t.Errorf("%s: Prev of %d is not %d", test.spec, times[i].Sse, times[i-1].Sse)
+			}
+		}
+	}
+}
+
+// TestCronDST tests fire times on the days DST starts and ends
+func TestCronDST(t *testing.T) {
+	tz, err := ParseTzfile("America/New_York", BuiltinDB(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		spec  string
+		start *Time
+		want  string
+	}{
+		// 02:30 does not exist on March 10th and fires after the gap
+		{"daily in the gap", "30 2 * * *", testTime(2024, 3, 9, 3, 0, 0, tz),
+			"10 03:30 EDT 1710055800, 11 02:30 EDT 1710138600, 12 02:30 EDT 1710225000"},
+		{"every 30 minutes across the gap", "*/30 * * * *", testTime(2024, 3, 10, 1, 0, 0, tz),
+			"10 01:30 EST 1710052200, 10 03:00 EDT 1710054000, 10 03:30 EDT 1710055800, 10 04:00 EDT 1710057600"},
+		// 01:30 happens twice on November 3rd and fires once
+		{"daily in the repeated hour", "30 1 * * *", testTime(2024, 11, 3, 0, 0, 0, tz),
+			"03 01:30 EDT 1730611800, 04 01:30 EST 1730701800"},
+		{"every 30 minutes across the repeated hour", "*/30 * * * *", testTime(2024, 11, 3, 0, 45, 0, tz),
+			"03 01:00 EDT 1730610000, 03 01:30 EDT 1730611800, 03 02:00 EST 1730617200"},
+	}
+
+	for _, test := range tests {
+		c, err := ParseCron(test.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var times []*Time
+		want := strings.Split(test.want, ", ")
+		for cur, ok := c.Next(test.start, tz); ok && len(times) < len(want); cur, ok = c.Next(cur, tz) {
+			times = append(times, cur)
+		}
+		var got []string
+		for _, tm := range times {
+			got = append(got, fmt.Sprintf("%02d %02d:%02d %s %d", tm.D, tm.H, tm.I, tm.TzAbbr, tm.Sse))
+		}
+		if strings.Join(got, ", ") != test.want {
+			t.Errorf("%s:\n got %s\nwant %s", test.name, strings.Join(got, ", "), test.want)
+		}
+
+		for i := 1; i < len(times); i++ {
+			if prev, ok := c.Prev(times[i], tz); !ok || prev.Sse != times[i-1].Sse {
+				t.Errorf("%s: Prev of %d is not %d", test.name, times[i].Sse, times[i-1].Sse)
+			}
+		}
+	}
+
+	// The second pass of the repeated hour is skipped backwards too
+	c, _ := ParseCron("*/30 * * * *")
+	before := TimeCtor()
+	before.Unixtime2gmt(1730614200) // 01:10 EST
+	if prev, ok := c.Prev(before, tz); !ok || prev.Sse != 1730611800 {
+		t.Errorf("Prev in the repeated hour: got %v", prev)
+	}
+}
+
+// TestParseCronErrors tests invalid cron expressions
+func TestParseCronErrors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * 32W * *",
+		"* * L-31 * *",
+		"* * * * 1#6",
+		"* * * * XYZ",
+		"@reboot",
+	} {
+		if _, err := ParseCron(spec); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+}
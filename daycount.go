@@ -0,0 +1,235 @@
+package timelib
+
+import "fmt"
+
+// Day count conventions, as defined in section 4.16 of the 2006 ISDA
+// Definitions unless noted otherwise
+const (
+	TIMELIB_DAYCOUNT_30_360_US     = iota + 1 // 30/360 US (SIA), with the end of February rules
+	TIMELIB_DAYCOUNT_30E_360                  // 30E/360, the Eurobond basis
+	TIMELIB_DAYCOUNT_30E_360_ISDA             // 30E/360 (ISDA)
+	TIMELIB_DAYCOUNT_ACT_360                  // Actual/360
+	TIMELIB_DAYCOUNT_ACT_365_FIXED            // Actual/365 (Fixed)
+	TIMELIB_DAYCOUNT_ACT_ACT_ISDA             // Actual/Actual (ISDA)
+	TIMELIB_DAYCOUNT_ACT_ACT_ICMA             // Actual/Actual (ICMA), ICMA Rule 251
+	TIMELIB_DAYCOUNT_BUS_252                  // Business/252, as used in Brazil
+)
+
+// HolidayCalendar tells the Business/252 convention which weekdays are not
+// business days. Saturdays and Sundays never are.
+type HolidayCalendar interface {
+	IsHoliday(y, m, d int64) bool
+}
+
+// HolidayFunc adapts a function to a HolidayCalendar
+type HolidayFunc func(y, m, d int64) bool
+
+// IsHoliday calls f(y, m, d)
+func (f HolidayFunc) IsHoliday(y, m, d int64) bool {
+	return f(y, m, d)
+}
+
+// DayCountOptions holds the parameters some conventions need
+type DayCountOptions struct {
+	// Holidays are the non-business weekdays for Business/252; nil for none
+	Holidays HolidayCalendar
+
+	// Frequency is the number of coupons per year for Actual/Actual (ICMA),
+	// one of 1, 2, 3, 4, 6 or 12. CouponDate is any date of the regular
+	// coupon schedule; the end date is used if it is nil.
+	Frequency  int
+	CouponDate *Time
+
+	// EndIsTermination tells 30E/360 (ISDA) that the end date is the
+	// termination date, which is not moved from the end of February
+	EndIsTermination bool
+}
+
+// dayCountDate is a calendar date for day counting
+type dayCountDate struct {
+	y, m, d int64
+}
+
+func (dt dayCountDate) days() int64 {
+	return timelib_epoch_days_from_time(&Time{Y: dt.y, M: dt.m, D: dt.d})
+}
+
+func (dt dayCountDate) lastOfMonth() bool {
+	return dt.d == DaysInMonth(dt.y, dt.m)
+}
+
+// addMonths moves a date by n months, keeping it on the last day of the
+// month if it is on one, and clamping the day to the length of the month
+func (dt dayCountDate) addMonths(n int64) dayCountDate {
+	months := dt.y*12 + dt.m - 1 + n
+	y, m := floorDiv(months, 12), months-floorDiv(months, 12)*12+1
+	d := dt.d
+	if dt.lastOfMonth() || d > DaysInMonth(y, m) {
+		d = DaysInMonth(y, m)
+	}
+	return dayCountDate{y, m, d}
+}
+
+func newDayCountDate(t *Time) (dayCountDate, error) {
+	if t == nil || t.Y == TIMELIB_UNSET || t.M == TIMELIB_UNSET || t.D == TIMELIB_UNSET {
+		return dayCountDate{}, fmt.Errorf("day counts need times with a date")
+	}
+	return dayCountDate{t.Y, t.M, t.D}, nil
+}
+
+// DayCount returns the number of days between the dates of start and end
+// in a day count convention: 30-day months for the 30/360 conventions,
+// business days for Business/252 and actual days otherwise. The time of day
+// and zone are ignored. If end is before start, the count is negative.
+func DayCount(start, end *Time, convention int, options *DayCountOptions) (int64, error) {
+	n, _, err := dayCount(start, end, convention, options)
+	return n, err
+}
+
+// YearFraction returns the length of the period between the dates of start
+// and end in years, in a day count convention such as
+// TIMELIB_DAYCOUNT_ACT_360. If end is before start, the fraction is
+// negative.
+func YearFraction(start, end *Time, convention int, options *DayCountOptions) (float64, error) {
+	_, fraction, err := dayCount(start, end, convention, options)
+	return fraction, err
+}
+
+func dayCount(start, end *Time, convention int, options *DayCountOptions) (int64, float64, error) {
+	d1, err := newDayCountDate(start)
+	if err != nil {
+		return 0, 0, err
+	}
+	d2, err := newDayCountDate(end)
+	if err != nil {
+		return 0, 0, err
+	}
+	if options == nil {
+		options = &DayCountOptions{}
+	}
+
+	sign := int64(1)
+	if d2.days() < d1.days() {
+		d1, d2 = d2, d1
+		sign = -1
+	}
+	actual := d2.days() - d1.days()
+
+	var n int64
+	var fraction float64
+	switch convention {
+	case TIMELIB_DAYCOUNT_30_360_US, TIMELIB_DAYCOUNT_30E_360, TIMELIB_DAYCOUNT_30E_360_ISDA:
+		n = dayCount30360(d1, d2, convention, options.EndIsTermination && sign > 0)
+		fraction = float64(n) / 360
+	case TIMELIB_DAYCOUNT_ACT_360:
+		n, fraction = actual, float64(actual)/360
+	case TIMELIB_DAYCOUNT_ACT_365_FIXED:
+		n, fraction = actual, float64(actual)/365
+	case TIMELIB_DAYCOUNT_ACT_ACT_ISDA:
+		n = actual
+		for y := d1.y; y <= d2.y; y++ {
+			from, to := dayCountDate{y, 1, 1}, dayCountDate{y + 1, 1, 1}
+			if y == d1.y {
+				from = d1
+			}
+			if y == d2.y {
+				to = d2
+			}
+			fraction += float64(to.days()-from.days()) / float64(dayCountYearLength(y))
+		}
+	case TIMELIB_DAYCOUNT_ACT_ACT_ICMA:
+		anchor := d2
+		if options.CouponDate != nil {
+			if anchor, err = newDayCountDate(options.CouponDate); err != nil {
+				return 0, 0, err
+			}
+		}
+		if fraction, err = dayCountICMA(d1, d2, anchor, options.Frequency); err != nil {
+			return 0, 0, err
+		}
+		n = actual
+	case TIMELIB_DAYCOUNT_BUS_252:
+		for day := d1.days(); day < d2.days(); day++ {
+			var y, m, d int64
+			Unixtime2date(day*SECS_PER_DAY, &y, &m, &d)
+			if dow := DayOfWeek(y, m, d); dow == 0 || dow == 6 {
+				continue
+			}
+			if options.Holidays != nil && options.Holidays.IsHoliday(y, m, d) {
+				continue
+			}
+			n++
+		}
+		fraction = float64(n) / 252
+	default:
+		return 0, 0, fmt.Errorf("unsupported day count convention %d", convention)
+	}
+
+	return n * sign, fraction * float64(sign), nil
+}
+
+func dayCountYearLength(y int64) int64 {
+	if IsLeapYear(y) {
+		return 366
+	}
+	return 365
+}
+
+// dayCount30360 counts days with 30-day months, adjusting the days of the
+// month as each convention prescribes
+func dayCount30360(d1, d2 dayCountDate, convention int, termination bool) int64 {
+	day1, day2 := d1.d, d2.d
+	switch convention {
+	case TIMELIB_DAYCOUNT_30_360_US:
+		febEnd1 := d1.m == 2 && d1.lastOfMonth()
+		if febEnd1 && d2.m == 2 && d2.lastOfMonth() {
+			day2 = 30
+		}
+		if febEnd1 || day1 == 31 {
+			day1 = 30
+		}
+		if day2 == 31 && day1 == 30 {
+			day2 = 30
+		}
+	case TIMELIB_DAYCOUNT_30E_360:
+		if day1 == 31 {
+			day1 = 30
+		}
+		if day2 == 31 {
+			day2 = 30
+		}
+	case TIMELIB_DAYCOUNT_30E_360_ISDA:
+		if d1.lastOfMonth() {
+			day1 = 30
+		}
+		if d2.lastOfMonth() && !(termination && d2.m == 2) {
+			day2 = 30
+		}
+	}
+	return 360*(d2.y-d1.y) + 30*(d2.m-d1.m) + day2 - day1
+}
+
+// dayCountICMA returns the Actual/Actual (ICMA) fraction of the period from
+// d1 to d2, adding the share of each notional coupon period of the schedule
+// through anchor that the period covers
+func dayCountICMA(d1, d2, anchor dayCountDate, frequency int) (float64, error) {
+	if frequency <= 0 || 12%frequency != 0 {
+		return 0, fmt.Errorf("unsupported coupon frequency %d", frequency)
+	}
+	step := int64(12 / frequency)
+
+	// Find the notional period that contains d1
+	k := floorDiv((d1.y-anchor.y)*12+d1.m-anchor.m, step) + 1
+	for anchor.addMonths(k*step).days() > d1.days() {
+		k--
+	}
+
+	fraction := 0.0
+	for from := anchor.addMonths(k * step); from.days() < d2.days(); k++ {
+		to := anchor.addMonths((k + 1) * step)
+		lo, hi := max(from.days(), d1.days()), min(to.days(), d2.days())
+		fraction += float64(hi-lo) / float64(int64(frequency)*(to.days()-from.days()))
+		from = to
+	}
+	return fraction, nil
+}
@@ -0,0 +1,108 @@
+package timelib
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// TestYearFractionActualActual tests the examples of the ISDA paper "EMU
+// and Market Conventions: Recent Developments", section 4, rounded to five
+// decimals as there
+func TestYearFractionActualActual(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end *Time
+		coupon     *Time
+		frequency  int
+		isda, icma float64
+	}{
+		{"regular", testTime(2003, 11, 1, 0, 0, 0, nil), testTime(2004, 5, 1, 0, 0, 0, nil), nil, 2, 0.49772, 0.5},
+		{"short first", testTime(1999, 2, 1, 0, 0, 0, nil), testTime(1999, 7, 1, 0, 0, 0, nil), nil, 1, 0.41096, 0.41096},
+		{"long first", testTime(2002, 8, 15, 0, 0, 0, nil), testTime(2003, 7, 15, 0, 0, 0, nil), nil, 1, 0.91507, 0.91507},
+		{"short final", testTime(1999, 7, 30, 0, 0, 0, nil), testTime(2000, 1, 30, 0, 0, 0, nil), nil, 2, 0.50389, 0.5},
+		{"long final", testTime(2000, 1, 30, 0, 0, 0, nil), testTime(2000, 6, 30, 0, 0, 0, nil), testTime(2000, 1, 30, 0, 0, 0, nil), 2, 0.41530, 0.41758},
+	}
+
+	for _, test := range tests {
+		isda, err := YearFraction(test.start, test.end, TIMELIB_DAYCOUNT_ACT_ACT_ISDA, nil)
+		if err != nil || math.Abs(isda-test.isda) > 0.000005 {
+			t.Errorf("%s ISDA: got %.5f (%v), want %.5f", test.name, isda, err, test.isda)
+		}
+
+		options := &DayCountOptions{Frequency: test.frequency, CouponDate: test.coupon}
+		icma, err := YearFraction(test.start, test.end, TIMELIB_DAYCOUNT_ACT_ACT_ICMA, options)
+		if err != nil || math.Abs(icma-test.icma) > 0.000005 {
+			t.Errorf("%s ICMA: got %.5f (%v), want %.5f", test.name, icma, err, test.icma)
+		}
+	}
+
+	if _, err := YearFraction(tests[0].start, tests[0].end, TIMELIB_DAYCOUNT_ACT_ACT_ICMA, &DayCountOptions{Frequency: 5}); err == nil {
+		t.Errorf("expected an error for a frequency that does not divide the year")
+	}
+}
+
+// TestDayCount30360 tests the end of month rules of the 30/360 conventions
+func TestDayCount30360(t *testing.T) {
+	tests := []struct {
+		start, end         *Time
+		us, e, isda, final int64
+	}{
+		{testTime(2007, 2, 28, 0, 0, 0, nil), testTime(2008, 2, 29, 0, 0, 0, nil), 360, 361, 360, 359},
+		{testTime(2007, 1, 31, 0, 0, 0, nil), testTime(2007, 2, 28, 0, 0, 0, nil), 28, 28, 30, 28},
+		{testTime(2008, 2, 29, 0, 0, 0, nil), testTime(2008, 8, 31, 0, 0, 0, nil), 180, 181, 180, 180},
+		{testTime(2007, 8, 31, 0, 0, 0, nil), testTime(2008, 2, 29, 0, 0, 0, nil), 179, 179, 180, 179},
+		{testTime(2007, 3, 15, 0, 0, 0, nil), testTime(2007, 3, 31, 0, 0, 0, nil), 16, 15, 15, 15},
+		{testTime(2007, 3, 30, 0, 0, 0, nil), testTime(2007, 3, 31, 0, 0, 0, nil), 0, 0, 0, 0},
+	}
+
+	for _, test := range tests {
+		name := fmt.Sprintf("%d-%02d-%02d - %d-%02d-%02d", test.start.Y, test.start.M, test.start.D, test.end.Y, test.end.M, test.end.D)
+		us, _ := DayCount(test.start, test.end, TIMELIB_DAYCOUNT_30_360_US, nil)
+		e, _ := DayCount(test.start, test.end, TIMELIB_DAYCOUNT_30E_360, nil)
+		isda, _ := DayCount(test.start, test.end, TIMELIB_DAYCOUNT_30E_360_ISDA, nil)
+		final, _ := DayCount(test.start, test.end, TIMELIB_DAYCOUNT_30E_360_ISDA, &DayCountOptions{EndIsTermination: true})
+		if us != test.us || e != test.e || isda != test.isda || final != test.final {
+			t.Errorf("%s: got %d %d %d %d, want %d %d %d %d", name, us, e, isda, final, test.us, test.e, test.isda, test.final)
+		}
+	}
+}
+
+// TestYearFraction tests the remaining conventions and the direction
+func TestYearFraction(t *testing.T) {
+	start, end := testTime(2024, 1, 1, 9, 30, 0, nil), testTime(2024, 7, 1, 0, 0, 0, nil)
+	newYear := HolidayFunc(func(y, m, d int64) bool { return m == 1 && d == 1 })
+
+	tests := []struct {
+		name       string
+		convention int
+		options    *DayCountOptions
+		days       int64
+		fraction   float64
+	}{
+		{"ACT/360", TIMELIB_DAYCOUNT_ACT_360, nil, 182, 182.0 / 360},
+		{"ACT/365F", TIMELIB_DAYCOUNT_ACT_365_FIXED, nil, 182, 182.0 / 365},
+		{"30/360 US", TIMELIB_DAYCOUNT_30_360_US, nil, 180, 0.5},
+		{"BUS/252", TIMELIB_DAYCOUNT_BUS_252, nil, 130, 130.0 / 252},
+		{"BUS/252 with holidays", TIMELIB_DAYCOUNT_BUS_252, &DayCountOptions{Holidays: newYear}, 129, 129.0 / 252},
+	}
+	for _, test := range tests {
+		days, err := DayCount(start, end, test.convention, test.options)
+		fraction, _ := YearFraction(start, end, test.convention, test.options)
+		if err != nil || days != test.days || math.Abs(fraction-test.fraction) > 1e-12 {
+			t.Errorf("%s: got %d days, %f (%v), want %d days, %f", test.name, days, fraction, err, test.days, test.fraction)
+		}
+
+		back, _ := YearFraction(end, start, test.convention, test.options)
+		if back != -fraction {
+			t.Errorf("%s: got %f backwards, want %f", test.name, back, -fraction)
+		}
+	}
+
+	if _, err := YearFraction(start, end, 0, nil); err == nil {
+		t.Errorf("expected an error for an unknown convention")
+	}
+	if _, err := YearFraction(TimeCtor(), end, TIMELIB_DAYCOUNT_ACT_360, nil); err == nil {
+		t.Errorf("expected an error for a time without a date")
+	}
+}
@@ -0,0 +1,21 @@
+package timelib
+
+import "fmt"
+
+// testTime returns a local time in tz, or UTC if tz is nil, with an up to
+// date timestamp
+func testTime(y, m, d, h, i, s int64, tz *TzInfo) *Time {
+	t := TimeCtor()
+	t.Y, t.M, t.D, t.H, t.I, t.S = y, m, d, h, i, s
+	if tz != nil {
+		t.TzInfo = tz
+		t.ZoneType = TIMELIB_ZONETYPE_ID
+	}
+	t.UpdateTS(tz)
+	return t
+}
+
+// testTimeString formats the date and time fields of t for comparison
+func testTimeString(t *Time) string {
+	return fmt.Sprintf("%04d-%02d-%02d %02d:%02d:%02d", t.Y, t.M, t.D, t.H, t.I, t.S)
+}
@@ -373,13 +373,13 @@ func TestStrtointervalErrorHandling(t *testing.T) {
 	}{
 		{
 			input:       "P",
-			desc:        "Empty duration - currently accepts it",
-			expectError: false, // Current implementation is lenient
+			desc:        "Empty duration",
+			expectError: true,
 		},
 		{
 			input:       "PT",
-			desc:        "Empty time duration - currently accepts it",
-			expectError: false, // Current implementation is lenient
+			desc:        "Empty time duration",
+			expectError: true,
 		},
 		{
 			input:       "invalid",
@@ -388,8 +388,8 @@ func TestStrtointervalErrorHandling(t *testing.T) {
 		},
 		{
 			input:       "P1X",
-			desc:        "Invalid duration component - currently accepts it",
-			expectError: false, // Current implementation is lenient
+			desc:        "Invalid duration component",
+			expectError: true,
 		},
 		{
 			input:       "2007-03-01T13:00:00Z",
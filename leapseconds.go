@@ -0,0 +1,172 @@
+package timelib
+
+import (
+	"fmt"
+)
+
+// TIMELIB_TAI_UTC_1972 is the TAI-UTC difference in seconds when leap seconds
+// were introduced on 1972-01-01. Earlier instants use the same value.
+const TIMELIB_TAI_UTC_1972 = 10
+
+// builtinLeapSeconds lists the leap seconds announced by the IERS up to
+// Bulletin C 69. Trans is the Unix time at which the total correction Corr
+// starts to apply, i.e. the second after each inserted 23:59:60.
+var builtinLeapSeconds = []TLInfo{
+	{78796800, 1},    // 1972-07-01
+	{94694400, 2},    // 1973-01-01
+	{126230400, 3},   // 1974-01-01
+	{157766400, 4},   // 1975-01-01
+	{189302400, 5},   // 1976-01-01
+	{220924800, 6},   // 1977-01-01
+	{252460800, 7},   // 1978-01-01
+	{283996800, 8},   // 1979-01-01
+	{315532800, 9},   // 1980-01-01
+	{362793600, 10},  // 1981-07-01
+	{394329600, 11},  // 1982-07-01
+	{425865600, 12},  // 1983-07-01
+	{489024000, 13},  // 1985-07-01
+	{567993600, 14},  // 1988-01-01
+	{631152000, 15},  // 1990-01-01
+	{662688000, 16},  // 1991-01-01
+	{709948800, 17},  // 1992-07-01
+	{741484800, 18},  // 1993-07-01
+	{773020800, 19},  // 1994-07-01
+	{820454400, 20},  // 1996-01-01
+	{867715200, 21},  // 1997-07-01
+	{915148800, 22},  // 1999-01-01
+	{1136073600, 23}, // 2006-01-01
+	{1230768000, 24}, // 2009-01-01
+	{1341100800, 25}, // 2012-07-01
+	{1435708800, 26}, // 2015-07-01
+	{1483228800, 27}, // 2017-01-01
+}
+
+// BuiltinLeapSeconds returns a copy of the builtin leap second table
+func BuiltinLeapSeconds() []TLInfo {
+	leaps := make([]TLInfo, len(builtinLeapSeconds))
+	copy(leaps, builtinLeapSeconds)
+	return leaps
+}
+
+// fetchLeaptimeOffset returns the leap second record in effect at ts, where
+// ts is a timestamp in the time scale of the zone (leap seconds included for
+// zones from the "right/" tree)
+func fetchLeaptimeOffset(tz *TzInfo, ts int64) *TLInfo {
+	if tz == nil {
+		return nil
+	}
+
+	for i := len(tz.LeapTimes) - 1; i >= 0; i-- {
+		if ts >= tz.LeapTimes[i].Trans {
+			return &tz.LeapTimes[i]
+		}
+	}
+	return nil
+}
+
+// LeapSecondsFromTzInfo converts the leap records of a zone loaded from the
+// "right/" tree to a table in Unix time, as used by UTCToTAI and TAIToUTC.
+// It returns nil if the zone has no leap second records. A trailing record
+// that does not change the correction (the TZif v4 expiry marker) is dropped.
+func LeapSecondsFromTzInfo(tz *TzInfo) []TLInfo {
+	if tz == nil || len(tz.LeapTimes) == 0 {
+		return nil
+	}
+
+	leaps := make([]TLInfo, 0, len(tz.LeapTimes))
+	prevCorr := int64(0)
+	for _, lt := range tz.LeapTimes {
+		if lt.Corr == prevCorr {
+			continue
+		}
+		// The record time includes the leap seconds inserted before it
+		leaps = append(leaps, TLInfo{Trans: lt.Trans - prevCorr, Corr: lt.Corr})
+		prevCorr = lt.Corr
+	}
+	return leaps
+}
+
+// LoadLeapSeconds reads the leap second table from the "right/UTC" zone of a
+// database created with TIMELIB_ZONEINFO_INCLUDE_RIGHT
+func LoadLeapSeconds(tzdb *TzDB) ([]TLInfo, error) {
+	tz, err := ParseTzfile("right/UTC", tzdb, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	leaps := LeapSecondsFromTzInfo(tz)
+	if leaps == nil {
+		return nil, fmt.Errorf("right/UTC contains no leap second records")
+	}
+	return leaps, nil
+}
+
+// leapCorrection returns the number of leap seconds inserted before the Unix
+// time ts. A nil table selects the builtin leap seconds.
+func leapCorrection(ts int64, leaps []TLInfo) int64 {
+	if leaps == nil {
+		leaps = builtinLeapSeconds
+	}
+
+	for i := len(leaps) - 1; i >= 0; i-- {
+		if ts >= leaps[i].Trans {
+			return leaps[i].Corr
+		}
+	}
+	return 0
+}
+
+// TAIMinusUTC returns the difference between TAI and UTC in seconds at the
+// Unix time ts. A nil table selects the builtin leap seconds.
+func TAIMinusUTC(ts int64, leaps []TLInfo) int64 {
+	return TIMELIB_TAI_UTC_1972 + leapCorrection(ts, leaps)
+}
+
+// UTCToTAI converts a Unix time to seconds since 1970-01-01 00:00:00 TAI
+func UTCToTAI(ts int64, leaps []TLInfo) int64 {
+	return ts + TAIMinusUTC(ts, leaps)
+}
+
+// TAIToUTC converts seconds since 1970-01-01 00:00:00 TAI to a Unix time.
+// Unix time cannot represent an inserted leap second; for those instants the
+// preceding second (23:59:59) is returned and isLeapSecond is set.
+func TAIToUTC(tai int64, leaps []TLInfo) (ts int64, isLeapSecond bool) {
+	if leaps == nil {
+		leaps = builtinLeapSeconds
+	}
+
+	for i := len(leaps) - 1; i >= 0; i-- {
+		if tai >= leaps[i].Trans+TIMELIB_TAI_UTC_1972+leaps[i].Corr {
+			return tai - TIMELIB_TAI_UTC_1972 - leaps[i].Corr, false
+		}
+
+		prevCorr := int64(0)
+		if i > 0 {
+			prevCorr = leaps[i-1].Corr
+		}
+		if leaps[i].Corr > prevCorr && tai >= leaps[i].Trans+TIMELIB_TAI_UTC_1972+prevCorr {
+			return leaps[i].Trans - 1, true
+		}
+	}
+	return tai - TIMELIB_TAI_UTC_1972, false
+}
+
+// LeapSecondsBetween returns the number of leap seconds inserted between the
+// Unix times from and to (negative if to is before from)
+func LeapSecondsBetween(from, to int64, leaps []TLInfo) int64 {
+	return leapCorrection(to, leaps) - leapCorrection(from, leaps)
+}
+
+// DiffWithLeapSeconds returns the number of elapsed SI seconds from one to
+// two, counting the leap seconds inserted in between. Both times need an up
+// to date Sse.
+func DiffWithLeapSeconds(one, two *Time, leaps []TLInfo) (int64, error) {
+	if one == nil || two == nil {
+		return 0, fmt.Errorf("time is nil")
+	}
+	if !one.SseUptodate || !two.SseUptodate {
+		return 0, fmt.Errorf("timestamp is not up to date")
+	}
+
+	return two.Sse - one.Sse + LeapSecondsBetween(one.Sse, two.Sse, leaps), nil
+}
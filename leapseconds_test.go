@@ -0,0 +1,126 @@
+package timelib
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const rightZoneinfoDir = "/usr/share/zoneinfo"
+
+// TestUTCToTAI tests TAI-UTC around leap seconds
+func TestUTCToTAI(t *testing.T) {
+	tests := []struct {
+		name string
+		ts   int64
+		diff int64
+	}{
+		{"epoch", 0, 10},
+		{"before first leap", 78796799, 10},
+		{"after first leap", 78796800, 11},
+		{"2016-12-31 23:59:59", 1483228799, 36},
+		{"2017-01-01 00:00:00", 1483228800, 37},
+		{"2024-06-01", 1717200000, 37},
+	}
+
+	for _, tt := range tests {
+		if got := TAIMinusUTC(tt.ts, nil); got != tt.diff {
+			t.Errorf("%s: TAIMinusUTC = %d, want %d", tt.name, got, tt.diff)
+		}
+		if got := UTCToTAI(tt.ts, nil); got != tt.ts+tt.diff {
+			t.Errorf("%s: UTCToTAI = %d, want %d", tt.name, got, tt.ts+tt.diff)
+		}
+	}
+}
+
+// TestTAIToUTC tests the inverse conversion including the leap second itself
+func TestTAIToUTC(t *testing.T) {
+	for _, ts := range []int64{0, 78796799, 78796800, 1483228799, 1483228800, 1717200000} {
+		got, leap := TAIToUTC(UTCToTAI(ts, nil), nil)
+		if got != ts || leap {
+			t.Errorf("TAIToUTC(UTCToTAI(%d)) = %d, %v", ts, got, leap)
+		}
+	}
+
+	// 2016-12-31 23:59:60 UTC sits between 23:59:59 (TAI +36) and 00:00:00 (TAI +37)
+	tai := int64(1483228799 + 36 + 1)
+	got, leap := TAIToUTC(tai, nil)
+	if got != 1483228799 || !leap {
+		t.Errorf("TAIToUTC(leap second) = %d, %v, want 1483228799, true", got, leap)
+	}
+}
+
+// TestDiffWithLeapSeconds tests elapsed seconds across a leap second
+func TestDiffWithLeapSeconds(t *testing.T) {
+	one := &Time{Sse: 1483228799, SseUptodate: true} // 2016-12-31 23:59:59 UTC
+	two := &Time{Sse: 1483228800, SseUptodate: true} // 2017-01-01 00:00:00 UTC
+
+	diff, err := DiffWithLeapSeconds(one, two, nil)
+	if err != nil {
+		t.Fatalf("DiffWithLeapSeconds failed: %v", err)
+	}
+	if diff != 2 {
+		t.Errorf("Expected 2 elapsed seconds, got %d", diff)
+	}
+
+	diff, _ = DiffWithLeapSeconds(two, one, nil)
+	if diff != -2 {
+		t.Errorf("Expected -2 elapsed seconds, got %d", diff)
+	}
+
+	if n := LeapSecondsBetween(0, 1483228800, nil); n != 27 {
+		t.Errorf("Expected 27 leap seconds since 1970, got %d", n)
+	}
+
+	if _, err := DiffWithLeapSeconds(one, &Time{}, nil); err == nil {
+		t.Error("Expected error for time without up to date Sse")
+	}
+}
+
+// TestRightZoneinfo tests leap second data from the "right/" zoneinfo tree
+func TestRightZoneinfo(t *testing.T) {
+	if _, err := os.Stat(filepath.Join(rightZoneinfoDir, "right", "UTC")); err != nil {
+		t.Skip("right/UTC not available")
+	}
+
+	tzdb, err := ZoneinfoDirWithFlags(rightZoneinfoDir, TIMELIB_ZONEINFO_INCLUDE_RIGHT)
+	if err != nil {
+		t.Fatalf("ZoneinfoDirWithFlags failed: %v", err)
+	}
+	if !TimezoneIDIsValid("right/Europe/London", tzdb) {
+		t.Error("Expected right/Europe/London to be indexed")
+	}
+
+	plain, err := ZoneinfoDir(rightZoneinfoDir)
+	if err != nil {
+		t.Fatalf("ZoneinfoDir failed: %v", err)
+	}
+	if TimezoneIDIsValid("right/Europe/London", plain) {
+		t.Error("right/ should not be indexed by default")
+	}
+
+	leaps, err := LoadLeapSeconds(tzdb)
+	if err != nil {
+		t.Fatalf("LoadLeapSeconds failed: %v", err)
+	}
+	for i := range builtinLeapSeconds {
+		if i >= len(leaps) || leaps[i] != builtinLeapSeconds[i] {
+			t.Fatalf("Leap table mismatch at %d: %v", i, leaps)
+		}
+	}
+
+	// In the right/ time scale, 2017-01-01 00:00:00 UTC is 1483228800 + 27
+	tz, err := ParseTzfile("right/UTC", tzdb, nil)
+	if err != nil {
+		t.Fatalf("ParseTzfile failed: %v", err)
+	}
+	if to := GetTimeZoneInfo(1483228800+27, tz); to.LeapSecs != 27 {
+		t.Errorf("Expected 27 leap seconds, got %d", to.LeapSecs)
+	}
+	if to := GetTimeZoneInfo(1483228800+25, tz); to.LeapSecs != 26 {
+		t.Errorf("Expected 26 leap seconds, got %d", to.LeapSecs)
+	}
+	if to := GetTimeZoneInfo(0, tz); to.LeapSecs != 0 {
+		t.Errorf("Expected 0 leap seconds at the epoch, got %d", to.LeapSecs)
+	}
+}
@@ -0,0 +1,140 @@
+package timelib
+
+import "fmt"
+
+// Month overflow policies, for adding months or years to a day that does
+// not exist in the resulting month, such as 2024-01-31 +1 month
+const (
+	TIMELIB_MONTH_OVERFLOW = iota // Carry the extra days into the next month (2024-03-02), as PHP does
+	TIMELIB_MONTH_CLAMP           // Use the last day of the month (2024-02-29)
+	TIMELIB_MONTH_REJECT          // Fail with an error
+)
+
+// monthOverflowDays returns the number of days the day of month d passes
+// the end of the month after moving y-m by relY years and relM months,
+// applying policy. With TIMELIB_MONTH_CLAMP, subtracting the result from
+// the relative days moves the date to the end of the month instead.
+func monthOverflowDays(y, m, d, relY, relM int64, policy int) (int64, error) {
+	switch policy {
+	case TIMELIB_MONTH_OVERFLOW, TIMELIB_MONTH_CLAMP, TIMELIB_MONTH_REJECT:
+	default:
+		return 0, fmt.Errorf("unsupported month overflow policy %d", policy)
+	}
+	if policy == TIMELIB_MONTH_OVERFLOW || (relY == 0 && relM == 0) {
+		return 0, nil
+	}
+
+	months := y*12 + m - 1 + relY*12 + relM
+	ty, tm := floorDiv(months, 12), months-floorDiv(months, 12)*12+1
+	excess := d - DaysInMonth(ty, tm)
+	if excess <= 0 {
+		return 0, nil
+	}
+	if policy == TIMELIB_MONTH_REJECT {
+		return 0, fmt.Errorf("day %d does not exist in %04d-%02d", d, ty, tm)
+	}
+	return excess, nil
+}
+
+// applyMonthPolicy returns interval with its days adjusted for the month
+// overflow policy, when applied to t with the sign given. "first/last day
+// of" relatives are returned unchanged.
+func applyMonthPolicy(t *Time, interval *RelTime, sign int64, policy int) (*RelTime, error) {
+	if interval.FirstLastDayOf != 0 {
+		if policy < TIMELIB_MONTH_OVERFLOW || policy > TIMELIB_MONTH_REJECT {
+			return nil, fmt.Errorf("unsupported month overflow policy %d", policy)
+		}
+		return interval, nil
+	}
+
+	bias := sign
+	if interval.Invert {
+		bias = -bias
+	}
+	excess, err := monthOverflowDays(t.Y, t.M, t.D, interval.Y*bias, interval.M*bias, policy)
+	if err != nil || excess == 0 {
+		return interval, err
+	}
+	if interval.HaveWeekdayRelative || interval.HaveSpecialRelative {
+		// Weekdays move the day before the months are added, so the end of
+		// the month cannot be kept
+		_, err := monthOverflowDays(t.Y, t.M, t.D, interval.Y*bias, interval.M*bias, TIMELIB_MONTH_REJECT)
+		return nil, err
+	}
+
+	// Applied as bias × D, so that the result moves back by excess days
+	adjusted := RelTimeClone(interval)
+	adjusted.D -= excess * bias
+	return adjusted, nil
+}
+
+// AddWithPolicy is Add with a month overflow policy, one of the
+// TIMELIB_MONTH_* constants. Add itself uses TIMELIB_MONTH_OVERFLOW.
+func (t *Time) AddWithPolicy(interval *RelTime, policy int) (*Time, error) {
+	adjusted, err := applyMonthPolicy(t, interval, 1, policy)
+	if err != nil {
+		return nil, err
+	}
+	return t.Add(adjusted), nil
+}
+
+// SubWithPolicy is Sub with a month overflow policy, so that 2024-03-31
+// -1 month is 2024-02-29 with TIMELIB_MONTH_CLAMP
+func (t *Time) SubWithPolicy(interval *RelTime, policy int) (*Time, error) {
+	adjusted, err := applyMonthPolicy(t, interval, -1, policy)
+	if err != nil {
+		return nil, err
+	}
+	return t.Sub(adjusted), nil
+}
+
+// AddWallWithPolicy is AddWall with a month overflow policy
+func (t *Time) AddWallWithPolicy(interval *RelTime, policy int) (*Time, error) {
+	adjusted, err := applyMonthPolicy(t, interval, 1, policy)
+	if err != nil {
+		return nil, err
+	}
+	return t.AddWall(adjusted), nil
+}
+
+// SubWallWithPolicy is SubWall with a month overflow policy
+func (t *Time) SubWallWithPolicy(interval *RelTime, policy int) (*Time, error) {
+	adjusted, err := applyMonthPolicy(t, interval, -1, policy)
+	if err != nil {
+		return nil, err
+	}
+	return t.SubWall(adjusted), nil
+}
+
+// StrToTimeOptions holds the options of StrToTimeWithOptions
+type StrToTimeOptions struct {
+	MonthOverflow int // TIMELIB_MONTH_* policy for relative months and years
+}
+
+// StrToTimeWithOptions parses a date/time string like StrToTime, fills in
+// what the string leaves out from base, and applies any relative part,
+// such as "+1 month", following options.MonthOverflow. With a weekday, as
+// in "+1 month friday", a day that does not exist in the resulting month
+// is an error unless the policy is TIMELIB_MONTH_OVERFLOW. The result has
+// an up to date timestamp in the zone of base, unless the string gives one.
+func StrToTimeWithOptions(str string, base *Time, tzdb *TzDB, options StrToTimeOptions) (*Time, error) {
+	if base == nil {
+		return nil, fmt.Errorf("base time is nil")
+	}
+	parsed, err := StrToTime(str, tzdb)
+	if err != nil {
+		return nil, err
+	}
+	FillHoles(parsed, base, TIMELIB_NO_CLONE)
+
+	if parsed.HaveRelative {
+		relative, err := applyMonthPolicy(parsed, &parsed.Relative, 1, options.MonthOverflow)
+		if err != nil {
+			return nil, err
+		}
+		parsed.Relative = *relative
+	}
+
+	parsed.UpdateTS(base.TzInfo)
+	return parsed, nil
+}
@@ -0,0 +1,101 @@
+package timelib
+
+import "testing"
+
+// TestMonthOverflowPolicy tests the policies with Add, Sub, AddWall and
+// SubWall
+func TestMonthOverflowPolicy(t *testing.T) {
+	tz, err := ParseTzfile("Europe/Amsterdam", BuiltinDB(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type op func(*Time, *RelTime, int) (*Time, error)
+	add := (*Time).AddWithPolicy
+	sub := (*Time).SubWithPolicy
+	addWall := (*Time).AddWallWithPolicy
+	subWall := (*Time).SubWallWithPolicy
+
+	tests := []struct {
+		name                    string
+		op                      op
+		base                    *Time
+		period                  string
+		overflow, clamp, reject string
+	}{
+		{"add month", add, testTime(2024, 1, 31, 10, 0, 0, nil), "P1M", "2024-03-02 10:00:00", "2024-02-29 10:00:00", ""},
+		{"add year", add, testTime(2024, 2, 29, 0, 0, 0, nil), "P1Y", "2025-03-01 00:00:00", "2025-02-28 00:00:00", ""},
+		{"add month and day", add, testTime(2023, 1, 31, 0, 0, 0, nil), "P1M1D", "2023-03-04 00:00:00", "2023-03-01 00:00:00", ""},
+		{"add month that fits", add, testTime(2024, 1, 30, 0, 0, 0, nil), "P2M", "2024-03-30 00:00:00", "2024-03-30 00:00:00", "2024-03-30 00:00:00"},
+		{"sub month", sub, testTime(2024, 3, 31, 0, 0, 0, nil), "P1M", "2024-03-02 00:00:00", "2024-02-29 00:00:00", ""},
+		{"sub months into previous year", sub, testTime(2024, 5, 31, 0, 0, 0, nil), "P7M", "2023-10-31 00:00:00", "2023-10-31 00:00:00", "2023-10-31 00:00:00"},
+		{"add wall", addWall, testTime(2024, 8, 31, 12, 0, 0, tz), "P1MT1H", "2024-10-01 13:00:00", "2024-09-30 13:00:00", ""},
+		{"sub wall", subWall, testTime(2024, 7, 31, 12, 0, 0, tz), "P1M", "2024-07-01 12:00:00", "2024-06-30 12:00:00", ""},
+	}
+
+	for _, test := range tests {
+		period := mustInterval(t, test.period)
+		for policy, want := range []string{test.overflow, test.clamp, test.reject} {
+			got, err := test.op(test.base, period, policy)
+			switch {
+			case want == "" && err == nil:
+				t.Errorf("%s, policy %d: got %s, want an error", test.name, policy, testTimeString(got))
+			case want != "" && (err != nil || testTimeString(got) != want):
+				t.Errorf("%s, policy %d: got %v (%v), want %s", test.name, policy, got, err, want)
+			}
+		}
+	}
+
+	// An inverted period subtracts, and is clamped the same way
+	back := mustInterval(t, "P1M").Negate()
+	got, err := testTime(2024, 3, 31, 0, 0, 0, nil).AddWithPolicy(back, TIMELIB_MONTH_CLAMP)
+	if err != nil || testTimeString(got) != "2024-02-29 00:00:00" {
+		t.Errorf("inverted: got %v (%v)", got, err)
+	}
+
+	if _, err := testTime(2024, 1, 31, 0, 0, 0, nil).AddWithPolicy(mustInterval(t, "P1M"), 7); err == nil {
+		t.Errorf("expected an error for an unknown policy")
+	}
+}
+
+// TestStrToTimeWithOptions tests the month overflow policy for relative
+// strings
+func TestStrToTimeWithOptions(t *testing.T) {
+	base := testTime(2024, 1, 31, 9, 0, 0, nil)
+
+	tests := []struct {
+		input                   string
+		overflow, clamp, reject string
+	}{
+		{"+1 month", "2024-03-02 09:00:00", "2024-02-29 09:00:00", ""},
+		{"2023-03-31 -1 month", "2023-03-03 00:00:00", "2023-02-28 00:00:00", ""},
+		{"+1 year", "2025-01-31 09:00:00", "2025-01-31 09:00:00", "2025-01-31 09:00:00"},
+		{"last day of next month", "2024-02-29 00:00:00", "2024-02-29 00:00:00", "2024-02-29 00:00:00"},
+		{"+1 month friday", "2024-03-02 00:00:00", "", ""},
+		{"+1 month +2 weekdays", "2024-03-05 09:00:00", "", ""},
+		{"+1 year friday", "2025-02-02 00:00:00", "2025-02-02 00:00:00", "2025-02-02 00:00:00"},
+	}
+	for _, test := range tests {
+		for policy, want := range []string{test.overflow, test.clamp, test.reject} {
+			got, err := StrToTimeWithOptions(test.input, base, nil, StrToTimeOptions{MonthOverflow: policy})
+			if want == "" {
+				if err == nil {
+					t.Errorf("%q, policy %d: got %s, want an error", test.input, policy, testTimeString(got))
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%q, policy %d: %v", test.input, policy, err)
+				continue
+			}
+			got.UpdateFromSSE()
+			if testTimeString(got) != want {
+				t.Errorf("%q, policy %d: got %s, want %s", test.input, policy, testTimeString(got), want)
+			}
+		}
+	}
+
+	if _, err := StrToTimeWithOptions("+1 month", nil, nil, StrToTimeOptions{}); err == nil {
+		t.Errorf("expected an error for a nil base")
+	}
+}
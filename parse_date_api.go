@@ -31,6 +31,14 @@ func ParseDateString(str string, tzdb *TzDB, tzWrapper TzGetWrapper) (*Time, *Er
 		return emptyTime, errContainer, nil
 	}
 
+	// Windows zone names contain spaces, so turn them into a single
+	// identifier token before the scanner sees them
+	original := str
+	var replacements []windowsZoneReplacement
+	if tzdb != nil && tzdb.acceptWindowsNames {
+		str, replacements = replaceWindowsZoneNames(str, tzdb)
+	}
+
 	// Initialize scanner with null-terminated string
 	// Add null terminator for re2go
 	strBytes := make([]byte, len(str)+1)
@@ -54,6 +62,20 @@ func ParseDateString(str string, tzdb *TzDB, tzWrapper TzGetWrapper) (*Time, *Er
 		tzdb: tzdb,
 	}
 
+	// Remember the zone names the wrapper could not resolve, so that the
+	// "not found" errors can carry a suggestion afterwards
+	var unresolvedZones []string
+	if tzWrapper != nil {
+		wrapped := tzWrapper
+		tzWrapper = func(name string, db *TzDB, errorCode *int) (*TzInfo, error) {
+			tz, err := wrapped(name, db, errorCode)
+			if tz == nil && !(len(name) < MAX_ABBR_LEN && abbrSearch(name, -1, 0) != nil) {
+				unresolvedZones = append(unresolvedZones, name)
+			}
+			return tz, err
+		}
+	}
+
 	// Set up pointers (lim points to the byte after the string, which is the null terminator)
 	s.cur = &s.str[0]
 	s.lim = &s.str[len(str)]
@@ -91,9 +113,46 @@ func ParseDateString(str string, tzdb *TzDB, tzWrapper TzGetWrapper) (*Time, *Er
 		}
 	}
 
+	addTzSuggestions(s.errors, unresolvedZones, tzdb)
+
+	// Report positions in the string as given, not as scanned
+	if len(replacements) > 0 {
+		for _, messages := range [][]ErrorMessage{s.errors.ErrorMessages, s.errors.WarningMessages} {
+			for i := range messages {
+				messages[i].Position = windowsZoneOriginalPosition(messages[i].Position, replacements)
+				messages[i].Character = 0
+				if messages[i].Position < len(original) {
+					messages[i].Character = original[messages[i].Position]
+				}
+			}
+		}
+	}
+
 	return s.time, s.errors, nil
 }
 
+// addTzSuggestions appends the closest known identifier to each "timezone
+// could not be found" error, pairing the errors with the unresolved names in
+// the order the scanner encountered them
+func addTzSuggestions(errors *ErrorContainer, names []string, tzdb *TzDB) {
+	if errors == nil || len(names) == 0 {
+		return
+	}
+
+	next := 0
+	for i := 0; i < errors.ErrorCount && i < len(errors.ErrorMessages); i++ {
+		msg := &errors.ErrorMessages[i]
+		if msg.ErrorCode != TIMELIB_ERR_TZID_NOT_FOUND {
+			continue
+		}
+		if next >= len(names) {
+			return
+		}
+		msg.Message += tzSuggestionHint(names[next], tzdb)
+		next++
+	}
+}
+
 // StrToTime is a convenience function that parses a date/time string.
 // It's similar to PHP's strtotime() function.
 // Returns the parsed time and an error if parsing failed.
@@ -1,6 +1,7 @@
 package timelib
 
 import (
+	"fmt"
 	"testing"
 )
 
@@ -219,3 +220,92 @@ func TestRelativeDateParsing(t *testing.T) {
 		})
 	}
 }
+
+// TestGoDurationParsing tests Go duration syntax as a relative time
+func TestGoDurationParsing(t *testing.T) {
+	tests := []struct {
+		input   string
+		h, i, s int64
+		us      int64
+	}{
+		{"1h30m45s", 1, 30, 45, 0},
+		{"-90m", -1, -30, 0, 0},
+		{"+1.5h", 1, 30, 0, 0},
+		{"2m3.5s", 0, 2, 3, 500000},
+		{"1s500ms", 0, 0, 1, 500000},
+		{"250us", 0, 0, 0, 250},
+		{".5h", 0, 30, 0, 0},
+		{"1.5h30m", 2, 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		time, err := StrToTime(tt.input, nil)
+		if err != nil {
+			t.Errorf("%s: %v", tt.input, err)
+			continue
+		}
+		if !time.HaveRelative || time.HaveDate || time.HaveTime {
+			t.Errorf("%s: expected only a relative time", tt.input)
+		}
+		r := time.Relative
+		if r.H != tt.h || r.I != tt.i || r.S != tt.s || r.US != tt.us || r.Y != 0 || r.M != 0 || r.D != 0 {
+			t.Errorf("%s: got %dH%dI%dS%dUS", tt.input, r.H, r.I, r.S, r.US)
+		}
+	}
+
+	// Combined with the rest of the grammar, and applied to a base time
+	// like any other relative time
+	base := testTime(2024, 1, 1, 12, 0, 0, nil)
+	combined := []struct {
+		input string
+		want  string
+	}{
+		{"-90m", "2024-01-01 10:30:00"},
+		{"now +1h30m", "2024-01-01 13:30:00"},
+		{"1h30m ago", "2024-01-01 10:30:00"},
+		{"tomorrow 1h30m", "2024-01-02 01:30:00"},
+		{"2024-03-01 09:00 +2h15m", "2024-03-01 11:15:00"},
+	}
+	for _, tt := range combined {
+		time, err := StrToTime(tt.input, nil)
+		if err != nil {
+			t.Errorf("%s: %v", tt.input, err)
+			continue
+		}
+		FillHoles(time, base, TIMELIB_NO_CLONE)
+		time.UpdateTS(nil)
+		got := fmt.Sprintf("%04d-%02d-%02d %02d:%02d:%02d", time.Y, time.M, time.D, time.H, time.I, time.S)
+		if got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+// TestGoDurationOverlap tests that input the grammar already read as hh.mm
+// and a military zone keeps that meaning
+func TestGoDurationOverlap(t *testing.T) {
+	tests := []struct {
+		input string
+		h, i  int64
+		zone  string
+	}{
+		{"1.5h", 1, 5, "H"},
+		{"9.30h", 9, 30, "H"},
+		{"12.45m", 12, 45, "M"},
+		{"5.5s", 5, 5, "S"},
+		{"tomorrow 1.5h", 1, 5, "H"},
+		{"10.30m ago", 10, 30, "M"},
+	}
+
+	for _, tt := range tests {
+		time, err := StrToTime(tt.input, nil)
+		if err != nil {
+			t.Errorf("%s: %v", tt.input, err)
+			continue
+		}
+		if time.H != tt.h || time.I != tt.i || time.TzAbbr != tt.zone || time.Relative.H != 0 || time.Relative.I != 0 {
+			t.Errorf("%s: got %02d:%02d %s with %dH%dI relative, want %02d:%02d %s",
+				tt.input, time.H, time.I, time.TzAbbr, time.Relative.H, time.Relative.I, tt.h, tt.i, tt.zone)
+		}
+	}
+}
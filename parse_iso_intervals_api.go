@@ -10,17 +10,19 @@ import (
 // ISO 8601 interval formats supported:
 //   - R5/2008-03-01T13:00:00Z/P1Y2M10DT2H30M (recurrence/start/duration)
 //   - R5/P1Y2M10DT2H30M/2008-03-01T13:00:00Z (recurrence/duration/end)
-//   - P1Y2M10DT2H30M (duration only)
+//   - P1Y2M10DT2H30M, PT0.5H, P0003-06-04T12:30:05 (duration only)
 //   - 2008-03-01T13:00:00Z/2009-05-11T15:30:00Z (start/end)
+//   - 2009-W01-1/2009-060, 2007-12-14T13:30/15:30 (week, ordinal and abbreviated dates)
+//
+// Only the elements given are returned: the end is not computed from the
+// start and the period. "R" without a count returns 0 recurrences. Error
+// positions are byte offsets into s.
 func ParseIsoInterval(s string) (*Time, *Time, *RelTime, int, *ErrorContainer) {
-	// Trim whitespace
-	s = strings.TrimSpace(s)
-
-	if len(s) == 0 {
+	if len(strings.TrimSpace(s)) == 0 {
 		errors := &ErrorContainer{
 			ErrorCount: 1,
 			ErrorMessages: []ErrorMessage{
-				{Position: 0, Character: 0, Message: "Empty string"},
+				{ErrorCode: TIMELIB_ERR_EMPTY_STRING, Position: 0, Character: 0, Message: "Empty string"},
 			},
 		}
 		return nil, nil, nil, 0, errors
@@ -32,51 +34,11 @@ func ParseIsoInterval(s string) (*Time, *Time, *RelTime, int, *ErrorContainer) {
 	strBytes[len(s)] = 0 // null terminator
 
 	scanner := &IsoIntervalScanner{
-		str:    strBytes,
-		errors: &ErrorContainer{},
-		Begin: &Time{
-			Y:            TIMELIB_UNSET,
-			M:            TIMELIB_UNSET,
-			D:            TIMELIB_UNSET,
-			H:            TIMELIB_UNSET,
-			I:            TIMELIB_UNSET,
-			S:            TIMELIB_UNSET,
-			US:           0,
-			Z:            0,
-			Dst:          0,
-			IsLocaltime:  false,
-			ZoneType:     TIMELIB_ZONETYPE_OFFSET,
-		},
-		End: &Time{
-			Y:            TIMELIB_UNSET,
-			M:            TIMELIB_UNSET,
-			D:            TIMELIB_UNSET,
-			H:            TIMELIB_UNSET,
-			I:            TIMELIB_UNSET,
-			S:            TIMELIB_UNSET,
-			US:           0,
-			Z:            0,
-			Dst:          0,
-			IsLocaltime:  false,
-			ZoneType:     TIMELIB_ZONETYPE_OFFSET,
-		},
-		Period: &RelTime{
-			Y:                   0,
-			M:                   0,
-			D:                   0,
-			H:                   0,
-			I:                   0,
-			S:                   0,
-			US:                  0,
-			Weekday:             0,
-			WeekdayBehavior:     0,
-			FirstLastDayOf:      0,
-			Invert:              false,
-			Days:                TIMELIB_UNSET,
-			Special:             struct{ Type int; Amount int64 }{},
-			HaveWeekdayRelative: false,
-			HaveSpecialRelative: false,
-		},
+		str:         strBytes,
+		errors:      &ErrorContainer{},
+		Begin:       isoIntervalTime(),
+		End:         isoIntervalTime(),
+		Period:      RelTimeCtor(),
 		Recurrences: 1,
 	}
 
@@ -84,13 +46,10 @@ func ParseIsoInterval(s string) (*Time, *Time, *RelTime, int, *ErrorContainer) {
 	scanner.cur = &scanner.str[0]
 	scanner.lim = &scanner.str[len(s)]
 
-	// Run the scanner in a loop
-	maxIterations := len(s) * 2 // Safety limit
-	iterations := 0
+	// Run the scanner until the end of the string or the first error
 	for {
 		t := scanIsoInterval(scanner)
-		iterations++
-		if t == EOI || iterations >= maxIterations {
+		if t == EOI || t == TIMELIB_ERROR {
 			break
 		}
 	}
@@ -117,6 +76,13 @@ func ParseIsoInterval(s string) (*Time, *Time, *RelTime, int, *ErrorContainer) {
 	return begin, end, period, recurrences, scanner.errors
 }
 
+// isoIntervalTime returns an empty start or end time
+func isoIntervalTime() *Time {
+	t := TimeCtor()
+	t.H, t.I, t.S = 0, 0, 0
+	return t
+}
+
 // StrToInterval is a convenience function that parses an ISO 8601 interval string
 // and returns the parsed components.
 func StrToInterval(s string) (*Time, *Time, *RelTime, int, error) {
@@ -1,6 +1,8 @@
 package timelib
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -290,3 +292,106 @@ func TestParseIsoIntervalCombinedRep(t *testing.T) {
 		})
 	}
 }
+
+// isoIntervalString formats the parts of a parsed interval for comparison
+func isoIntervalString(begin, end *Time, period *RelTime, recur int) string {
+	var parts []string
+	if recur != 0 {
+		parts = append(parts, fmt.Sprintf("R%d", recur))
+	}
+	date := func(t *Time) string {
+		return fmt.Sprintf("%04d-%02d-%02d %02d:%02d:%02d.%06d%+d", t.Y, t.M, t.D, t.H, t.I, t.S, t.US, t.Z)
+	}
+	if begin != nil {
+		parts = append(parts, date(begin))
+	}
+	if period != nil {
+		parts = append(parts, fmt.Sprintf("%dY%dM%dD %dH%dI%dS%dUS", period.Y, period.M, period.D, period.H, period.I, period.S, period.US))
+	}
+	if end != nil {
+		parts = append(parts, date(end))
+	}
+	return strings.Join(parts, " / ")
+}
+
+// TestParseIsoIntervalGrammar tests date, time and duration forms
+func TestParseIsoIntervalGrammar(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		// Week and ordinal dates
+		{"2009-W01-1/2009-W53-7", "2008-12-29 00:00:00.000000+0 / 2010-01-03 00:00:00.000000+0"},
+		{"2009W011T1200Z/P1D", "2008-12-29 12:00:00.000000+0 / 0Y0M1D 0H0I0S0US"},
+		{"2024-W10/2024-060", "2024-03-04 00:00:00.000000+0 / 2024-02-29 00:00:00.000000+0"},
+		{"2023365/2024-366", "2023-12-31 00:00:00.000000+0 / 2024-12-31 00:00:00.000000+0"},
+		// Fractions, offsets and the end of the day
+		{"PT0.5H", "0Y0M0D 0H30I0S0US"},
+		{"P1.5D", "0Y0M1D 12H0I0S0US"},
+		{"P0.5Y", "0Y6M0D 0H0I0S0US"},
+		{"P1W0,25D", "0Y0M7D 6H0I0S0US"},
+		{"PT1.000250S", "0Y0M0D 0H0I1S250US"},
+		{"2008-03-01T13:30,5+01:00/2008-03-01T1500-0530", "2008-03-01 13:30:30.000000+3600 / 2008-03-01 15:00:00.000000-19800"},
+		{"2008-03-01T13:00:00.25Z--2008-12-31T24:00", "2008-03-01 13:00:00.250000+0 / 2009-01-01 00:00:00.000000+0"},
+		// The alternative duration format
+		{"P0003-06-04T12:30:05", "3Y6M4D 12H30I5S0US"},
+		{"P00030604T123005", "3Y6M4D 12H30I5S0US"},
+		{"P0001-100", "1Y0M100D 0H0I0S0US"},
+		// Abbreviated ends take the missing parts from the start
+		{"2007-12-14T13:30/15:30", "2007-12-14 13:30:00.000000+0 / 2007-12-14 15:30:00.000000+0"},
+		{"2007-11-13T09:00+02:00/15T17:00", "2007-11-13 09:00:00.000000+7200 / 2007-11-15 17:00:00.000000+7200"},
+		{"2008-02-15/03-14", "2008-02-15 00:00:00.000000+0 / 2008-03-14 00:00:00.000000+0"},
+		{"R2/2008-03-01T13:00Z/P1D/02T00:00Z", "R2 / 2008-03-01 13:00:00.000000+0 / 0Y0M1D 0H0I0S0US / 2008-03-02 00:00:00.000000+0"},
+	}
+
+	for _, test := range tests {
+		begin, end, period, recur, errors := ParseIsoInterval(test.input)
+		if errors.ErrorCount > 0 {
+			t.Errorf("%s: %v", test.input, errors.ErrorMessages)
+			continue
+		}
+		if got := isoIntervalString(begin, end, period, recur); got != test.want {
+			t.Errorf("%s:\n got %s\nwant %s", test.input, got, test.want)
+		}
+	}
+}
+
+// TestParseIsoIntervalErrors tests the messages and positions of errors
+func TestParseIsoIntervalErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		position int
+		message  string
+	}{
+		{"", 0, "Empty string"},
+		{"P", 1, "Empty duration"},
+		{"P1DT", 4, "Missing expected time part"},
+		{"P1X", 2, "Undefined period specifier"},
+		{"P1D2Y", 4, "Duration components are out of order"},
+		{"PT1.5H30M", 6, "Only the last duration component may have a fraction"},
+		{"P0.3Y", 4, "A fraction of a month cannot be represented"},
+		{"P0000-13-00", 0, "Duration component out of range"},
+		{"2008-02-30/P1D", 5, "Invalid date"},
+		{"2009-W54/P1D", 6, "Invalid week number"},
+		{"2008-03-01T25:00/P1D", 11, "Invalid time"},
+		{"2008-03-01T13:00+24:00", 17, "Invalid offset"},
+		{"R5/P1D/R2", 7, "Recurrences must be the first element"},
+		{"P1D/P2D", 4, "Unexpected duration"},
+		{"2008-03-01/2008-03-02/2008-03-03", 22, "Unexpected data after the end date"},
+		{"2008-03-01x/P1D", 10, "Unexpected character"},
+		{"P1D/", 4, "Missing interval element"},
+		{"03-01/P1D", 0, "Expected a four-digit year"},
+		{"  P1D/15:30", 6, "Expected a four-digit year"},
+	}
+
+	for _, test := range tests {
+		_, _, _, _, errors := ParseIsoInterval(test.input)
+		if errors.ErrorCount == 0 {
+			t.Errorf("%q: expected an error", test.input)
+			continue
+		}
+		if e := errors.ErrorMessages[0]; e.Position != test.position || e.Message != test.message {
+			t.Errorf("%q: got %q at %d, want %q at %d", test.input, e.Message, e.Position, test.message, test.position)
+		}
+	}
+}
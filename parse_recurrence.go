@@ -0,0 +1,717 @@
+package timelib
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// recurrenceAdverbs are the words for a frequency on their own, with the
+// interval they imply
+var recurrenceAdverbs = map[string][2]int64{
+	"secondly":    {TIMELIB_FREQ_SECONDLY, 1},
+	"minutely":    {TIMELIB_FREQ_MINUTELY, 1},
+	"hourly":      {TIMELIB_FREQ_HOURLY, 1},
+	"daily":       {TIMELIB_FREQ_DAILY, 1},
+	"nightly":     {TIMELIB_FREQ_DAILY, 1},
+	"weekly":      {TIMELIB_FREQ_WEEKLY, 1},
+	"fortnightly": {TIMELIB_FREQ_WEEKLY, 2},
+	"biweekly":    {TIMELIB_FREQ_WEEKLY, 2},
+	"monthly":     {TIMELIB_FREQ_MONTHLY, 1},
+	"quarterly":   {TIMELIB_FREQ_MONTHLY, 3},
+	"yearly":      {TIMELIB_FREQ_YEARLY, 1},
+	"annually":    {TIMELIB_FREQ_YEARLY, 1},
+}
+
+// recurrencePhrase is the state of parsing a recurrence phrase
+type recurrencePhrase struct {
+	tokens []string
+	pos    int
+
+	rule     *RRule
+	rules    []*RRule   // the resolved rules, one for each minute and second of the times
+	times    [][3]int64 // hour, minute and second of each "at" time
+	start    string     // date phrase of the "starting" clause
+	until    string     // date phrase of the "until" clause
+	interval int64
+}
+
+// ParseRecurrence parses a recurrence phrase such as "every day", "every
+// other week on Tuesday", "every 2nd Monday of the month", "weekdays at
+// 17:30" or "every 15 minutes" into a recurrence set. Times of day with
+// different minutes, as in "every day at 9am and 5:30pm", give a rule each.
+//
+// Besides units, days of the week, ordinals ("2nd", "second", "last") and
+// "other", a phrase may contain "on" with days of the week or of the month,
+// "in" with month names, "at" with times of day, "for N times",
+// "until <date>" and "starting <date>". Dates are parsed like StrToTime,
+// relative to now.
+//
+// The recurrence starts with its first occurrence at or after now, or at or
+// after the "starting" date, and is evaluated in the zone of now. Without
+// "at", daily and less frequent recurrences occur at midnight, like "next
+// tuesday" does. More frequent ones occur on multiples of their interval
+// since midnight, such as 10:15 and 10:30 for "every 15 minutes", or on
+// whole units when the interval does not divide a day.
+func ParseRecurrence(phrase string, now *Time, tzdb *TzDB) (*RecurrenceSet, error) {
+	if now == nil {
+		return nil, fmt.Errorf("recurrence reference time is nil")
+	}
+
+	p := &recurrencePhrase{
+		tokens:   strings.Fields(strings.ReplaceAll(strings.ToLower(phrase), ",", " , ")),
+		rule:     NewRRule(0),
+		interval: 1,
+	}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("empty recurrence phrase")
+	}
+	if err := p.parse(); err != nil {
+		return nil, fmt.Errorf("invalid recurrence '%s': %v", strings.TrimSpace(phrase), err)
+	}
+	if err := p.resolve(); err != nil {
+		return nil, fmt.Errorf("invalid recurrence '%s': %v", strings.TrimSpace(phrase), err)
+	}
+
+	ref := now.Clone()
+	if !ref.SseUptodate {
+		ref.UpdateTS(ref.TzInfo)
+	}
+	ref.Unixtime2local(ref.Sse)
+
+	startHasTime := false
+	if p.start != "" {
+		var err error
+		if ref, startHasTime, err = resolveRecurrenceClause(p.start, now, tzdb); err != nil {
+			return nil, err
+		}
+	}
+
+	var start *Time
+	for _, rule := range p.rules {
+		first, err := p.firstOccurrence(rule, ref, startHasTime)
+		if err != nil {
+			return nil, err
+		}
+		if start == nil || first.Sse < start.Sse {
+			start = first
+		}
+	}
+
+	if p.until != "" {
+		until, hasTime, err := resolveRecurrenceClause(p.until, now, tzdb)
+		if err != nil {
+			return nil, err
+		}
+		if !hasTime {
+			// A date includes the whole day. UNTIL must have the type of the
+			// start, so it is the end of that day as a date-time.
+			until = recurrenceDayEnd(until, start)
+		}
+		if TimeCompare(until, start) < 0 {
+			return nil, fmt.Errorf("recurrence '%s' ends before it starts", strings.TrimSpace(phrase))
+		}
+		for _, rule := range p.rules {
+			rule.Until = until
+		}
+	}
+
+	set := &RecurrenceSet{Start: start, RRules: p.rules}
+	if len(p.rules) > 1 && p.rule.Count > 0 {
+		// Each rule would count its own occurrences, so the rules end with
+		// the last occurrence of the set instead
+		dates := set.Dates(int(p.rule.Count))
+		for _, rule := range p.rules {
+			rule.Count = 0
+			rule.Until = dates[len(dates)-1]
+		}
+	}
+	return set, nil
+}
+
+// parse reads the tokens of the phrase into the rule
+func (p *recurrencePhrase) parse() error {
+	for p.pos < len(p.tokens) {
+		w := p.next()
+		switch {
+		case w == "," || w == "and":
+		case w == "every" || w == "each":
+			if err := p.parseEvery(); err != nil {
+				return err
+			}
+		case recurrenceAdverbs[w] != [2]int64{}:
+			if err := p.setFreq(int(recurrenceAdverbs[w][0]), recurrenceAdverbs[w][1]); err != nil {
+				return err
+			}
+		case recurrenceWeekdays(w) != nil:
+			p.addDays(recurrenceWeekdays(w), 0)
+		case w == "on":
+			if err := p.parseOn(); err != nil {
+				return err
+			}
+		case w == "in":
+			if err := p.parseMonths(); err != nil {
+				return err
+			}
+		case w == "at":
+			if err := p.parseTimes(); err != nil {
+				return err
+			}
+		case w == "until" || w == "till":
+			if p.until = p.clause(); p.until == "" {
+				return fmt.Errorf("'%s' needs a date", w)
+			}
+		case w == "starting" || w == "beginning" || w == "from":
+			if p.start = p.clause(); p.start == "" {
+				return fmt.Errorf("'%s' needs a date", w)
+			}
+		case w == "for":
+			if err := p.parseCount(p.next()); err != nil {
+				return err
+			}
+		case recurrenceNumber(w) > 0 && isRecurrenceCount(p.peek()):
+			if err := p.parseCount(w); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unexpected '%s'", w)
+		}
+	}
+	return nil
+}
+
+// parseEvery parses what follows "every": a unit with an optional number,
+// "other" or ordinal, days of the week, or an ordinal day of the month
+func (p *recurrencePhrase) parseEvery() error {
+	interval := int64(1)
+	switch w := p.peek(); {
+	case recurrenceNumber(w) > 0:
+		interval = recurrenceNumber(p.next())
+	case w == "other":
+		p.next()
+		interval = 2
+	case recurrenceOrdinal(w) != 0 && !p.ordinalApplies():
+		// "second" is read as the unit below
+	case recurrenceOrdinal(w) != 0:
+		ord := recurrenceOrdinal(p.next())
+		if days := recurrenceWeekdays(p.peek()); days != nil {
+			p.next()
+			freq, err := p.parseOf()
+			if err != nil {
+				return err
+			}
+			switch {
+			case freq != 0:
+				p.addDays(days, ord)
+				return p.setFreq(freq, 1)
+			case ord == 1 || ord == -1:
+				// "every last friday" is monthly
+				p.addDays(days, ord)
+				return p.setFreq(TIMELIB_FREQ_MONTHLY, 1)
+			}
+			p.addDays(days, 0)
+			return p.setFreq(TIMELIB_FREQ_WEEKLY, ord)
+		}
+		if p.peek() == "day" || p.peek() == "of" {
+			if p.peek() == "day" {
+				p.next()
+			}
+			freq, err := p.parseOf()
+			if err != nil {
+				return err
+			}
+			if freq == 0 {
+				if ord < 0 {
+					return fmt.Errorf("'last day' needs 'of the month'")
+				}
+				return p.setFreq(TIMELIB_FREQ_DAILY, ord)
+			}
+			p.rule.ByMonthDay = append(p.rule.ByMonthDay, ord)
+			return p.setFreq(freq, 1)
+		}
+		if ord < 0 {
+			return fmt.Errorf("unexpected 'last'")
+		}
+		interval = ord
+	}
+
+	w := p.next()
+	if days := recurrenceWeekdays(w); days != nil {
+		p.addDays(days, 0)
+		return p.setFreq(TIMELIB_FREQ_WEEKLY, interval)
+	}
+	freq, multiple := recurrenceUnit(w)
+	if freq == 0 {
+		return fmt.Errorf("expected a unit or a day of the week after 'every', not '%s'", w)
+	}
+	return p.setFreq(freq, interval*multiple)
+}
+
+// ordinalApplies checks whether the ordinal that is the next word applies
+// to what follows it, as in "every second monday" or "every second week",
+// rather than being a unit on its own, as in "every second"
+func (p *recurrencePhrase) ordinalApplies() bool {
+	if freq, _ := recurrenceUnit(p.peek()); freq == 0 {
+		return true
+	}
+	w := p.peekAt(1)
+	if freq, _ := recurrenceUnit(w); freq != 0 {
+		return true
+	}
+	return recurrenceWeekdays(w) != nil || w == "of"
+}
+
+// parseOn parses the days of the week or of the month after "on"
+func (p *recurrencePhrase) parseOn() error {
+	found := false
+	for p.pos < len(p.tokens) {
+		w := p.peek()
+		switch {
+		case w == "the" || w == "," || w == "and":
+			p.next()
+			continue
+		case recurrenceWeekdays(w) != nil:
+			p.addDays(recurrenceWeekdays(p.next()), 0)
+		case recurrenceOrdinal(w) != 0:
+			ord := recurrenceOrdinal(p.next())
+			if days := recurrenceWeekdays(p.peek()); days != nil {
+				p.next()
+				p.addDays(days, ord)
+			} else {
+				if p.peek() == "day" {
+					p.next()
+				}
+				p.rule.ByMonthDay = append(p.rule.ByMonthDay, ord)
+			}
+			freq, err := p.parseOf()
+			if err != nil {
+				return err
+			}
+			if freq != 0 {
+				if err := p.setFreq(freq, 1); err != nil {
+					return err
+				}
+			}
+		default:
+			if !found {
+				return fmt.Errorf("expected a day after 'on', not '%s'", w)
+			}
+			return nil
+		}
+		found = true
+	}
+	if !found {
+		return fmt.Errorf("expected a day after 'on'")
+	}
+	return nil
+}
+
+// parseOf parses an optional "of the month", "of every month", "of the year"
+// or "of <month name>", and returns the frequency it implies or 0
+func (p *recurrencePhrase) parseOf() (int, error) {
+	if p.peek() != "of" {
+		return 0, nil
+	}
+	p.next()
+	if w := p.peek(); w == "the" || w == "every" || w == "each" {
+		p.next()
+	}
+
+	w := p.next()
+	switch {
+	case w == "month":
+		return TIMELIB_FREQ_MONTHLY, nil
+	case w == "year":
+		return TIMELIB_FREQ_YEARLY, nil
+	case recurrenceMonth(w) != 0:
+		p.rule.ByMonth = append(p.rule.ByMonth, recurrenceMonth(w))
+		return TIMELIB_FREQ_YEARLY, nil
+	}
+	return 0, fmt.Errorf("expected 'month', 'year' or a month after 'of', not '%s'", w)
+}
+
+// parseMonths parses the month names after "in"
+func (p *recurrencePhrase) parseMonths() error {
+	found := false
+	for p.pos < len(p.tokens) {
+		w := p.peek()
+		if w == "," || w == "and" {
+			p.next()
+			continue
+		}
+		m := recurrenceMonth(w)
+		if m == 0 {
+			break
+		}
+		p.next()
+		p.rule.ByMonth = append(p.rule.ByMonth, m)
+		found = true
+	}
+	if !found {
+		return fmt.Errorf("expected a month after 'in'")
+	}
+	return nil
+}
+
+// parseTimes parses the times of day after "at", e.g. "9am", "9:15 pm",
+// "17:30" or "noon and midnight"
+func (p *recurrencePhrase) parseTimes() error {
+	var group []string
+	flush := func() error {
+		if len(group) == 0 {
+			return nil
+		}
+		text := strings.Join(group, " ")
+		group = nil
+
+		if h, err := strconv.ParseInt(text, 10, 64); err == nil && h >= 0 && h <= 23 {
+			p.times = append(p.times, [3]int64{h, 0, 0})
+			return nil
+		}
+		t, err := StrToTime(text, nil)
+		if err != nil || !t.HaveTime || t.HaveDate || t.H == TIMELIB_UNSET {
+			return fmt.Errorf("invalid time of day '%s'", text)
+		}
+		p.times = append(p.times, [3]int64{t.H, t.I, t.S})
+		return nil
+	}
+
+	for p.pos < len(p.tokens) {
+		w := p.peek()
+		if w == "," || w == "and" {
+			p.next()
+			if err := flush(); err != nil {
+				return err
+			}
+			continue
+		}
+		if !isRecurrenceTimeWord(w) {
+			break
+		}
+		group = append(group, p.next())
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	if len(p.times) == 0 {
+		return fmt.Errorf("expected a time of day after 'at'")
+	}
+	return nil
+}
+
+// parseCount parses "N times" or "N occurrences"
+func (p *recurrencePhrase) parseCount(number string) error {
+	n := recurrenceNumber(number)
+	if n <= 0 || !isRecurrenceCount(p.peek()) {
+		return fmt.Errorf("expected 'for N times'")
+	}
+	p.next()
+	p.rule.Count = n
+	return nil
+}
+
+// clause returns the words up to the next clause keyword
+func (p *recurrencePhrase) clause() string {
+	var words []string
+	for p.pos < len(p.tokens) {
+		w := p.peek()
+		if w == "until" || w == "till" || w == "starting" || w == "beginning" || w == "for" ||
+			(recurrenceNumber(w) > 0 && isRecurrenceCount(p.peekAt(1))) {
+			break
+		}
+		words = append(words, p.next())
+	}
+	if len(words) > 0 && (words[0] == "on" || words[0] == "from") {
+		words = words[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// setFreq sets the frequency and interval, which may only be given once
+func (p *recurrencePhrase) setFreq(freq int, interval int64) error {
+	if p.rule.Freq != 0 && (p.rule.Freq != freq || p.interval != interval) {
+		return fmt.Errorf("conflicting frequencies")
+	}
+	if interval < 1 {
+		return fmt.Errorf("the interval must be positive")
+	}
+	p.rule.Freq = freq
+	p.interval = interval
+	return nil
+}
+
+// addDays adds days of the week, with an occurrence number or 0. The
+// occurrence of a group of days, as in "the last weekday of the month",
+// selects among all of them with BYSETPOS.
+func (p *recurrencePhrase) addDays(days []int64, n int64) {
+	if n != 0 && len(days) > 1 {
+		p.rule.BySetPos = append(p.rule.BySetPos, n)
+		n = 0
+	}
+	for _, day := range days {
+		p.rule.ByDay = append(p.rule.ByDay, RRuleWeekday{Day: day, N: n})
+	}
+}
+
+// resolve completes the rule from what the phrase specified
+func (p *recurrencePhrase) resolve() error {
+	r := p.rule
+	if r.Freq == 0 {
+		switch {
+		case len(r.ByMonth) > 0:
+			r.Freq = TIMELIB_FREQ_YEARLY
+		case len(r.ByMonthDay) > 0:
+			r.Freq = TIMELIB_FREQ_MONTHLY
+		case len(r.ByDay) > 0:
+			r.Freq = TIMELIB_FREQ_WEEKLY
+			for _, day := range r.ByDay {
+				if day.N != 0 {
+					r.Freq = TIMELIB_FREQ_MONTHLY
+				}
+			}
+		default:
+			return fmt.Errorf("no frequency or days given")
+		}
+	}
+	r.Interval = p.interval
+
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	if len(p.times) == 0 {
+		p.rules = []*RRule{r}
+		return nil
+	}
+	if r.Freq < TIMELIB_FREQ_DAILY {
+		return fmt.Errorf("times of day cannot be combined with a frequency of less than a day")
+	}
+
+	// A rule combines every hour with every minute and second, so times with
+	// different minutes or seconds need a rule each
+	var groups [][2]int64
+	hours := map[[2]int64][]int64{}
+	for _, t := range p.times {
+		key := [2]int64{t[1], t[2]}
+		if _, ok := hours[key]; !ok {
+			groups = append(groups, key)
+		}
+		hours[key] = appendUnique(hours[key], t[0])
+	}
+	for _, key := range groups {
+		rule := *r
+		rule.ByHour = hours[key]
+		sort.Slice(rule.ByHour, func(a, b int) bool { return rule.ByHour[a] < rule.ByHour[b] })
+		rule.ByMinute = []int64{key[0]}
+		rule.BySecond = []int64{key[1]}
+		p.rules = append(p.rules, &rule)
+	}
+	return nil
+}
+
+// firstOccurrence returns the first occurrence of rule at or after ref.
+// Rules with an interval count their periods from that occurrence, so that
+// "every other Tuesday" starts with the next Tuesday.
+func (p *recurrencePhrase) firstOccurrence(rule *RRule, ref *Time, keepTime bool) (*Time, error) {
+	candidate := ref.Clone()
+	candidate.US = 0
+	switch {
+	case rule.Freq >= TIMELIB_FREQ_DAILY && !keepTime:
+		candidate.H, candidate.I, candidate.S = 0, 0, 0
+	case rule.Freq < TIMELIB_FREQ_DAILY && !keepTime:
+		// Round up to a multiple of the interval since midnight, or of the
+		// unit if the interval does not divide a day; UpdateTS normalizes
+		// the seconds
+		unit := map[int]int64{TIMELIB_FREQ_SECONDLY: 1, TIMELIB_FREQ_MINUTELY: 60, TIMELIB_FREQ_HOURLY: 3600}[rule.Freq]
+		step := unit * p.interval
+		if SECS_PER_DAY%step != 0 {
+			step = unit
+		}
+		seconds := candidate.H*3600 + candidate.I*60 + candidate.S
+		if ref.US > 0 {
+			seconds++
+		}
+		candidate.H, candidate.I, candidate.S = 0, 0, ceilDiv(seconds, step)*step
+	}
+	candidate.SseUptodate = false
+	candidate.UpdateTS(candidate.TzInfo)
+
+	probe := *rule
+	probe.Interval, probe.Count, probe.Until = 1, 0, nil
+	it, err := probe.Iterator(candidate)
+	if err != nil {
+		return nil, err
+	}
+	it.matchStart = true
+
+	for {
+		t, ok := it.Next()
+		if !ok {
+			return nil, fmt.Errorf("the recurrence has no occurrences")
+		}
+		if t.Sse >= ref.Sse {
+			return t, nil
+		}
+	}
+}
+
+// resolveRecurrenceClause parses a date phrase relative to now, and returns
+// it in the zone of now with whether it had a time
+func resolveRecurrenceClause(phrase string, now *Time, tzdb *TzDB) (*Time, bool, error) {
+	parsed, err := StrToTime(phrase, tzdb)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid date '%s': %v", phrase, err)
+	}
+	hasTime := parsed.HaveTime
+
+	FillHoles(parsed, now, TIMELIB_NO_CLONE)
+	parsed.UpdateTS(now.TzInfo)
+
+	t := now.Clone()
+	t.Relative = RelTime{}
+	t.HaveRelative = false
+	t.Unixtime2local(parsed.Sse)
+	return t, hasTime, nil
+}
+
+// recurrenceDayEnd returns the last second of the day of date in the zone
+// of start
+func recurrenceDayEnd(date, start *Time) *Time {
+	end := start.Clone()
+	end.Y, end.M, end.D = date.Y, date.M, date.D
+	end.H, end.I, end.S = 23, 59, 59
+	end.SseUptodate = false
+	end.UpdateTS(end.TzInfo)
+	return end
+}
+
+func (p *recurrencePhrase) next() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	p.pos++
+	return p.tokens[p.pos-1]
+}
+
+func (p *recurrencePhrase) peek() string {
+	return p.peekAt(0)
+}
+
+func (p *recurrencePhrase) peekAt(offset int) string {
+	if p.pos+offset >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos+offset]
+}
+
+// recurrenceUnit returns the frequency and interval multiple of a unit word
+func recurrenceUnit(word string) (int, int64) {
+	unit := timelibLookupRelunit(&word)
+	if unit == nil || word != "" {
+		return 0, 0
+	}
+	switch unit.Unit {
+	case TIMELIB_SECOND:
+		return TIMELIB_FREQ_SECONDLY, 1
+	case TIMELIB_MINUTE:
+		return TIMELIB_FREQ_MINUTELY, 1
+	case TIMELIB_HOUR:
+		return TIMELIB_FREQ_HOURLY, 1
+	case TIMELIB_DAY:
+		if unit.Multiplier == 1 {
+			return TIMELIB_FREQ_DAILY, 1
+		}
+		return TIMELIB_FREQ_WEEKLY, int64(unit.Multiplier / 7)
+	case TIMELIB_MONTH:
+		return TIMELIB_FREQ_MONTHLY, 1
+	case TIMELIB_YEAR:
+		return TIMELIB_FREQ_YEARLY, 1
+	}
+	return 0, 0
+}
+
+// recurrenceWeekdays returns the days of the week a word stands for, such as
+// "tuesday", "tuesdays", "weekdays" or "weekends", or nil
+func recurrenceWeekdays(word string) []int64 {
+	if word == "weekend" || word == "weekends" {
+		return []int64{6, 0}
+	}
+	unit := timelibLookupRelunit(&word)
+	if unit == nil || word != "" {
+		return nil
+	}
+	switch {
+	case unit.Unit == TIMELIB_WEEKDAY:
+		return []int64{int64(unit.Multiplier)}
+	case unit.Unit == TIMELIB_SPECIAL && unit.Multiplier == TIMELIB_SPECIAL_WEEKDAY:
+		return []int64{1, 2, 3, 4, 5}
+	}
+	return nil
+}
+
+// recurrenceOrdinal returns the value of "1st", "22nd", "second" or "last",
+// or 0
+func recurrenceOrdinal(word string) int64 {
+	for _, suffix := range []string{"st", "nd", "rd", "th"} {
+		if number, ok := strings.CutSuffix(word, suffix); ok {
+			if n := recurrenceNumber(number); n > 0 {
+				return n
+			}
+		}
+	}
+
+	// The relative text words without "next", "previous" and "this"
+	if word == "next" || word == "previous" || word == "this" {
+		return 0
+	}
+	behavior := 0
+	value := timelibLookupRelativeText(&word, &behavior)
+	if word != "" {
+		return 0
+	}
+	return value
+}
+
+// recurrenceNumber returns the value of a positive number, or 0
+func recurrenceNumber(word string) int64 {
+	n, err := strconv.ParseInt(word, 10, 64)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// recurrenceMonth returns the number of a month name, or 0. Roman numerals
+// are not accepted.
+func recurrenceMonth(word string) int64 {
+	if len(word) < 3 || strings.Trim(word, "ivx") == "" {
+		return 0
+	}
+	m := timelibLookupMonth(&word)
+	if word != "" {
+		return 0
+	}
+	return m
+}
+
+// isRecurrenceCount checks for the word after the number of occurrences
+func isRecurrenceCount(word string) bool {
+	return word == "times" || word == "time" || word == "occurrences" || word == "occurrence"
+}
+
+// isRecurrenceTimeWord checks whether a word can be part of a time of day
+func isRecurrenceTimeWord(word string) bool {
+	switch word {
+	case "am", "pm", "a.m.", "p.m.", "noon", "midnight":
+		return true
+	}
+	return word != "" && word[0] >= '0' && word[0] <= '9'
+}
+
+// appendUnique appends a value to a list if it is not in it yet
+func appendUnique(values []int64, v int64) []int64 {
+	if containsInt64(values, v) {
+		return values
+	}
+	return append(values, v)
+}
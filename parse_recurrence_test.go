@@ -0,0 +1,140 @@
+package timelib
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParseRecurrence tests recurrence phrases and their first occurrences
+func TestParseRecurrence(t *testing.T) {
+	tz, err := ParseTzfile("America/New_York", BuiltinDB(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Wednesday March 6th 2024, 10:13:27
+	now := testTime(2024, 3, 6, 10, 13, 27, tz)
+	now.Unixtime2local(now.Sse)
+
+	tests := []struct {
+		phrase string
+		rule   string
+		dates  string
+	}{
+		{"every day", "FREQ=DAILY",
+			"2024-03-07 00:00, 2024-03-08 00:00, 2024-03-09 00:00"},
+		{"Every day at 9am", "FREQ=DAILY;BYSECOND=0;BYMINUTE=0;BYHOUR=9",
+			"2024-03-07 09:00, 2024-03-08 09:00, 2024-03-09 09:00"},
+		{"every other week on Tuesday", "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU",
+			"2024-03-12 00:00, 2024-03-26 00:00, 2024-04-09 00:00"},
+		{"every other Tuesday at 9am", "FREQ=WEEKLY;INTERVAL=2;BYSECOND=0;BYMINUTE=0;BYHOUR=9;BYDAY=TU",
+			"2024-03-12 09:00, 2024-03-26 09:00, 2024-04-09 09:00"},
+		{"every second tuesday", "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU",
+			"2024-03-12 00:00, 2024-03-26 00:00, 2024-04-09 00:00"},
+		{"every 2nd Monday of the month", "FREQ=MONTHLY;BYDAY=2MO",
+			"2024-03-11 00:00, 2024-04-08 00:00, 2024-05-13 00:00"},
+		{"every last friday of the month at 5pm", "FREQ=MONTHLY;BYSECOND=0;BYMINUTE=0;BYHOUR=17;BYDAY=-1FR",
+			"2024-03-29 17:00, 2024-04-26 17:00, 2024-05-31 17:00"},
+		{"weekdays at 17:30", "FREQ=WEEKLY;BYSECOND=0;BYMINUTE=30;BYHOUR=17;BYDAY=MO,TU,WE,TH,FR",
+			"2024-03-06 17:30, 2024-03-07 17:30, 2024-03-08 17:30, 2024-03-11 17:30"},
+		{"every 15 minutes", "FREQ=MINUTELY;INTERVAL=15",
+			"2024-03-06 10:15, 2024-03-06 10:30, 2024-03-06 10:45"},
+		{"hourly", "FREQ=HOURLY",
+			"2024-03-06 11:00, 2024-03-06 12:00, 2024-03-06 13:00"},
+		{"every 2 hours on weekends", "FREQ=HOURLY;INTERVAL=2;BYDAY=SA,SU",
+			"2024-03-09 00:00, 2024-03-09 02:00, 2024-03-09 04:00"},
+		{"every second", "FREQ=SECONDLY",
+			"2024-03-06 10:13, 2024-03-06 10:13, 2024-03-06 10:13"},
+		{"every second hour", "FREQ=HOURLY;INTERVAL=2",
+			"2024-03-06 12:00, 2024-03-06 14:00, 2024-03-06 16:00"},
+		{"every 7 minutes", "FREQ=MINUTELY;INTERVAL=7",
+			"2024-03-06 10:14, 2024-03-06 10:21, 2024-03-06 10:28"},
+		{"every 3 days", "FREQ=DAILY;INTERVAL=3",
+			"2024-03-07 00:00, 2024-03-10 00:00, 2024-03-13 00:00"},
+		{"every month on the 1st and 15th at noon", "FREQ=MONTHLY;BYSECOND=0;BYMINUTE=0;BYHOUR=12;BYMONTHDAY=1,15",
+			"2024-03-15 12:00, 2024-04-01 12:00, 2024-04-15 12:00"},
+		{"every last day of the month", "FREQ=MONTHLY;BYMONTHDAY=-1",
+			"2024-03-31 00:00, 2024-04-30 00:00, 2024-05-31 00:00"},
+		{"yearly in march and june on the 1st", "FREQ=YEARLY;BYMONTHDAY=1;BYMONTH=3,6",
+			"2024-06-01 00:00, 2025-03-01 00:00, 2025-06-01 00:00"},
+		{"every first monday of september", "FREQ=YEARLY;BYDAY=1MO;BYMONTH=9",
+			"2024-09-02 00:00, 2025-09-01 00:00, 2026-09-07 00:00"},
+		{"every last weekday", "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+			"2024-03-29 00:00, 2024-04-30 00:00, 2024-05-31 00:00"},
+		{"weekends at 8:00 and 20:00", "FREQ=WEEKLY;BYSECOND=0;BYMINUTE=0;BYHOUR=8,20;BYDAY=SA,SU",
+			"2024-03-09 08:00, 2024-03-09 20:00, 2024-03-10 08:00"},
+		{"every day at 9am and 5:30pm", "FREQ=DAILY;BYSECOND=0;BYMINUTE=0;BYHOUR=9",
+			"2024-03-06 17:30, 2024-03-07 09:00, 2024-03-07 17:30, 2024-03-08 09:00"},
+		{"mondays, wednesdays at 9:15 am for 3 times", "FREQ=WEEKLY;COUNT=3;BYSECOND=0;BYMINUTE=15;BYHOUR=9;BYDAY=MO,WE",
+			"2024-03-11 09:15, 2024-03-13 09:15, 2024-03-18 09:15"},
+		{"every day at 9am until friday", "FREQ=DAILY;UNTIL=20240309T045959Z;BYSECOND=0;BYMINUTE=0;BYHOUR=9",
+			"2024-03-07 09:00, 2024-03-08 09:00"},
+		{"every week starting next monday", "FREQ=WEEKLY",
+			"2024-03-11 00:00, 2024-03-18 00:00, 2024-03-25 00:00"},
+		{"daily at 7am starting 2024-04-01 5 times", "FREQ=DAILY;COUNT=5;BYSECOND=0;BYMINUTE=0;BYHOUR=7",
+			"2024-04-01 07:00, 2024-04-02 07:00, 2024-04-03 07:00"},
+	}
+
+	for _, test := range tests {
+		set, err := ParseRecurrence(test.phrase, now, nil)
+		if err != nil {
+			t.Errorf("%s: %v", test.phrase, err)
+			continue
+		}
+		if got := set.RRules[0].String(); got != test.rule {
+			t.Errorf("%s: rule %s, want %s", test.phrase, got, test.rule)
+		}
+		if got := recurrenceDates(set, strings.Count(test.dates, ",")+1); got != test.dates {
+			t.Errorf("%s:\n got %s\nwant %s", test.phrase, got, test.dates)
+		}
+	}
+
+	// Occurrences keep their local time across DST
+	set, err := ParseRecurrence("every day at 9am", now, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dates := set.Dates(5)
+	if dates[2].TzAbbr != "EST" || dates[3].TzAbbr != "EDT" || dates[3].H != 9 || dates[3].Sse-dates[2].Sse != 23*3600 {
+		t.Errorf("DST: got %s %d:%02d and %s %d:%02d", dates[2].TzAbbr, dates[2].H, dates[2].I, dates[3].TzAbbr, dates[3].H, dates[3].I)
+	}
+
+	// UNTIL has the type of DTSTART, so a date ends with that day
+	set, err = ParseRecurrence("every day until 2024-04-02", now, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := set.String(), "DTSTART;TZID=America/New_York:20240307T000000\nRRULE:FREQ=DAILY;UNTIL=20240403T035959Z"; got != want {
+		t.Errorf("until:\n got %s\nwant %s", got, want)
+	}
+
+	// Times with different minutes give a rule each, which share the count
+	set, err = ParseRecurrence("weekdays at 9am, noon and 5:30pm for 4 times", now, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set.RRules) != 2 || set.RRules[1].String() != "FREQ=WEEKLY;UNTIL=20240307T170000Z;BYSECOND=0;BYMINUTE=30;BYHOUR=17;BYDAY=MO,TU,WE,TH,FR" {
+		t.Errorf("rules: got %s", set)
+	}
+	if got, want := recurrenceDates(set, 0), "2024-03-06 12:00, 2024-03-06 17:30, 2024-03-07 09:00, 2024-03-07 12:00"; got != want {
+		t.Errorf("count:\n got %s\nwant %s", got, want)
+	}
+
+	for _, phrase := range []string{
+		"",
+		"every",
+		"every blue moon",
+		"tomorrow",
+		"every day every week",
+		"every 15 minutes at 9am",
+		"every day at teatime",
+		"every day until 2020-01-01",
+		"every 0 days",
+		"every other week on the",
+		"every 2nd day of the week",
+		"for 3 times",
+	} {
+		if _, err := ParseRecurrence(phrase, now, nil); err == nil {
+			t.Errorf("%q: expected an error", phrase)
+		}
+	}
+}
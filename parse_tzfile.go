@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 )
 
@@ -541,6 +542,19 @@ func ParseTzfileFromDB(tzName string, tzdb *TzDB, errorCode *int) (*TzInfo, erro
 		return nil, errors.New("timezone database is nil")
 	}
 
+	// Registered zones and layered databases
+	if data, _, ok, err := loadLayeredTzFile(tzName, tzdb); ok {
+		if err != nil {
+			if errorCode != nil {
+				*errorCode = TIMELIB_ERROR_NO_SUCH_TIMEZONE
+			}
+			return nil, err
+		}
+		tz, err := ParseTzfileData(tzName, data, errorCode)
+		applyZoneLocation(tz, tzName, tzdb)
+		return tz, err
+	}
+
 	// Find timezone in index
 	var entry *TzDBIndexEntry
 	for i := range tzdb.Index {
@@ -564,11 +578,18 @@ func ParseTzfileFromDB(tzName string, tzdb *TzDB, errorCode *int) (*TzInfo, erro
 		return ParseTzfileData(tzName, tzdb.Data[entry.Pos:], errorCode)
 	}
 
-	// Otherwise, try to load from file using BaseDir
-	if entry.Pos == 0 && entry.ID != "" && tzdb.BaseDir != "" {
-		// Construct full path from BaseDir + relative ID
-		fullPath := filepath.Join(tzdb.BaseDir, filepath.FromSlash(entry.ID))
-		return ParseTzfileFromFile(fullPath, errorCode)
+	// Otherwise, try to load from the database file system
+	if entry.Pos == 0 && entry.ID != "" && tzdbFS(tzdb) != nil {
+		data, _, err := readTzDBFile(tzdb, entry)
+		if err != nil {
+			if errorCode != nil {
+				*errorCode = TIMELIB_ERROR_CANNOT_OPEN_FILE
+			}
+			return nil, err
+		}
+		tz, err := ParseTzfileData(path.Base(entry.ID), data, errorCode)
+		applyZoneLocation(tz, entry.ID, tzdb)
+		return tz, err
 	}
 
 	// Try as direct filename if no BaseDir
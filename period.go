@@ -0,0 +1,246 @@
+package timelib
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DatePeriod options, as in PHP's DatePeriod class
+const (
+	TIMELIB_PERIOD_EXCLUDE_START_DATE = 0x01
+	TIMELIB_PERIOD_INCLUDE_END_DATE   = 0x02
+)
+
+// TIMELIB_PERIOD_UNBOUNDED is the recurrence count of a period that repeats
+// forever, such as one parsed from "R/2008-03-01T13:00:00Z/P1D"
+const TIMELIB_PERIOD_UNBOUNDED = -1
+
+// DatePeriod iterates over the dates start + interval × n, like PHP's
+// DatePeriod. Each date is computed from the start rather than from the
+// previous date, so that e.g. monthly dates from January 31st do not drift
+// to the 2nd or 3rd of every following month. Dates are computed lazily by
+// Next, which makes unbounded periods possible.
+//
+// The period ends before End if that is set, or after Recurrences
+// recurrences following the start date otherwise. As in PHP, the start date
+// is included unless TIMELIB_PERIOD_EXCLUDE_START_DATE is set, and
+// TIMELIB_PERIOD_INCLUDE_END_DATE includes End itself, or one more
+// recurrence if the period is bounded by the count.
+type DatePeriod struct {
+	Start       *Time
+	Interval    *RelTime
+	End         *Time // Optional; takes precedence over Recurrences
+	Recurrences int   // Number of recurrences, or TIMELIB_PERIOD_UNBOUNDED
+	Options     int   // TIMELIB_PERIOD_* flags
+
+	started bool
+	n       int64 // multiple of Interval of the next date
+	count   int   // number of dates returned so far
+	last    *Time // previous date, for intervals that cannot be multiplied
+}
+
+// NewDatePeriod creates a period of the start date and a number of
+// recurrences of the interval, or TIMELIB_PERIOD_UNBOUNDED
+func NewDatePeriod(start *Time, interval *RelTime, recurrences int, options int) (*DatePeriod, error) {
+	p := &DatePeriod{Start: start, Interval: interval, Recurrences: recurrences, Options: options}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// NewDatePeriodUntil creates a period of the recurrences of the interval from
+// the start date up to, but not including, the end date
+func NewDatePeriodUntil(start *Time, interval *RelTime, end *Time, options int) (*DatePeriod, error) {
+	if end == nil {
+		return nil, fmt.Errorf("period end date is nil")
+	}
+	p := &DatePeriod{Start: start, Interval: interval, End: end, Options: options}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ParseDatePeriod creates a period from an ISO 8601 recurring interval such
+// as "R5/2008-03-01T13:00:00Z/P1Y2M10DT2H30M", which yields the start date
+// and five recurrences. "R/" without a count repeats forever, and an end
+// date after the interval ("R2/2008-03-01T13:00:00Z/P1D/2008-03-10T00:00:00Z")
+// bounds the period instead of the count.
+func ParseDatePeriod(s string, options int) (*DatePeriod, error) {
+	s = strings.TrimSpace(s)
+
+	spec := s
+	unbounded := strings.HasPrefix(s, "R/")
+	if unbounded {
+		spec = s[len("R/"):]
+	}
+
+	begin, end, period, recurrences, errors := ParseIsoInterval(spec)
+	if errors != nil && errors.ErrorCount > 0 && len(errors.ErrorMessages) > 0 {
+		return nil, &ParseError{
+			Message:  errors.ErrorMessages[0].Message,
+			Position: errors.ErrorMessages[0].Position,
+		}
+	}
+	if begin == nil {
+		return nil, fmt.Errorf("the ISO interval '%s' did not contain a start date", s)
+	}
+	if period == nil {
+		return nil, fmt.Errorf("the ISO interval '%s' did not contain an interval", s)
+	}
+	if unbounded {
+		recurrences = TIMELIB_PERIOD_UNBOUNDED
+	}
+	if end == nil && recurrences == 0 {
+		return nil, fmt.Errorf("the ISO interval '%s' did not contain a recurrence count or an end date", s)
+	}
+
+	p := &DatePeriod{Start: begin, Interval: period, End: end, Recurrences: recurrences, Options: options}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// validate checks the fields of a period before iterating
+func (p *DatePeriod) validate() error {
+	if p.Start == nil {
+		return fmt.Errorf("period start date is nil")
+	}
+	if p.Interval == nil {
+		return fmt.Errorf("period interval is nil")
+	}
+	if relTimeIsEmpty(p.Interval) {
+		return fmt.Errorf("period interval must not be empty")
+	}
+	if p.End == nil && p.Recurrences < 1 && p.Recurrences != TIMELIB_PERIOD_UNBOUNDED {
+		return fmt.Errorf("recurrence count must be greater than 0")
+	}
+	if p.End != nil {
+		// Dates that move away from a later end date would never reach it
+		start, end := p.Start.Clone(), p.End.Clone()
+		if !start.SseUptodate {
+			start.UpdateTS(nil)
+		}
+		if !end.SseUptodate {
+			end.UpdateTS(nil)
+		}
+		if TimeCompare(start, end) < 0 && TimeCompare(p.date(1), start) <= 0 {
+			return fmt.Errorf("period interval must move towards the end date")
+		}
+	}
+	return nil
+}
+
+// relTimeIsEmpty checks whether adding the relative time changes nothing
+func relTimeIsEmpty(r *RelTime) bool {
+	return r.Y == 0 && r.M == 0 && r.D == 0 && r.H == 0 && r.I == 0 && r.S == 0 && r.US == 0 &&
+		!r.HaveWeekdayRelative && !r.HaveSpecialRelative
+}
+
+// Next returns the next date of the period, or false once the period is
+// exhausted. The returned Time is a new value that the caller may modify.
+func (p *DatePeriod) Next() (*Time, bool) {
+	if !p.started {
+		p.started = true
+		if p.Options&TIMELIB_PERIOD_EXCLUDE_START_DATE != 0 {
+			p.n = 1
+		}
+	}
+
+	if p.End == nil && p.Recurrences != TIMELIB_PERIOD_UNBOUNDED {
+		limit := p.Recurrences
+		if p.Options&TIMELIB_PERIOD_EXCLUDE_START_DATE == 0 {
+			limit++
+		}
+		if p.Options&TIMELIB_PERIOD_INCLUDE_END_DATE != 0 {
+			limit++
+		}
+		if p.count >= limit {
+			return nil, false
+		}
+	}
+
+	current := p.date(p.n)
+
+	// Weekday relatives stop moving once a date is on that weekday, and
+	// dates that stop moving towards the end date never reach it
+	if p.last != nil {
+		cmp := TimeCompare(current, p.last)
+		if cmp == 0 || (p.End != nil && cmp < 0) {
+			return nil, false
+		}
+	}
+
+	if p.End != nil {
+		end := p.End.Clone()
+		if !end.SseUptodate {
+			end.UpdateTS(nil)
+		}
+		cmp := TimeCompare(current, end)
+		if cmp > 0 || (cmp == 0 && p.Options&TIMELIB_PERIOD_INCLUDE_END_DATE == 0) {
+			return nil, false
+		}
+	}
+
+	p.n++
+	p.count++
+	p.last = current
+	return current.Clone(), true
+}
+
+// date returns start + interval × n
+func (p *DatePeriod) date(n int64) *Time {
+	if p.Interval.HaveWeekdayRelative || p.Interval.HaveSpecialRelative {
+		// Relative weekdays and special relatives such as "+2 weekdays"
+		// cannot be multiplied, so they are applied to the previous date
+		if p.last == nil {
+			current := p.Start.Add(&RelTime{})
+			for i := int64(0); i < n; i++ {
+				current = current.Add(p.Interval)
+			}
+			return current
+		}
+		return p.last.Add(p.Interval)
+	}
+
+	multiple := *p.Interval
+	multiple.Y *= n
+	multiple.M *= n
+	multiple.D *= n
+	multiple.H *= n
+	multiple.I *= n
+	multiple.S *= n
+	multiple.US *= n
+	return p.Start.Add(&multiple)
+}
+
+// Reset restarts the iteration at the first date
+func (p *DatePeriod) Reset() {
+	p.started = false
+	p.n = 0
+	p.count = 0
+	p.last = nil
+}
+
+// Dates returns up to max dates of the period from its start, or all of
+// them if max is 0 or less. For an unbounded period, max must be positive.
+func (p *DatePeriod) Dates(max int) []*Time {
+	if max <= 0 && p.End == nil && p.Recurrences == TIMELIB_PERIOD_UNBOUNDED {
+		return nil
+	}
+
+	p.Reset()
+	defer p.Reset()
+
+	var dates []*Time
+	for max <= 0 || len(dates) < max {
+		date, ok := p.Next()
+		if !ok {
+			break
+		}
+		dates = append(dates, date)
+	}
+	return dates
+}
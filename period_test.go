@@ -0,0 +1,181 @@
+package timelib
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// periodDates formats the dates of a period for comparison
+func periodDates(p *DatePeriod, max int) string {
+	var dates []string
+	for _, d := range p.Dates(max) {
+		dates = append(dates, fmt.Sprintf("%04d-%02d-%02d %02d:%02d:%02d", d.Y, d.M, d.D, d.H, d.I, d.S))
+	}
+	return strings.Join(dates, ", ")
+}
+
+// TestParseDatePeriod tests periods from ISO 8601 recurring intervals
+func TestParseDatePeriod(t *testing.T) {
+	tests := []struct {
+		spec    string
+		options int
+		max     int
+		want    string
+	}{
+		// The example from PHP's DatePeriod documentation
+		{"R5/2008-03-01T13:00:00Z/P1Y2M10DT2H30M", 0, 0,
+			"2008-03-01 13:00:00, 2009-05-11 15:30:00, 2010-07-21 18:00:00, 2011-10-01 20:30:00, 2012-12-11 23:00:00, 2014-02-21 01:30:00"},
+		{"R3/2008-03-01T13:00:00Z/P1D", TIMELIB_PERIOD_EXCLUDE_START_DATE, 0,
+			"2008-03-02 13:00:00, 2008-03-03 13:00:00, 2008-03-04 13:00:00"},
+		{"R2/2008-03-01T13:00:00Z/P1D", TIMELIB_PERIOD_INCLUDE_END_DATE, 0,
+			"2008-03-01 13:00:00, 2008-03-02 13:00:00, 2008-03-03 13:00:00, 2008-03-04 13:00:00"},
+		// Month ends do not drift
+		{"R4/2024-01-31T00:00:00Z/P1M", 0, 0,
+			"2024-01-31 00:00:00, 2024-03-02 00:00:00, 2024-03-31 00:00:00, 2024-05-01 00:00:00, 2024-05-31 00:00:00"},
+		// Unbounded periods are computed lazily
+		{"R/2008-03-01T13:00:00Z/PT12H", 0, 3,
+			"2008-03-01 13:00:00, 2008-03-02 01:00:00, 2008-03-02 13:00:00"},
+		// An end date bounds the period instead of the count
+		{"R9/2008-03-01T00:00:00Z/P1D/2008-03-03T00:00:00Z", 0, 0,
+			"2008-03-01 00:00:00, 2008-03-02 00:00:00"},
+		{"R9/2008-03-01T00:00:00Z/P1D/2008-03-03T00:00:00Z", TIMELIB_PERIOD_INCLUDE_END_DATE, 0,
+			"2008-03-01 00:00:00, 2008-03-02 00:00:00, 2008-03-03 00:00:00"},
+	}
+
+	for _, test := range tests {
+		p, err := ParseDatePeriod(test.spec, test.options)
+		if err != nil {
+			t.Errorf("%s: %v", test.spec, err)
+			continue
+		}
+		if got := periodDates(p, test.max); got != test.want {
+			t.Errorf("%s (options %d):\n got %s\nwant %s", test.spec, test.options, got, test.want)
+		}
+	}
+
+	for _, spec := range []string{
+		"",
+		"P1D",
+		"2008-03-01T13:00:00Z/P1D",
+		"R5/P1D/2008-03-01T13:00:00Z",
+		"R5/2008-03-01T13:00:00Z/P0D",
+	} {
+		if _, err := ParseDatePeriod(spec, 0); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+}
+
+// TestDatePeriodIteration tests Next, Reset and constructing periods from values
+func TestDatePeriodIteration(t *testing.T) {
+	tz, err := ParseTzfile("America/New_York", BuiltinDB(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Daily at noon across the start of DST keeps the wall clock time
+	start := TimeCtor()
+	start.Y, start.M, start.D, start.H, start.I, start.S = 2024, 3, 9, 12, 0, 0
+	start.TzInfo = tz
+	start.ZoneType = TIMELIB_ZONETYPE_ID
+	start.UpdateTS(tz)
+
+	p, err := NewDatePeriod(start, &RelTime{D: 1}, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for d, ok := p.Next(); ok; d, ok = p.Next() {
+		got = append(got, fmt.Sprintf("%02d %02d:%02d %s %d", d.D, d.H, d.I, d.TzAbbr, d.Sse))
+	}
+	want := "09 12:00 EST 1710003600, 10 12:00 EDT 1710086400, 11 12:00 EDT 1710172800"
+	if strings.Join(got, ", ") != want {
+		t.Errorf("got %s, want %s", strings.Join(got, ", "), want)
+	}
+	if _, ok := p.Next(); ok {
+		t.Error("period should stay exhausted")
+	}
+
+	p.Reset()
+	if d, ok := p.Next(); !ok || d.D != 9 {
+		t.Errorf("Reset did not restart the period")
+	}
+
+	// Every four days up to an end date, without the start date
+	end := start.Clone()
+	end.D = 20
+	end.UpdateTS(tz)
+	p, err = NewDatePeriodUntil(start, &RelTime{D: 4}, end, TIMELIB_PERIOD_EXCLUDE_START_DATE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := periodDates(p, 0); got != "2024-03-13 12:00:00, 2024-03-17 12:00:00" {
+		t.Errorf("got %s", got)
+	}
+
+	// Weekday relatives are applied to the previous date
+	weekdays := &RelTime{HaveSpecialRelative: true}
+	weekdays.Special.Type = TIMELIB_SPECIAL_WEEKDAY
+	weekdays.Special.Amount = 1
+	friday := TimeCtor()
+	friday.Y, friday.M, friday.D, friday.H, friday.I, friday.S = 2024, 3, 8, 9, 0, 0
+	p, err = NewDatePeriod(friday, weekdays, 3, TIMELIB_PERIOD_EXCLUDE_START_DATE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := periodDates(p, 0); got != "2024-03-11 09:00:00, 2024-03-12 09:00:00, 2024-03-13 09:00:00" {
+		t.Errorf("weekdays: got %s", got)
+	}
+
+	if _, err := NewDatePeriod(start, &RelTime{D: 1}, 0, 0); err == nil {
+		t.Error("expected an error for zero recurrences")
+	}
+	if _, err := NewDatePeriodUntil(start, &RelTime{D: 1}, nil, 0); err == nil {
+		t.Error("expected an error for a nil end date")
+	}
+	if _, err := NewDatePeriod(nil, &RelTime{D: 1}, 1, 0); err == nil {
+		t.Error("expected an error for a nil start date")
+	}
+
+	// An interval that points away from a later end date never reaches it
+	for _, interval := range []*RelTime{{D: -1}, {D: 1, Invert: true}, {D: 1, H: -25}} {
+		if _, err := NewDatePeriodUntil(start, interval, end, 0); err == nil {
+			t.Errorf("expected an error for interval %+v", interval)
+		}
+	}
+
+	// A weekday relative stops at the first Monday, and ends the period
+	sunday, until := testTime(2024, 3, 31, 0, 0, 0, nil), testTime(2024, 5, 1, 0, 0, 0, nil)
+	monday, err := StrToTime("monday", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err = NewDatePeriodUntil(sunday, &monday.Relative, until, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := periodDates(p, 0); got != "2024-03-31 00:00:00, 2024-04-01 00:00:00" {
+		t.Errorf("monday: got %s", got)
+	}
+
+	back, err := NewDatePeriodUntil(end, &RelTime{D: -4}, start, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dates := back.Dates(0); len(dates) != 0 {
+		t.Errorf("got %d dates for an end date before the start", len(dates))
+	}
+
+	unbounded, err := NewDatePeriod(start, &RelTime{M: 1}, TIMELIB_PERIOD_UNBOUNDED, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dates := unbounded.Dates(0); dates != nil {
+		t.Errorf("Dates(0) of an unbounded period returned %d dates", len(dates))
+	}
+	if dates := unbounded.Dates(24); len(dates) != 24 || dates[23].Y != 2026 || dates[23].M != 2 {
+		t.Errorf("Dates(24) of an unbounded period returned %d dates", len(dates))
+	}
+}
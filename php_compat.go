@@ -65,6 +65,45 @@ func Strtotime(datetime string, baseTimestamp int64) int64 {
 	return parsedTime.Sse
 }
 
+// StrtotimeInTimezone is like Strtotime, but interprets the string in the
+// given timezone instead of UTC, the way PHP's strtotime() uses the default
+// timezone. A nil tz selects DefaultTimezone(). A zone given in the string
+// itself takes precedence, and is looked up in tzdb, which should be the
+// database tz comes from; nil selects BuiltinDB().
+//
+// Example:
+//
+//	SetDefaultTimezone("Europe/Amsterdam", nil)
+//	ts := StrtotimeInTimezone("2024-07-01 12:00", 0, nil, nil) // 10:00 UTC
+func StrtotimeInTimezone(datetime string, baseTimestamp int64, tz *TzInfo, tzdb *TzDB) int64 {
+	if tz == nil {
+		_, tz = DefaultTimezone()
+	}
+	if tzdb == nil {
+		tzdb = BuiltinDB()
+	}
+
+	if baseTimestamp == 0 {
+		baseTimestamp = time.Now().Unix()
+	}
+
+	// The base time in the local time of the zone
+	now := TimeCtor()
+	now.TzInfo = tz
+	now.ZoneType = TIMELIB_ZONETYPE_ID
+	now.Unixtime2local(baseTimestamp)
+
+	parsedTime, err := StrToTime(datetime, tzdb)
+	if err != nil {
+		return -1
+	}
+
+	FillHoles(parsedTime, now, TIMELIB_NO_CLONE)
+	parsedTime.UpdateTS(tz)
+
+	return parsedTime.Sse
+}
+
 // StrtotimeToGoTime parses a date/time string and returns a Go time.Time value,
 // similar to PHP's strtotime() but returning Go's native time type.
 //
@@ -0,0 +1,337 @@
+package timelib
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Relative times as durations
+//
+// The operations in this file treat a RelTime as a duration with calendar
+// parts (years, months and days) and an exact time part (hours down to
+// microseconds). Given a reference time, the calendar parts are counted on
+// the calendar and in the zone of the reference, as AddWall does, so that
+// P1M is 29 days from 2024-02-01 and P1D is 23 hours on the day DST starts.
+// Without a reference, a day is 24 hours and years and months can only be
+// converted into each other.
+//
+// Weekday and special relatives, such as "next monday", have no length and
+// are rejected.
+
+const usPerDay = SECS_PER_DAY * 1000000
+
+// relTimeParts returns the signed years, months, days and exact time in
+// microseconds of a relative time
+func relTimeParts(r *RelTime) (y, m, d, us int64, err error) {
+	if r.HaveWeekdayRelative || r.HaveSpecialRelative {
+		return 0, 0, 0, 0, fmt.Errorf("relative time with weekday or special relatives has no length")
+	}
+	bias := int64(1)
+	if r.Invert {
+		bias = -1
+	}
+	us = ((r.H*60+r.I)*60+r.S)*1000000 + r.US
+	return r.Y * bias, r.M * bias, r.D * bias, us * bias, nil
+}
+
+// relTimeFromParts builds a relative time from signed parts, splitting the
+// time part into units up to largestUnit. If no part is positive, the
+// result has positive fields and Invert set, as Diff returns.
+func relTimeFromParts(y, m, d, us int64, largestUnit int) *RelTime {
+	r := RelTimeCtor()
+	if y <= 0 && m <= 0 && d <= 0 && us <= 0 && (y != 0 || m != 0 || d != 0 || us != 0) {
+		y, m, d, us = -y, -m, -d, -us
+		r.Invert = true
+	}
+	r.Y, r.M, r.D = y, m, d
+
+	r.US = us % 1000000
+	s := us / 1000000
+	switch largestUnit {
+	case TIMELIB_MICROSEC:
+		r.US = us
+	case TIMELIB_SECOND:
+		r.S = s
+	case TIMELIB_MINUTE:
+		r.I, r.S = s/60, s%60
+	default:
+		r.H, r.I, r.S = s/3600, s/60%60, s%60
+	}
+	return r
+}
+
+// relTimeReference returns a copy of a reference time with an up to date
+// timestamp
+func relTimeReference(relativeTo *Time) *Time {
+	ref := relativeTo.Clone()
+	if !ref.SseUptodate {
+		ref.UpdateTS(ref.TzInfo)
+	}
+	return ref
+}
+
+// relTimeCount returns the largest number of units (TIMELIB_YEAR,
+// TIMELIB_MONTH or TIMELIB_DAY) that can be added to ref after the calendar
+// parts y, m and d without passing end, counting down if sign is negative
+func relTimeCount(ref, end *Time, y, m, d int64, unit int, sign int64) int64 {
+	at := func(n int64) *Time {
+		switch unit {
+		case TIMELIB_YEAR:
+			return ref.AddWall(&RelTime{Y: y + n, M: m, D: d})
+		case TIMELIB_MONTH:
+			return ref.AddWall(&RelTime{Y: y, M: m + n, D: d})
+		}
+		return ref.AddWall(&RelTime{Y: y, M: m, D: d + n})
+	}
+	passes := func(n int64) bool {
+		if sign > 0 {
+			return timeKey(at(n)) > timeKey(end)
+		}
+		return timeKey(at(n)) < timeKey(end)
+	}
+
+	// Estimate from the average length of the unit, then correct it
+	length := map[int]float64{TIMELIB_YEAR: 365.2425, TIMELIB_MONTH: 30.436875, TIMELIB_DAY: 1}[unit] * usPerDay
+	n := int64(float64(timeKey(end)-timeKey(at(0))) / length)
+	for n != 0 && passes(n) {
+		n -= sign
+	}
+	for !passes(n + sign) {
+		n += sign
+	}
+	return n
+}
+
+// Compare compares the lengths of two relative times, returning -1, 0 or 1.
+// A reference time is needed if either has years or months, unless both
+// have nothing else.
+func (r *RelTime) Compare(other *RelTime, relativeTo *Time) (int, error) {
+	y1, m1, d1, us1, err := relTimeParts(r)
+	if err != nil {
+		return 0, err
+	}
+	y2, m2, d2, us2, err := relTimeParts(other)
+	if err != nil {
+		return 0, err
+	}
+
+	var a, b int64
+	switch {
+	case relativeTo != nil:
+		ref := relTimeReference(relativeTo)
+		a, b = timeKey(ref.AddWall(r)), timeKey(ref.AddWall(other))
+	case y1 == 0 && m1 == 0 && y2 == 0 && m2 == 0:
+		a, b = d1*usPerDay+us1, d2*usPerDay+us2
+	case d1 == 0 && us1 == 0 && d2 == 0 && us2 == 0:
+		a, b = y1*12+m1, y2*12+m2
+	default:
+		return 0, fmt.Errorf("comparing relative times with years or months needs a reference time")
+	}
+
+	switch {
+	case a < b:
+		return -1, nil
+	case a > b:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Total returns the length of a relative time in a unit: TIMELIB_YEAR,
+// TIMELIB_MONTH, TIMELIB_DAY, TIMELIB_HOUR, TIMELIB_MINUTE, TIMELIB_SECOND
+// or TIMELIB_MICROSEC. Partial years, months and days are fractions of the
+// length of the next one from the reference time, so that P1M15D is 1.5
+// months from 2024-03-01.
+func (r *RelTime) Total(unit int, relativeTo *Time) (float64, error) {
+	y, m, d, us, err := relTimeParts(r)
+	if err != nil {
+		return 0, err
+	}
+
+	var unitUS int64
+	switch unit {
+	case TIMELIB_YEAR, TIMELIB_MONTH, TIMELIB_DAY:
+		unitUS = usPerDay
+	case TIMELIB_HOUR:
+		unitUS = 3600000000
+	case TIMELIB_MINUTE:
+		unitUS = 60000000
+	case TIMELIB_SECOND:
+		unitUS = 1000000
+	case TIMELIB_MICROSEC:
+		unitUS = 1
+	default:
+		return 0, fmt.Errorf("unsupported unit %d", unit)
+	}
+
+	if relativeTo == nil {
+		switch {
+		case unit == TIMELIB_YEAR && d == 0 && us == 0:
+			return float64(y*12+m) / 12, nil
+		case unit == TIMELIB_MONTH && d == 0 && us == 0:
+			return float64(y*12 + m), nil
+		case unit == TIMELIB_YEAR || unit == TIMELIB_MONTH || y != 0 || m != 0:
+			return 0, fmt.Errorf("the total of a relative time with years or months needs a reference time")
+		}
+		return float64(d*usPerDay+us) / float64(unitUS), nil
+	}
+
+	ref := relTimeReference(relativeTo)
+	end := ref.AddWall(r)
+	if unit != TIMELIB_YEAR && unit != TIMELIB_MONTH && unit != TIMELIB_DAY {
+		return float64(timeKey(end)-timeKey(ref)) / float64(unitUS), nil
+	}
+
+	sign := int64(1)
+	if timeKey(end) < timeKey(ref) {
+		sign = -1
+	}
+	n := relTimeCount(ref, end, 0, 0, 0, unit, sign)
+	var lo, hi *Time
+	switch unit {
+	case TIMELIB_YEAR:
+		lo, hi = ref.AddWall(&RelTime{Y: n}), ref.AddWall(&RelTime{Y: n + sign})
+	case TIMELIB_MONTH:
+		lo, hi = ref.AddWall(&RelTime{M: n}), ref.AddWall(&RelTime{M: n + sign})
+	default:
+		lo, hi = ref.AddWall(&RelTime{D: n}), ref.AddWall(&RelTime{D: n + sign})
+	}
+	fraction := float64(timeKey(end)-timeKey(lo)) / float64(timeKey(hi)-timeKey(lo))
+	return float64(n) + math.Abs(fraction)*float64(sign), nil
+}
+
+// Normalize returns a relative time of the same length with each unit
+// carried into the next, up to largestUnit, so that PT90M becomes PT1H30M.
+// With TIMELIB_HOUR or smaller, days are converted into hours; with
+// TIMELIB_DAY hours are carried into days, and with TIMELIB_MONTH or
+// TIMELIB_YEAR days into months and months into years.
+//
+// Without a reference time, days are 24 hours and are never carried into
+// months, and years and months are only converted into each other.
+func (r *RelTime) Normalize(largestUnit int, relativeTo *Time) (*RelTime, error) {
+	y, m, d, us, err := relTimeParts(r)
+	if err != nil {
+		return nil, err
+	}
+	switch largestUnit {
+	case TIMELIB_YEAR, TIMELIB_MONTH, TIMELIB_DAY, TIMELIB_HOUR, TIMELIB_MINUTE, TIMELIB_SECOND, TIMELIB_MICROSEC:
+	default:
+		return nil, fmt.Errorf("unsupported unit %d", largestUnit)
+	}
+	calendar := largestUnit == TIMELIB_YEAR || largestUnit == TIMELIB_MONTH || largestUnit == TIMELIB_DAY
+
+	if relativeTo == nil {
+		if !calendar {
+			if y != 0 || m != 0 {
+				return nil, fmt.Errorf("converting years or months into hours needs a reference time")
+			}
+			return relTimeFromParts(0, 0, 0, d*usPerDay+us, largestUnit), nil
+		}
+
+		d += us / usPerDay
+		us %= usPerDay
+		switch largestUnit {
+		case TIMELIB_YEAR:
+			y += m / 12
+			m %= 12
+		case TIMELIB_MONTH:
+			m += y * 12
+			y = 0
+		}
+		return relTimeFromParts(y, m, d, us, TIMELIB_HOUR), nil
+	}
+
+	ref := relTimeReference(relativeTo)
+	end := ref.AddWall(r)
+	if !calendar {
+		return relTimeFromParts(0, 0, 0, timeKey(end)-timeKey(ref), largestUnit), nil
+	}
+
+	sign := int64(1)
+	if timeKey(end) < timeKey(ref) {
+		sign = -1
+	}
+	y, m, d = 0, 0, 0
+	if largestUnit == TIMELIB_YEAR {
+		y = relTimeCount(ref, end, 0, 0, 0, TIMELIB_YEAR, sign)
+	}
+	if largestUnit != TIMELIB_DAY {
+		m = relTimeCount(ref, end, y, 0, 0, TIMELIB_MONTH, sign)
+	}
+	d = relTimeCount(ref, end, y, m, 0, TIMELIB_DAY, sign)
+	us = timeKey(end) - timeKey(ref.AddWall(&RelTime{Y: y, M: m, D: d}))
+	return relTimeFromParts(y, m, d, us, TIMELIB_HOUR), nil
+}
+
+// Add returns the sum of two relative times. Years, months and days are
+// added field by field, and the time fields are carried up to hours; use
+// Normalize to carry further.
+func (r *RelTime) Add(other *RelTime) (*RelTime, error) {
+	y1, m1, d1, us1, err := relTimeParts(r)
+	if err != nil {
+		return nil, err
+	}
+	y2, m2, d2, us2, err := relTimeParts(other)
+	if err != nil {
+		return nil, err
+	}
+	return relTimeFromParts(y1+y2, m1+m2, d1+d2, us1+us2, TIMELIB_HOUR), nil
+}
+
+// Sub returns the difference of two relative times, field by field
+func (r *RelTime) Sub(other *RelTime) (*RelTime, error) {
+	return r.Add(other.Negate())
+}
+
+// Negate returns a relative time of the same length in the other direction
+func (r *RelTime) Negate() *RelTime {
+	result := RelTimeClone(r)
+	result.Invert = !result.Invert
+	return result
+}
+
+// Multiply returns a relative time with each field multiplied by n
+func (r *RelTime) Multiply(n int64) (*RelTime, error) {
+	y, m, d, us, err := relTimeParts(r)
+	if err != nil {
+		return nil, err
+	}
+	return relTimeFromParts(y*n, m*n, d*n, us*n, TIMELIB_HOUR), nil
+}
+
+// Duration returns the length of a relative time as a time.Duration. It is
+// exact for relative times without years or months; with them, a reference
+// time is needed and the length is counted from it, as Total does. Lengths
+// beyond the range of time.Duration, about 292 years, are an error.
+func (r *RelTime) Duration(relativeTo *Time) (time.Duration, error) {
+	y, m, d, us, err := relTimeParts(r)
+	if err != nil {
+		return 0, err
+	}
+
+	if relativeTo != nil {
+		ref := relTimeReference(relativeTo)
+		us = timeKey(ref.AddWall(r)) - timeKey(ref)
+	} else {
+		if y != 0 || m != 0 {
+			return 0, fmt.Errorf("converting years or months into a duration needs a reference time")
+		}
+		if d > math.MaxInt64/usPerDay || d < math.MinInt64/usPerDay {
+			return 0, fmt.Errorf("relative time is out of the range of a duration")
+		}
+		us += d * usPerDay
+	}
+
+	if us > int64(math.MaxInt64/time.Microsecond) || us < int64(math.MinInt64/time.Microsecond) {
+		return 0, fmt.Errorf("relative time is out of the range of a duration")
+	}
+	return time.Duration(us) * time.Microsecond, nil
+}
+
+// RelTimeFromDuration returns a relative time of the length of d in hours,
+// minutes, seconds and microseconds, with Invert set if d is negative.
+// Nanoseconds are truncated.
+func RelTimeFromDuration(d time.Duration) *RelTime {
+	return relTimeFromParts(0, 0, 0, d.Microseconds(), TIMELIB_HOUR)
+}
@@ -0,0 +1,233 @@
+package timelib
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// relTimeString formats a relative time for comparison
+func relTimeString(r *RelTime) string {
+	sign := ""
+	if r.Invert {
+		sign = "-"
+	}
+	return fmt.Sprintf("%s%dY%dM%dD %dH%dI%dS%dUS", sign, r.Y, r.M, r.D, r.H, r.I, r.S, r.US)
+}
+
+// mustInterval parses an ISO 8601 duration for a test
+func mustInterval(t *testing.T, s string) *RelTime {
+	t.Helper()
+	_, _, period, _, err := Strtointerval(s, nil)
+	if err != nil {
+		t.Fatalf("%s: %v", s, err)
+	}
+	return period
+}
+
+// TestRelTimeCompare tests comparing durations with and without a reference
+func TestRelTimeCompare(t *testing.T) {
+	tz, err := ParseTzfile("America/New_York", BuiltinDB(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		a, b string
+		ref  *Time
+		want int
+	}{
+		{"P1M", "P30D", testTime(2024, 1, 1, 0, 0, 0, nil), 1},
+		{"P1M", "P30D", testTime(2024, 2, 1, 0, 0, 0, nil), -1},
+		{"P1M", "P30D", testTime(2024, 4, 1, 0, 0, 0, nil), 0},
+		{"PT36H", "P1DT12H", nil, 0},
+		{"P1Y", "P12M", nil, 0},
+		{"P2W", "P13DT24H1S", nil, -1},
+		// The day DST starts is 23 hours long
+		{"P1D", "PT23H", testTime(2024, 3, 10, 0, 0, 0, tz), 0},
+		{"P1D", "PT23H", nil, 1},
+	}
+	for _, test := range tests {
+		got, err := mustInterval(t, test.a).Compare(mustInterval(t, test.b), test.ref)
+		if err != nil || got != test.want {
+			t.Errorf("%s vs %s: got %d (%v), want %d", test.a, test.b, got, err, test.want)
+		}
+	}
+
+	if _, err := mustInterval(t, "P1M").Compare(mustInterval(t, "P30D"), nil); err == nil {
+		t.Errorf("expected an error without a reference time")
+	}
+}
+
+// TestRelTimeTotal tests the length of durations in a unit
+func TestRelTimeTotal(t *testing.T) {
+	tz, err := ParseTzfile("America/New_York", BuiltinDB(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		period string
+		unit   int
+		ref    *Time
+		want   float64
+	}{
+		{"P1DT2H", TIMELIB_HOUR, nil, 26},
+		{"PT1H30M", TIMELIB_MINUTE, nil, 90},
+		{"PT0.5S", TIMELIB_MICROSEC, nil, 500000},
+		{"P1DT12H", TIMELIB_DAY, nil, 1.5},
+		{"P1Y6M", TIMELIB_YEAR, nil, 1.5},
+		{"P2Y", TIMELIB_MONTH, nil, 24},
+		{"P1M15D", TIMELIB_MONTH, testTime(2024, 3, 1, 0, 0, 0, nil), 1.5},
+		{"P1M", TIMELIB_DAY, testTime(2024, 2, 1, 0, 0, 0, nil), 29},
+		{"P1Y", TIMELIB_DAY, testTime(2024, 1, 1, 0, 0, 0, nil), 366},
+		{"P1D", TIMELIB_HOUR, testTime(2024, 11, 3, 0, 0, 0, tz), 25},
+		{"PT35H", TIMELIB_DAY, testTime(2024, 3, 10, 0, 0, 0, tz), 1.5},
+	}
+	for _, test := range tests {
+		got, err := mustInterval(t, test.period).Total(test.unit, test.ref)
+		if err != nil || got != test.want {
+			t.Errorf("%s in unit %d: got %v (%v), want %v", test.period, test.unit, got, err, test.want)
+		}
+	}
+
+	// Negative durations count backwards from the reference
+	negative := mustInterval(t, "P1M").Negate()
+	if got, _ := negative.Total(TIMELIB_DAY, testTime(2024, 3, 1, 0, 0, 0, nil)); got != -29 {
+		t.Errorf("-P1M in days: got %v, want -29", got)
+	}
+
+	if _, err := mustInterval(t, "P1M").Total(TIMELIB_DAY, nil); err == nil {
+		t.Errorf("expected an error without a reference time")
+	}
+	weekday := RelTimeCtor()
+	weekday.HaveWeekdayRelative = true
+	if _, err := weekday.Total(TIMELIB_DAY, nil); err == nil {
+		t.Errorf("expected an error for a weekday relative")
+	}
+}
+
+// TestRelTimeNormalize tests carrying units with and without a reference
+func TestRelTimeNormalize(t *testing.T) {
+	tz, err := ParseTzfile("America/New_York", BuiltinDB(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		period  string
+		largest int
+		ref     *Time
+		want    string
+	}{
+		{"PT90M", TIMELIB_HOUR, nil, "0Y0M0D 1H30I0S0US"},
+		{"P1DT26H", TIMELIB_HOUR, nil, "0Y0M0D 50H0I0S0US"},
+		{"P1DT26H", TIMELIB_DAY, nil, "0Y0M2D 2H0I0S0US"},
+		{"PT3723.5S", TIMELIB_MINUTE, nil, "0Y0M0D 0H62I3S500000US"},
+		{"P14M40D", TIMELIB_YEAR, nil, "1Y2M40D 0H0I0S0US"},
+		{"P1Y2M", TIMELIB_MONTH, nil, "0Y14M0D 0H0I0S0US"},
+		// A month from January 31st overflows into March, as with Add
+		{"P40D", TIMELIB_MONTH, testTime(2024, 1, 31, 0, 0, 0, nil), "0Y1M9D 0H0I0S0US"},
+		{"P400D", TIMELIB_YEAR, testTime(2024, 1, 1, 0, 0, 0, nil), "1Y1M3D 0H0I0S0US"},
+		{"P1M", TIMELIB_HOUR, testTime(2024, 2, 1, 0, 0, 0, nil), "0Y0M0D 696H0I0S0US"},
+		// Across the start of DST, 24 hours is a day and an hour
+		{"PT24H", TIMELIB_DAY, testTime(2024, 3, 10, 0, 0, 0, tz), "0Y0M1D 1H0I0S0US"},
+		{"PT24H", TIMELIB_DAY, nil, "0Y0M1D 0H0I0S0US"},
+	}
+	for _, test := range tests {
+		got, err := mustInterval(t, test.period).Normalize(test.largest, test.ref)
+		if err != nil {
+			t.Errorf("%s: %v", test.period, err)
+			continue
+		}
+		if relTimeString(got) != test.want {
+			t.Errorf("%s up to unit %d: got %s, want %s", test.period, test.largest, relTimeString(got), test.want)
+		}
+	}
+
+	if _, err := mustInterval(t, "P1M").Normalize(TIMELIB_HOUR, nil); err == nil {
+		t.Errorf("expected an error without a reference time")
+	}
+}
+
+// TestRelTimeArithmetic tests adding, subtracting, negating and multiplying
+func TestRelTimeArithmetic(t *testing.T) {
+	a, b := mustInterval(t, "P1Y2M3DT4H50M"), mustInterval(t, "P1MT20M30S")
+
+	sum, _ := a.Add(b)
+	diff, _ := a.Sub(b)
+	reverse, _ := b.Sub(a)
+	product, _ := b.Multiply(3)
+	tests := []struct {
+		name string
+		got  *RelTime
+		want string
+	}{
+		{"add", sum, "1Y3M3D 5H10I30S0US"},
+		{"sub", diff, "1Y1M3D 4H29I30S0US"},
+		{"reverse sub", reverse, "-1Y1M3D 4H29I30S0US"},
+		{"negate", a.Negate(), "-1Y2M3D 4H50I0S0US"},
+		{"multiply", product, "0Y3M0D 1H1I30S0US"},
+	}
+	for _, test := range tests {
+		if relTimeString(test.got) != test.want {
+			t.Errorf("%s: got %s, want %s", test.name, relTimeString(test.got), test.want)
+		}
+	}
+
+	// Fields keep their own sign when they differ
+	mixed, _ := mustInterval(t, "P1M").Sub(mustInterval(t, "P3D"))
+	if mixed.Invert || mixed.M != 1 || mixed.D != -3 {
+		t.Errorf("mixed signs: got %s", relTimeString(mixed))
+	}
+}
+
+// TestRelTimeDuration tests converting to and from time.Duration
+func TestRelTimeDuration(t *testing.T) {
+	tz, err := ParseTzfile("America/New_York", BuiltinDB(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		period string
+		ref    *Time
+		want   time.Duration
+	}{
+		{"PT1H30M45.5S", nil, time.Hour + 30*time.Minute + 45500*time.Millisecond},
+		{"P1DT1H", nil, 25 * time.Hour},
+		{"P1M", testTime(2024, 2, 1, 0, 0, 0, nil), 29 * 24 * time.Hour},
+		{"P1D", testTime(2024, 3, 10, 0, 0, 0, tz), 23 * time.Hour},
+	}
+	for _, test := range tests {
+		got, err := mustInterval(t, test.period).Duration(test.ref)
+		if err != nil || got != test.want {
+			t.Errorf("%s: got %v (%v), want %v", test.period, got, err, test.want)
+		}
+	}
+
+	if _, err := mustInterval(t, "P1M").Duration(nil); err == nil {
+		t.Errorf("expected an error without a reference time")
+	}
+	if _, err := mustInterval(t, "P300Y").Duration(testTime(2000, 1, 1, 0, 0, 0, nil)); err == nil {
+		t.Errorf("expected an error beyond the range of a duration")
+	}
+
+	fromTests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{90 * time.Minute, "0Y0M0D 1H30I0S0US"},
+		{-(26*time.Hour + 1500*time.Microsecond + 999), "-0Y0M0D 26H0I0S1500US"},
+		{0, "0Y0M0D 0H0I0S0US"},
+	}
+	for _, test := range fromTests {
+		got := RelTimeFromDuration(test.d)
+		if relTimeString(got) != test.want {
+			t.Errorf("%v: got %s, want %s", test.d, relTimeString(got), test.want)
+		}
+		if back, _ := got.Duration(nil); back != test.d.Truncate(time.Microsecond) {
+			t.Errorf("%v: round trip gave %v", test.d, back)
+		}
+	}
+}
@@ -0,0 +1,715 @@
+package timelib
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Frequencies of a recurrence rule (RFC 5545 FREQ)
+const (
+	TIMELIB_FREQ_SECONDLY = iota + 1
+	TIMELIB_FREQ_MINUTELY
+	TIMELIB_FREQ_HOURLY
+	TIMELIB_FREQ_DAILY
+	TIMELIB_FREQ_WEEKLY
+	TIMELIB_FREQ_MONTHLY
+	TIMELIB_FREQ_YEARLY
+)
+
+// rruleFreqNames are the FREQ values, indexed by the TIMELIB_FREQ_* constants
+var rruleFreqNames = []string{"", "SECONDLY", "MINUTELY", "HOURLY", "DAILY", "WEEKLY", "MONTHLY", "YEARLY"}
+
+// rruleDayNames are the BYDAY and WKST day names, indexed like DayOfWeek
+var rruleDayNames = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// rruleMaxYear ends the expansion of rules that never match again, as
+// RFC 5545 dates have four-digit years
+const rruleMaxYear = 9999
+
+// RRuleWeekday is a BYDAY entry such as "MO", "+2TU" or "-1FR"
+type RRuleWeekday struct {
+	Day int64 // Day of the week as in DayOfWeek, 0=Sunday..6=Saturday
+	N   int64 // Occurrence within the month or year, negative from the end; 0 for all
+}
+
+// RRule is an RFC 5545 recurrence rule, e.g. "FREQ=MONTHLY;BYDAY=-1FR".
+// Occurrences are expanded in the local time of the start date, so that a
+// rule starting at 09:00 in a named zone stays at 09:00 across DST changes.
+// Local times in a DST gap are moved forward by the length of the gap.
+type RRule struct {
+	Freq       int   // One of the TIMELIB_FREQ_* constants
+	Interval   int64 // Number of periods between occurrences, 1 if 0
+	Count      int64 // Number of occurrences, 0 for no limit
+	Until      *Time // Last possible occurrence, inclusive; nil for no limit
+	BySecond   []int64
+	ByMinute   []int64
+	ByHour     []int64
+	ByDay      []RRuleWeekday
+	ByMonthDay []int64
+	ByYearDay  []int64
+	ByWeekNo   []int64
+	ByMonth    []int64
+	BySetPos   []int64
+	Wkst       int64 // First day of the week as in DayOfWeek; NewRRule and ParseRRule set Monday
+}
+
+// NewRRule creates a rule with the given frequency, an interval of 1 and
+// weeks starting on Monday
+func NewRRule(freq int) *RRule {
+	return &RRule{Freq: freq, Interval: 1, Wkst: 1}
+}
+
+// ParseRRule parses the text form of a recurrence rule, with or without the
+// "RRULE:" prefix. UNTIL may be a date, a UTC date-time ("19971224T000000Z")
+// or a floating date-time, which is taken in the zone of the start date.
+func ParseRRule(s string) (*RRule, error) {
+	s = strings.TrimSpace(s)
+	if len(s) >= 6 && strings.EqualFold(s[:6], "RRULE:") {
+		s = s[6:]
+	}
+
+	r := NewRRule(0)
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		if !ok || name == "" || value == "" {
+			return nil, fmt.Errorf("invalid RRULE part '%s'", part)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("RRULE part %s appears more than once", name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			r.Freq = indexOfName(rruleFreqNames[1:], value) + 1
+			if r.Freq == 0 {
+				err = fmt.Errorf("unknown frequency")
+			}
+		case "INTERVAL":
+			r.Interval, err = strconv.ParseInt(value, 10, 64)
+			if err == nil && r.Interval < 1 {
+				err = fmt.Errorf("must be positive")
+			}
+		case "COUNT":
+			r.Count, err = strconv.ParseInt(value, 10, 64)
+			if err == nil && r.Count < 1 {
+				err = fmt.Errorf("must be positive")
+			}
+		case "UNTIL":
+			r.Until, err = parseICalDateTime(value, nil)
+		case "BYSECOND":
+			r.BySecond, err = parseRRuleNumbers(value)
+		case "BYMINUTE":
+			r.ByMinute, err = parseRRuleNumbers(value)
+		case "BYHOUR":
+			r.ByHour, err = parseRRuleNumbers(value)
+		case "BYDAY":
+			r.ByDay, err = parseRRuleWeekdays(value)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseRRuleNumbers(value)
+		case "BYYEARDAY":
+			r.ByYearDay, err = parseRRuleNumbers(value)
+		case "BYWEEKNO":
+			r.ByWeekNo, err = parseRRuleNumbers(value)
+		case "BYMONTH":
+			r.ByMonth, err = parseRRuleNumbers(value)
+		case "BYSETPOS":
+			r.BySetPos, err = parseRRuleNumbers(value)
+		case "WKST":
+			r.Wkst = int64(indexOfName(rruleDayNames, value))
+			if r.Wkst < 0 {
+				err = fmt.Errorf("unknown day")
+			}
+		default:
+			err = fmt.Errorf("unsupported rule part")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid RRULE %s '%s': %v", name, value, err)
+		}
+	}
+
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// indexOfName returns the index of a name in a list, ignoring case, or -1
+func indexOfName(names []string, name string) int {
+	for i, n := range names {
+		if strings.EqualFold(n, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseRRuleNumbers parses a comma-separated list of signed integers
+func parseRRuleNumbers(value string) ([]int64, error) {
+	var numbers []int64
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.ParseInt(item, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number '%s'", item)
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, nil
+}
+
+// parseRRuleWeekdays parses a BYDAY list such as "MO,WE,-1FR"
+func parseRRuleWeekdays(value string) ([]RRuleWeekday, error) {
+	var days []RRuleWeekday
+	for _, item := range strings.Split(value, ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("invalid day '%s'", item)
+		}
+		day := indexOfName(rruleDayNames, item[len(item)-2:])
+		if day < 0 {
+			return nil, fmt.Errorf("invalid day '%s'", item)
+		}
+
+		var n int64
+		if prefix := item[:len(item)-2]; prefix != "" {
+			var err error
+			if n, err = strconv.ParseInt(prefix, 10, 64); err != nil || n == 0 {
+				return nil, fmt.Errorf("invalid day '%s'", item)
+			}
+		}
+		days = append(days, RRuleWeekday{Day: int64(day), N: n})
+	}
+	return days, nil
+}
+
+// Validate checks the rule against the value ranges and the combinations of
+// rule parts that RFC 5545 allows
+func (r *RRule) Validate() error {
+	if r.Freq < TIMELIB_FREQ_SECONDLY || r.Freq > TIMELIB_FREQ_YEARLY {
+		return fmt.Errorf("RRULE needs a valid FREQ")
+	}
+	if r.Interval < 0 || r.Count < 0 {
+		return fmt.Errorf("RRULE INTERVAL and COUNT cannot be negative")
+	}
+	if r.Count > 0 && r.Until != nil {
+		return fmt.Errorf("RRULE cannot have both COUNT and UNTIL")
+	}
+	if r.Wkst < 0 || r.Wkst > 6 {
+		return fmt.Errorf("RRULE WKST out of range: %d", r.Wkst)
+	}
+
+	ranges := []struct {
+		name     string
+		values   []int64
+		min, max int64
+		signed   bool
+	}{
+		{"BYSECOND", r.BySecond, 0, 60, false},
+		{"BYMINUTE", r.ByMinute, 0, 59, false},
+		{"BYHOUR", r.ByHour, 0, 23, false},
+		{"BYMONTHDAY", r.ByMonthDay, 1, 31, true},
+		{"BYYEARDAY", r.ByYearDay, 1, 366, true},
+		{"BYWEEKNO", r.ByWeekNo, 1, 53, true},
+		{"BYMONTH", r.ByMonth, 1, 12, false},
+		{"BYSETPOS", r.BySetPos, 1, 366, true},
+	}
+	for _, rng := range ranges {
+		for _, v := range rng.values {
+			if rng.signed && v < 0 {
+				v = -v
+			}
+			if v < rng.min || v > rng.max {
+				return fmt.Errorf("RRULE %s value out of range: %d", rng.name, v)
+			}
+		}
+	}
+	for _, day := range r.ByDay {
+		if day.Day < 0 || day.Day > 6 || day.N < -53 || day.N > 53 {
+			return fmt.Errorf("RRULE BYDAY value out of range: %d%s", day.N, rruleDayNames[(day.Day%7+7)%7])
+		}
+		if day.N != 0 && (r.Freq < TIMELIB_FREQ_MONTHLY || (r.Freq == TIMELIB_FREQ_YEARLY && len(r.ByWeekNo) > 0)) {
+			return fmt.Errorf("RRULE BYDAY with a number requires FREQ=MONTHLY or FREQ=YEARLY without BYWEEKNO")
+		}
+	}
+
+	if len(r.ByWeekNo) > 0 && r.Freq != TIMELIB_FREQ_YEARLY {
+		return fmt.Errorf("RRULE BYWEEKNO requires FREQ=YEARLY")
+	}
+	if len(r.ByYearDay) > 0 && (r.Freq == TIMELIB_FREQ_DAILY || r.Freq == TIMELIB_FREQ_WEEKLY || r.Freq == TIMELIB_FREQ_MONTHLY) {
+		return fmt.Errorf("RRULE BYYEARDAY cannot be used with FREQ=%s", rruleFreqNames[r.Freq])
+	}
+	if len(r.ByMonthDay) > 0 && r.Freq == TIMELIB_FREQ_WEEKLY {
+		return fmt.Errorf("RRULE BYMONTHDAY cannot be used with FREQ=WEEKLY")
+	}
+	if len(r.BySetPos) > 0 && len(r.BySecond)+len(r.ByMinute)+len(r.ByHour)+len(r.ByDay)+
+		len(r.ByMonthDay)+len(r.ByYearDay)+len(r.ByWeekNo)+len(r.ByMonth) == 0 {
+		return fmt.Errorf("RRULE BYSETPOS requires another BYxxx rule part")
+	}
+	return nil
+}
+
+// String returns the text form of the rule, without the "RRULE:" prefix.
+// UNTIL is written in UTC unless it is a date or a floating time.
+func (r *RRule) String() string {
+	var parts []string
+	if r.Freq >= TIMELIB_FREQ_SECONDLY && r.Freq <= TIMELIB_FREQ_YEARLY {
+		parts = append(parts, "FREQ="+rruleFreqNames[r.Freq])
+	}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.FormatInt(r.Interval, 10))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.FormatInt(r.Count, 10))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+formatICalDateTime(r.Until, true))
+	}
+
+	lists := []struct {
+		name   string
+		values []int64
+	}{
+		{"BYSECOND", r.BySecond},
+		{"BYMINUTE", r.ByMinute},
+		{"BYHOUR", r.ByHour},
+	}
+	for _, list := range lists {
+		if len(list.values) > 0 {
+			parts = append(parts, list.name+"="+formatRRuleNumbers(list.values))
+		}
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = rruleDayNames[(day.Day%7+7)%7]
+			if day.N != 0 {
+				days[i] = strconv.FormatInt(day.N, 10) + days[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	lists = []struct {
+		name   string
+		values []int64
+	}{
+		{"BYMONTHDAY", r.ByMonthDay},
+		{"BYYEARDAY", r.ByYearDay},
+		{"BYWEEKNO", r.ByWeekNo},
+		{"BYMONTH", r.ByMonth},
+		{"BYSETPOS", r.BySetPos},
+	}
+	for _, list := range lists {
+		if len(list.values) > 0 {
+			parts = append(parts, list.name+"="+formatRRuleNumbers(list.values))
+		}
+	}
+	if r.Wkst != 1 && r.Wkst >= 0 && r.Wkst <= 6 {
+		parts = append(parts, "WKST="+rruleDayNames[r.Wkst])
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// formatRRuleNumbers formats a comma-separated list of integers
+func formatRRuleNumbers(values []int64) string {
+	items := make([]string, len(values))
+	for i, v := range values {
+		items[i] = strconv.FormatInt(v, 10)
+	}
+	return strings.Join(items, ",")
+}
+
+// RRuleIterator returns the occurrences of a rule one by one, see
+// RRule.Iterator
+type RRuleIterator struct {
+	rule      *RRule
+	start     *Time
+	startWall int64 // local wall clock time of the start, as seconds since 1970-01-01 00:00
+	interval  int64
+	hasUntil  bool
+	untilSse  int64
+
+	period     int64   // number of the next period to expand
+	pending    []*Time // occurrences of the expanded period not yet returned
+	count      int64   // occurrences returned so far
+	started    bool
+	matchStart bool // return the start date only if the rule generates it
+	finished   bool
+}
+
+// Iterator returns an iterator over the occurrences of the rule from the
+// start date (DTSTART), which is always the first occurrence as RFC 5545
+// requires. The start date's zone determines the local time in which the
+// rule is expanded.
+func (r *RRule) Iterator(start *Time) (*RRuleIterator, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	if start == nil {
+		return nil, fmt.Errorf("recurrence start date is nil")
+	}
+
+	begin := start.Clone()
+	if !begin.SseUptodate {
+		begin.UpdateTS(begin.TzInfo)
+	}
+	begin.Unixtime2local(begin.Sse)
+
+	it := &RRuleIterator{
+		rule:      r,
+		start:     begin,
+		startWall: rruleWall(begin.Y, begin.M, begin.D, begin.H, begin.I, begin.S),
+		interval:  r.Interval,
+	}
+	if it.interval < 1 {
+		it.interval = 1
+	}
+
+	if r.Until != nil {
+		it.hasUntil = true
+		if r.Until.ZoneType == TIMELIB_ZONETYPE_NONE {
+			// Floating times are in the zone of the start date, and a date
+			// includes the whole day
+			u := r.Until
+			if u.HaveTime {
+				it.untilSse = it.instance(rruleWall(u.Y, u.M, u.D, u.H, u.I, u.S)).Sse
+			} else {
+				it.untilSse = it.instance(rruleWall(u.Y, u.M, u.D, 23, 59, 59)).Sse
+			}
+		} else {
+			until := r.Until.Clone()
+			if !until.SseUptodate {
+				until.UpdateTS(until.TzInfo)
+			}
+			it.untilSse = until.Sse
+		}
+	}
+
+	return it, nil
+}
+
+// Next returns the next occurrence, or false when there are no more
+func (it *RRuleIterator) Next() (*Time, bool) {
+	for !it.finished {
+		if it.rule.Count > 0 && it.count >= it.rule.Count {
+			it.finished = true
+			break
+		}
+
+		var next *Time
+		if !it.started && !it.matchStart {
+			it.started = true
+			next = it.start
+		} else {
+			if len(it.pending) == 0 {
+				if !it.expand() {
+					it.finished = true
+				}
+				continue
+			}
+			next = it.pending[0]
+			it.pending = it.pending[1:]
+
+			// The start date was already returned
+			if next.Sse < it.start.Sse || (next.Sse == it.start.Sse && !it.matchStart) {
+				continue
+			}
+		}
+
+		if it.hasUntil && next.Sse > it.untilSse {
+			it.finished = true
+			break
+		}
+		it.count++
+		return next.Clone(), true
+	}
+	return nil, false
+}
+
+// expand computes the occurrences of the next period into it.pending. It
+// returns false once the periods are past rruleMaxYear.
+func (it *RRuleIterator) expand() bool {
+	r := it.rule
+	start := it.start
+	k := it.period
+	it.period++
+
+	var days []int64
+	var times []int64
+
+	switch r.Freq {
+	case TIMELIB_FREQ_YEARLY:
+		y := start.Y + k*it.interval
+		if y > rruleMaxYear {
+			return false
+		}
+		first := rruleDays(y, 1, 1)
+		days = it.filterDays(first, rruleDays(y+1, 1, 1))
+
+	case TIMELIB_FREQ_MONTHLY:
+		month := start.Y*12 + start.M - 1 + k*it.interval
+		y, m := floorDiv(month, 12), month-floorDiv(month, 12)*12+1
+		if y > rruleMaxYear {
+			return false
+		}
+		first := rruleDays(y, m, 1)
+		days = it.filterDays(first, first+DaysInMonth(y, m))
+
+	case TIMELIB_FREQ_WEEKLY:
+		startDay := rruleDays(start.Y, start.M, start.D)
+		first := startDay - (DayOfWeek(start.Y, start.M, start.D)-r.Wkst+7)%7 + k*7*it.interval
+		if rruleYear(first) > rruleMaxYear {
+			return false
+		}
+		days = it.filterDays(first, first+7)
+
+	case TIMELIB_FREQ_DAILY:
+		day := rruleDays(start.Y, start.M, start.D) + k*it.interval
+		if rruleYear(day) > rruleMaxYear {
+			return false
+		}
+		days = it.filterDays(day, day+1)
+
+	default:
+		unit := int64(1)
+		switch r.Freq {
+		case TIMELIB_FREQ_HOURLY:
+			unit = 3600
+		case TIMELIB_FREQ_MINUTELY:
+			unit = 60
+		}
+		step := unit * it.interval
+
+		wall := it.startWall + k*step
+		day := floorDiv(wall, SECS_PER_DAY)
+		if rruleYear(day) > rruleMaxYear {
+			return false
+		}
+		tod := wall - day*SECS_PER_DAY
+		h, i, s := tod/3600, tod/60%60, tod%60
+
+		// Skip ahead to the next day, hour or minute that can match
+		skipTo := int64(-1)
+		switch {
+		case len(it.filterDays(day, day+1)) == 0:
+			skipTo = (day + 1) * SECS_PER_DAY
+		case len(r.ByHour) > 0 && !containsInt64(r.ByHour, h):
+			skipTo = wall - tod%3600 + 3600
+		case len(r.ByMinute) > 0 && !containsInt64(r.ByMinute, i):
+			skipTo = wall - s + 60
+		case r.Freq == TIMELIB_FREQ_SECONDLY && len(r.BySecond) > 0 && !containsInt64(r.BySecond, s):
+			return true
+		}
+		if skipTo >= 0 {
+			if next := ceilDiv(skipTo-it.startWall, step); next > it.period {
+				it.period = next
+			}
+			return true
+		}
+
+		days = []int64{day}
+		switch r.Freq {
+		case TIMELIB_FREQ_HOURLY:
+			times = rruleTimes([]int64{h}, orDefault(r.ByMinute, start.I), orDefault(r.BySecond, start.S))
+		case TIMELIB_FREQ_MINUTELY:
+			times = rruleTimes([]int64{h}, []int64{i}, orDefault(r.BySecond, start.S))
+		default:
+			times = []int64{tod}
+		}
+	}
+
+	if times == nil {
+		times = rruleTimes(orDefault(r.ByHour, start.H), orDefault(r.ByMinute, start.I), orDefault(r.BySecond, start.S))
+	}
+
+	walls := make([]int64, 0, len(days)*len(times))
+	for _, day := range days {
+		for _, tod := range times {
+			walls = append(walls, day*SECS_PER_DAY+tod)
+		}
+	}
+
+	if len(r.BySetPos) > 0 {
+		var selected []int64
+		for _, pos := range r.BySetPos {
+			idx := pos - 1
+			if pos < 0 {
+				idx = int64(len(walls)) + pos
+			}
+			if idx >= 0 && idx < int64(len(walls)) && !containsInt64(selected, walls[idx]) {
+				selected = append(selected, walls[idx])
+			}
+		}
+		sort.Slice(selected, func(a, b int) bool { return selected[a] < selected[b] })
+		walls = selected
+	}
+
+	for _, wall := range walls {
+		it.pending = append(it.pending, it.instance(wall))
+	}
+	return true
+}
+
+// filterDays returns the days in [first, end) that match the day-level rule
+// parts, with the defaults that RFC 5545 derives from the start date
+func (it *RRuleIterator) filterDays(first, end int64) []int64 {
+	r := it.rule
+	start := it.start
+
+	byMonth := r.ByMonth
+	byMonthDay := r.ByMonthDay
+	byDay := r.ByDay
+	if len(r.ByWeekNo) == 0 && len(r.ByYearDay) == 0 && len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+		switch r.Freq {
+		case TIMELIB_FREQ_YEARLY:
+			if len(byMonth) == 0 {
+				byMonth = []int64{start.M}
+			}
+			byMonthDay = []int64{start.D}
+		case TIMELIB_FREQ_MONTHLY:
+			byMonthDay = []int64{start.D}
+		case TIMELIB_FREQ_WEEKLY:
+			byDay = []RRuleWeekday{{Day: DayOfWeek(start.Y, start.M, start.D)}}
+		}
+	} else if r.Freq == TIMELIB_FREQ_YEARLY && len(r.ByWeekNo) > 0 && len(r.ByDay) == 0 &&
+		len(r.ByYearDay) == 0 && len(r.ByMonthDay) == 0 {
+		byDay = []RRuleWeekday{{Day: DayOfWeek(start.Y, start.M, start.D)}}
+	}
+
+	// Occurrences of BYDAY with a number count within the month, or the
+	// year for yearly rules without BYMONTH
+	nthInMonth := r.Freq == TIMELIB_FREQ_MONTHLY || len(r.ByMonth) > 0
+
+	var days []int64
+	for day := first; day < end; day++ {
+		var y, m, d int64
+		Unixtime2date(day*SECS_PER_DAY, &y, &m, &d)
+
+		if len(byMonth) > 0 && !containsInt64(byMonth, m) {
+			continue
+		}
+
+		if len(r.ByWeekNo) > 0 {
+			weekYear, week := rruleWeekNo(y, m, d, r.Wkst)
+			weeks := rruleWeeksInYear(weekYear, r.Wkst)
+			if !containsInt64(r.ByWeekNo, week) && !containsInt64(r.ByWeekNo, week-weeks-1) {
+				continue
+			}
+		}
+
+		doy := day - rruleDays(y, 1, 1) + 1
+		daysInYear := rruleDays(y+1, 1, 1) - rruleDays(y, 1, 1)
+		if len(r.ByYearDay) > 0 && !containsInt64(r.ByYearDay, doy) && !containsInt64(r.ByYearDay, doy-daysInYear-1) {
+			continue
+		}
+
+		dim := DaysInMonth(y, m)
+		if len(byMonthDay) > 0 && !containsInt64(byMonthDay, d) && !containsInt64(byMonthDay, d-dim-1) {
+			continue
+		}
+
+		if len(byDay) > 0 {
+			weekday := DayOfWeek(y, m, d)
+			nth, nthLast := (doy-1)/7+1, -((daysInYear-doy)/7 + 1)
+			if nthInMonth {
+				nth, nthLast = (d-1)/7+1, -((dim-d)/7 + 1)
+			}
+			matched := false
+			for _, bd := range byDay {
+				if bd.Day == weekday && (bd.N == 0 || bd.N == nth || bd.N == nthLast) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				continue
+			}
+		}
+
+		days = append(days, day)
+	}
+	return days
+}
+
+// instance converts a local wall clock time into an occurrence in the zone
+// of the start date
+func (it *RRuleIterator) instance(wall int64) *Time {
+	day := floorDiv(wall, SECS_PER_DAY)
+	tod := wall - day*SECS_PER_DAY
+
+	t := it.start.Clone()
+	Unixtime2date(day*SECS_PER_DAY, &t.Y, &t.M, &t.D)
+	t.H, t.I, t.S = tod/3600, tod/60%60, tod%60
+	t.Relative = RelTime{}
+	t.HaveRelative = false
+	t.SseUptodate = false
+	t.UpdateTS(t.TzInfo)
+	t.Unixtime2local(t.Sse)
+	return t
+}
+
+// rruleTimes returns the sorted seconds of the day for all combinations of
+// hours, minutes and seconds
+func rruleTimes(hours, minutes, seconds []int64) []int64 {
+	var times []int64
+	for _, h := range hours {
+		for _, i := range minutes {
+			for _, s := range seconds {
+				times = append(times, h*3600+i*60+s)
+			}
+		}
+	}
+	sort.Slice(times, func(a, b int) bool { return times[a] < times[b] })
+
+	unique := times[:0]
+	for i, t := range times {
+		if i == 0 || t != times[i-1] {
+			unique = append(unique, t)
+		}
+	}
+	return unique
+}
+
+// rruleWall returns a local date and time as seconds since 1970-01-01 00:00
+func rruleWall(y, m, d, h, i, s int64) int64 {
+	return rruleDays(y, m, d)*SECS_PER_DAY + h*3600 + i*60 + s
+}
+
+// rruleDays returns the number of days since 1970-01-01 of a date
+func rruleDays(y, m, d int64) int64 {
+	return timelib_epoch_days_from_time(&Time{Y: y, M: m, D: d})
+}
+
+// rruleYear returns the year of a day number
+func rruleYear(day int64) int64 {
+	var y, m, d int64
+	Unixtime2date(day*SECS_PER_DAY, &y, &m, &d)
+	return y
+}
+
+// rruleWeekNo returns the week-numbering year and the week number of a date
+// with weeks starting on wkst, as used by BYWEEKNO. Weeks starting on Monday
+// are ISO weeks.
+func rruleWeekNo(y, m, d, wkst int64) (int64, int64) {
+	if wkst == 1 {
+		week, year := IsoWeekFromDate(y, m, d)
+		return year, week
+	}
+
+	// A week belongs to the year that has at least four of its days, which
+	// is the year of its fourth day
+	fourth := rruleDays(y, m, d) - (DayOfWeek(y, m, d)-wkst+7)%7 + 3
+	Unixtime2date(fourth*SECS_PER_DAY, &y, &m, &d)
+	return y, DayOfYear(y, m, d)/7 + 1
+}
+
+// rruleWeeksInYear returns the number of weeks (52 or 53) of a
+// week-numbering year, which is the week number of December 28th
+func rruleWeeksInYear(year, wkst int64) int64 {
+	_, weeks := rruleWeekNo(year, 12, 28, wkst)
+	return weeks
+}
@@ -0,0 +1,419 @@
+package timelib
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RecurrenceSet combines a start date with recurrence rules and extra and
+// excluded dates, like the DTSTART, RRULE, RDATE and EXDATE properties of an
+// iCalendar component. The occurrences are the start date, the occurrences
+// of all rules and the extra dates, in order and without duplicates, minus
+// the excluded dates.
+//
+// Extra and excluded dates without a zone are taken in the zone of the start
+// date, and dates without a time at the start date's time of day, so that
+// "EXDATE;VALUE=DATE:19970904" removes the occurrence on that day.
+type RecurrenceSet struct {
+	Start   *Time
+	RRules  []*RRule
+	RDates  []*Time
+	ExDates []*Time
+
+	started bool
+	iters   []*RRuleIterator
+	heads   []*Time // next occurrence of each rule, nil once exhausted
+	rdates  []*Time // resolved extra dates, sorted
+	exdates map[int64]bool
+	last    int64 // Sse of the previous occurrence
+	first   bool  // whether an occurrence was returned yet
+}
+
+// NewRecurrenceSet creates a set of occurrences from the start date and rules
+func NewRecurrenceSet(start *Time, rules ...*RRule) *RecurrenceSet {
+	return &RecurrenceSet{Start: start, RRules: rules}
+}
+
+// ParseRecurrenceSet parses the DTSTART, RRULE, RDATE and EXDATE lines of an
+// iCalendar component, e.g.
+//
+//	DTSTART;TZID=America/New_York:19970902T090000
+//	RRULE:FREQ=WEEKLY;COUNT=10;BYDAY=TU,TH
+//	EXDATE;TZID=America/New_York:19970904T090000
+//
+// TZID parameters are looked up in tzdb, or the builtin database if it is
+// nil. Folded lines are joined, and lines for other properties are ignored.
+func ParseRecurrenceSet(s string, tzdb *TzDB) (*RecurrenceSet, error) {
+	if tzdb == nil {
+		tzdb = BuiltinDB()
+	}
+
+	// Unfold lines continued with a leading space or tab
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	set := &RecurrenceSet{}
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		head, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid iCalendar line '%s'", line)
+		}
+		params := strings.Split(head, ";")
+		name := strings.ToUpper(params[0])
+		if name != "DTSTART" && name != "RRULE" && name != "RDATE" && name != "EXDATE" {
+			continue
+		}
+
+		if name == "RRULE" {
+			rule, err := ParseRRule(value)
+			if err != nil {
+				return nil, err
+			}
+			set.RRules = append(set.RRules, rule)
+			continue
+		}
+
+		var tz *TzInfo
+		for _, param := range params[1:] {
+			key, v, _ := strings.Cut(param, "=")
+			switch strings.ToUpper(key) {
+			case "TZID":
+				var err error
+				if tz, err = ParseTzfile(strings.Trim(v, "\""), tzdb, nil); err != nil {
+					return nil, fmt.Errorf("unknown TZID '%s' in %s", v, name)
+				}
+			case "VALUE":
+				if !strings.EqualFold(v, "DATE") && !strings.EqualFold(v, "DATE-TIME") {
+					return nil, fmt.Errorf("unsupported %s value type '%s'", name, v)
+				}
+			}
+		}
+
+		for _, item := range strings.Split(value, ",") {
+			t, err := parseICalDateTime(item, tz)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s '%s': %v", name, item, err)
+			}
+			switch name {
+			case "DTSTART":
+				if set.Start != nil {
+					return nil, fmt.Errorf("DTSTART appears more than once")
+				}
+				set.Start = t
+			case "RDATE":
+				set.RDates = append(set.RDates, t)
+			case "EXDATE":
+				set.ExDates = append(set.ExDates, t)
+			}
+		}
+	}
+
+	if set.Start == nil {
+		return nil, fmt.Errorf("recurrence set has no DTSTART")
+	}
+	return set, nil
+}
+
+// String returns the set as iCalendar lines separated by "\n", with one
+// line per extra or excluded date
+func (set *RecurrenceSet) String() string {
+	var lines []string
+	if set.Start != nil {
+		lines = append(lines, "DTSTART"+formatICalProperty(set.Start))
+	}
+	for _, rule := range set.RRules {
+		lines = append(lines, "RRULE:"+rule.String())
+	}
+	for _, t := range set.RDates {
+		lines = append(lines, "RDATE"+formatICalProperty(t))
+	}
+	for _, t := range set.ExDates {
+		lines = append(lines, "EXDATE"+formatICalProperty(t))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatICalProperty formats the parameters and value of a date property
+func formatICalProperty(t *Time) string {
+	switch {
+	case t.ZoneType == TIMELIB_ZONETYPE_NONE && !t.HaveTime:
+		return ";VALUE=DATE:" + formatICalDateTime(t, false)
+	case t.ZoneType == TIMELIB_ZONETYPE_ID && t.TzInfo != nil:
+		return ";TZID=" + t.TzInfo.Name + ":" + formatICalDateTime(t, false)
+	}
+	return ":" + formatICalDateTime(t, true)
+}
+
+// Next returns the next occurrence of the set, or false when there are no
+// more. The returned Time is a new value that the caller may modify.
+func (set *RecurrenceSet) Next() (*Time, bool) {
+	if !set.started {
+		if err := set.init(); err != nil {
+			return nil, false
+		}
+	}
+
+	for {
+		// Pick the earliest of the rule occurrences and extra dates
+		var next *Time
+		source := -1
+		for i, head := range set.heads {
+			if head != nil && (next == nil || head.Sse < next.Sse) {
+				next, source = head, i
+			}
+		}
+		if len(set.rdates) > 0 && (next == nil || set.rdates[0].Sse < next.Sse) {
+			next, source = set.rdates[0], len(set.heads)
+		}
+		if next == nil {
+			return nil, false
+		}
+
+		if source < len(set.heads) {
+			set.heads[source], _ = set.iters[source].Next()
+		} else {
+			set.rdates = set.rdates[1:]
+		}
+
+		if (set.first && next.Sse <= set.last) || set.exdates[next.Sse] {
+			continue
+		}
+		set.first = true
+		set.last = next.Sse
+		return next.Clone(), true
+	}
+}
+
+// init prepares the iteration of the set
+func (set *RecurrenceSet) init() error {
+	if set.Start == nil {
+		return fmt.Errorf("recurrence start date is nil")
+	}
+
+	start := set.Start.Clone()
+	if !start.SseUptodate {
+		start.UpdateTS(start.TzInfo)
+	}
+	start.Unixtime2local(start.Sse)
+
+	set.iters = nil
+	set.heads = nil
+	for _, rule := range set.RRules {
+		it, err := rule.Iterator(start)
+		if err != nil {
+			return err
+		}
+		head, _ := it.Next()
+		set.iters = append(set.iters, it)
+		set.heads = append(set.heads, head)
+	}
+
+	// The start date is always the first occurrence
+	set.rdates = []*Time{start}
+	for _, t := range set.RDates {
+		set.rdates = append(set.rdates, resolveRecurrenceDate(t, start))
+	}
+	sort.SliceStable(set.rdates, func(a, b int) bool { return set.rdates[a].Sse < set.rdates[b].Sse })
+
+	set.exdates = make(map[int64]bool)
+	for _, t := range set.ExDates {
+		set.exdates[resolveRecurrenceDate(t, start).Sse] = true
+	}
+
+	set.started = true
+	set.first = false
+	return nil
+}
+
+// resolveRecurrenceDate converts an extra or excluded date into the zone of
+// the start date, taking floating dates and times in that zone
+func resolveRecurrenceDate(t, start *Time) *Time {
+	r := start.Clone()
+	if t.ZoneType == TIMELIB_ZONETYPE_NONE {
+		r.Y, r.M, r.D = t.Y, t.M, t.D
+		if t.HaveTime {
+			r.H, r.I, r.S = t.H, t.I, t.S
+		}
+		r.Relative = RelTime{}
+		r.HaveRelative = false
+		r.SseUptodate = false
+		r.UpdateTS(r.TzInfo)
+		r.Unixtime2local(r.Sse)
+		return r
+	}
+
+	other := t.Clone()
+	if !other.SseUptodate {
+		other.UpdateTS(other.TzInfo)
+	}
+	r.Unixtime2local(other.Sse)
+	return r
+}
+
+// Reset restarts the iteration at the first occurrence
+func (set *RecurrenceSet) Reset() {
+	set.started = false
+	set.iters = nil
+	set.heads = nil
+	set.rdates = nil
+	set.exdates = nil
+}
+
+// Dates returns up to max occurrences of the set from its start, or all of
+// them if max is 0 or less. Without a limit, every rule must have a COUNT or
+// UNTIL, or expansion only stops at the year 9999.
+func (set *RecurrenceSet) Dates(max int) []*Time {
+	set.Reset()
+	defer set.Reset()
+
+	var dates []*Time
+	for max <= 0 || len(dates) < max {
+		date, ok := set.Next()
+		if !ok {
+			break
+		}
+		dates = append(dates, date)
+	}
+	return dates
+}
+
+// Between returns the occurrences from after up to before, both inclusive
+func (set *RecurrenceSet) Between(after, before *Time) []*Time {
+	set.Reset()
+	defer set.Reset()
+
+	from, to := after.Clone(), before.Clone()
+	if !from.SseUptodate {
+		from.UpdateTS(from.TzInfo)
+	}
+	if !to.SseUptodate {
+		to.UpdateTS(to.TzInfo)
+	}
+
+	var dates []*Time
+	for {
+		date, ok := set.Next()
+		if !ok || date.Sse > to.Sse {
+			break
+		}
+		if date.Sse >= from.Sse {
+			dates = append(dates, date)
+		}
+	}
+	return dates
+}
+
+// parseICalDateTime parses an iCalendar DATE ("19970902") or DATE-TIME
+// ("19970902T090000", or "19970902T130000Z" in UTC). Dates and times without
+// "Z" are in tz, or floating (TIMELIB_ZONETYPE_NONE) if tz is nil.
+func parseICalDateTime(value string, tz *TzInfo) (*Time, error) {
+	value = strings.TrimSpace(value)
+	utc := strings.HasSuffix(value, "Z") || strings.HasSuffix(value, "z")
+	if utc {
+		value = value[:len(value)-1]
+	}
+	if len(value) != 8 && (len(value) != 15 || (value[8] != 'T' && value[8] != 't')) {
+		return nil, fmt.Errorf("expected YYYYMMDD or YYYYMMDDTHHMMSS")
+	}
+	if utc && len(value) == 8 {
+		return nil, fmt.Errorf("a date cannot be in UTC")
+	}
+
+	digits := func(s string) (int64, error) {
+		for _, c := range s {
+			if c < '0' || c > '9' {
+				return 0, fmt.Errorf("expected digits, got '%s'", s)
+			}
+		}
+		return strconv.ParseInt(s, 10, 64)
+	}
+
+	t := TimeCtor()
+	fields := []struct {
+		dst      *int64
+		from, to int
+		min, max int64
+	}{
+		{&t.Y, 0, 4, 0, 9999},
+		{&t.M, 4, 6, 1, 12},
+		{&t.D, 6, 8, 1, 31},
+		{&t.H, 9, 11, 0, 23},
+		{&t.I, 11, 13, 0, 59},
+		{&t.S, 13, 15, 0, 60},
+	}
+	if len(value) == 8 {
+		fields = fields[:3]
+	}
+	for _, f := range fields {
+		var err error
+		if *f.dst, err = digits(value[f.from:f.to]); err != nil {
+			return nil, err
+		}
+		if *f.dst < f.min || *f.dst > f.max {
+			return nil, fmt.Errorf("'%s' out of range", value[f.from:f.to])
+		}
+	}
+	if t.D > DaysInMonth(t.Y, t.M) {
+		return nil, fmt.Errorf("day out of range for %04d-%02d", t.Y, t.M)
+	}
+
+	t.HaveDate = true
+	t.HaveTime = len(value) == 15
+	if !t.HaveTime {
+		t.H, t.I, t.S = 0, 0, 0
+	}
+
+	switch {
+	case utc:
+		t.ZoneType = TIMELIB_ZONETYPE_OFFSET
+		t.HaveZone = true
+		t.TzAbbr = "UTC"
+	case tz != nil:
+		t.ZoneType = TIMELIB_ZONETYPE_ID
+		t.HaveZone = true
+		t.TzInfo = tz
+	}
+	t.UpdateTS(t.TzInfo)
+	if t.ZoneType == TIMELIB_ZONETYPE_ID {
+		t.Unixtime2local(t.Sse)
+	}
+	return t, nil
+}
+
+// formatICalDateTime formats a time as an iCalendar DATE or DATE-TIME.
+// Floating times and dates are written as they are; other times are written
+// in UTC with "Z" if utc is set, or as their local time otherwise.
+func formatICalDateTime(t *Time, utc bool) string {
+	if t.ZoneType == TIMELIB_ZONETYPE_NONE {
+		if !t.HaveTime {
+			return fmt.Sprintf("%04d%02d%02d", t.Y, t.M, t.D)
+		}
+		return fmt.Sprintf("%04d%02d%02dT%02d%02d%02d", t.Y, t.M, t.D, t.H, t.I, t.S)
+	}
+
+	if utc {
+		sse := t.Sse
+		if !t.SseUptodate {
+			c := t.Clone()
+			c.UpdateTS(c.TzInfo)
+			sse = c.Sse
+		}
+		g := TimeCtor()
+		g.Unixtime2gmt(sse)
+		return fmt.Sprintf("%04d%02d%02dT%02d%02d%02dZ", g.Y, g.M, g.D, g.H, g.I, g.S)
+	}
+	return fmt.Sprintf("%04d%02d%02dT%02d%02d%02d", t.Y, t.M, t.D, t.H, t.I, t.S)
+}
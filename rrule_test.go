@@ -0,0 +1,226 @@
+package timelib
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// recurrenceDates formats the first max occurrences of a set for comparison
+func recurrenceDates(set *RecurrenceSet, max int) string {
+	var dates []string
+	for _, d := range set.Dates(max) {
+		dates = append(dates, fmt.Sprintf("%04d-%02d-%02d %02d:%02d", d.Y, d.M, d.D, d.H, d.I))
+	}
+	return strings.Join(dates, ", ")
+}
+
+// TestRRuleExamples tests the examples of RFC 5545 section 3.8.5.3
+func TestRRuleExamples(t *testing.T) {
+	tests := []struct {
+		name    string
+		dtstart string
+		rule    string
+		max     int
+		want    string
+	}{
+		{"daily for 10 occurrences", "19970902T090000", "FREQ=DAILY;COUNT=10", 0,
+			"1997-09-02 09:00, 1997-09-03 09:00, 1997-09-04 09:00, 1997-09-05 09:00, 1997-09-06 09:00, " +
+				"1997-09-07 09:00, 1997-09-08 09:00, 1997-09-09 09:00, 1997-09-10 09:00, 1997-09-11 09:00"},
+		{"every 10 days, 5 occurrences", "19970902T090000", "FREQ=DAILY;INTERVAL=10;COUNT=5", 0,
+			"1997-09-02 09:00, 1997-09-12 09:00, 1997-09-22 09:00, 1997-10-02 09:00, 1997-10-12 09:00"},
+		{"weekly on Tuesday and Thursday for five weeks", "19970902T090000", "FREQ=WEEKLY;UNTIL=19971007T000000Z;WKST=SU;BYDAY=TU,TH", 0,
+			"1997-09-02 09:00, 1997-09-04 09:00, 1997-09-09 09:00, 1997-09-11 09:00, 1997-09-16 09:00, " +
+				"1997-09-18 09:00, 1997-09-23 09:00, 1997-09-25 09:00, 1997-09-30 09:00, 1997-10-02 09:00"},
+		{"monthly on the first Friday", "19970905T090000", "FREQ=MONTHLY;COUNT=10;BYDAY=1FR", 0,
+			"1997-09-05 09:00, 1997-10-03 09:00, 1997-11-07 09:00, 1997-12-05 09:00, 1998-01-02 09:00, " +
+				"1998-02-06 09:00, 1998-03-06 09:00, 1998-04-03 09:00, 1998-05-01 09:00, 1998-06-05 09:00"},
+		{"monthly on the second-to-last Monday", "19970922T090000", "FREQ=MONTHLY;COUNT=6;BYDAY=-2MO", 0,
+			"1997-09-22 09:00, 1997-10-20 09:00, 1997-11-17 09:00, 1997-12-22 09:00, 1998-01-19 09:00, 1998-02-16 09:00"},
+		{"every third year on days 1, 100 and 200", "19970101T090000", "FREQ=YEARLY;INTERVAL=3;COUNT=10;BYYEARDAY=1,100,200", 0,
+			"1997-01-01 09:00, 1997-04-10 09:00, 1997-07-19 09:00, 2000-01-01 09:00, 2000-04-09 09:00, " +
+				"2000-07-18 09:00, 2003-01-01 09:00, 2003-04-10 09:00, 2003-07-19 09:00, 2006-01-01 09:00"},
+		{"every 20th Monday of the year", "19970519T090000", "FREQ=YEARLY;BYDAY=20MO", 3,
+			"1997-05-19 09:00, 1998-05-18 09:00, 1999-05-17 09:00"},
+		{"Monday of week 20", "19970512T090000", "FREQ=YEARLY;BYWEEKNO=20;BYDAY=MO", 3,
+			"1997-05-12 09:00, 1998-05-11 09:00, 1999-05-17 09:00"},
+		{"every Thursday in March", "19970313T090000", "FREQ=YEARLY;BYMONTH=3;BYDAY=TH", 7,
+			"1997-03-13 09:00, 1997-03-20 09:00, 1997-03-27 09:00, 1998-03-05 09:00, 1998-03-12 09:00, 1998-03-19 09:00, 1998-03-26 09:00"},
+		{"US presidential election day", "19961105T090000", "FREQ=YEARLY;INTERVAL=4;BYMONTH=11;BYDAY=TU;BYMONTHDAY=2,3,4,5,6,7,8", 3,
+			"1996-11-05 09:00, 2000-11-07 09:00, 2004-11-02 09:00"},
+		{"third Tuesday, Wednesday or Thursday", "19970904T090000", "FREQ=MONTHLY;COUNT=3;BYDAY=TU,WE,TH;BYSETPOS=3", 0,
+			"1997-09-04 09:00, 1997-10-07 09:00, 1997-11-06 09:00"},
+		{"last work day of the month", "19970929T090000", "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1", 7,
+			"1997-09-29 09:00, 1997-09-30 09:00, 1997-10-31 09:00, 1997-11-28 09:00, 1997-12-31 09:00, 1998-01-30 09:00, 1998-02-27 09:00"},
+		{"every 20 minutes during the day", "19970902T090000", "FREQ=DAILY;BYHOUR=9,10,11,12,13,14,15,16;BYMINUTE=0,20,40", 5,
+			"1997-09-02 09:00, 1997-09-02 09:20, 1997-09-02 09:40, 1997-09-02 10:00, 1997-09-02 10:20"},
+		{"every 20 minutes, minutely", "19970902T160000", "FREQ=MINUTELY;INTERVAL=20;BYHOUR=9,10,11,12,13,14,15,16", 5,
+			"1997-09-02 16:00, 1997-09-02 16:20, 1997-09-02 16:40, 1997-09-03 09:00, 1997-09-03 09:20"},
+		{"every 3 hours", "19970902T090000", "FREQ=HOURLY;INTERVAL=3;UNTIL=19970902T210000Z", 0,
+			"1997-09-02 09:00, 1997-09-02 12:00, 1997-09-02 15:00"},
+		{"WKST=MO", "19970805T090000", "FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU;WKST=MO", 0,
+			"1997-08-05 09:00, 1997-08-10 09:00, 1997-08-19 09:00, 1997-08-24 09:00"},
+		{"WKST=SU", "19970805T090000", "FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU;WKST=SU", 0,
+			"1997-08-05 09:00, 1997-08-17 09:00, 1997-08-19 09:00, 1997-08-31 09:00"},
+		{"invalid dates are skipped", "20070115T090000", "FREQ=MONTHLY;BYMONTHDAY=15,30;COUNT=5", 0,
+			"2007-01-15 09:00, 2007-01-30 09:00, 2007-02-15 09:00, 2007-03-15 09:00, 2007-03-30 09:00"},
+		{"last day of the month", "20240131T090000", "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=4", 0,
+			"2024-01-31 09:00, 2024-02-29 09:00, 2024-03-31 09:00, 2024-04-30 09:00"},
+		{"floating date UNTIL includes the day", "19970902T090000", "FREQ=DAILY;UNTIL=19970904", 0,
+			"1997-09-02 09:00, 1997-09-03 09:00, 1997-09-04 09:00"},
+	}
+
+	for _, test := range tests {
+		set, err := ParseRecurrenceSet("DTSTART;TZID=America/New_York:"+test.dtstart+"\nRRULE:"+test.rule, nil)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if got := recurrenceDates(set, test.max); got != test.want {
+			t.Errorf("%s:\n got %s\nwant %s", test.name, got, test.want)
+		}
+	}
+}
+
+// TestRRuleKeepsLocalTime tests that occurrences keep their wall clock time
+// across DST changes
+func TestRRuleKeepsLocalTime(t *testing.T) {
+	set, err := ParseRecurrenceSet("DTSTART;TZID=America/New_York:19971020T090000\nRRULE:FREQ=WEEKLY;COUNT=3", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, d := range set.Dates(0) {
+		got = append(got, fmt.Sprintf("%02d %02d:%02d %s %d", d.D, d.H, d.I, d.TzAbbr, d.Sse))
+	}
+	want := "20 09:00 EDT 877352400, 27 09:00 EST 877960800, 03 09:00 EST 878565600"
+	if strings.Join(got, ", ") != want {
+		t.Errorf("got %s, want %s", strings.Join(got, ", "), want)
+	}
+
+	// A local time in the gap at the start of DST is moved forward
+	set, err = ParseRecurrenceSet("DTSTART;TZID=America/New_York:20240309T023000\nRRULE:FREQ=DAILY;COUNT=3", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := recurrenceDates(set, 0); got != "2024-03-09 02:30, 2024-03-10 03:30, 2024-03-11 02:30" {
+		t.Errorf("gap: got %s", got)
+	}
+}
+
+// TestRecurrenceSetDates tests RDATE and EXDATE
+func TestRecurrenceSetDates(t *testing.T) {
+	// Friday the 13th, without the start date
+	set, err := ParseRecurrenceSet(`DTSTART;TZID=America/New_York:19970902T090000
+EXDATE;TZID=America/New_York:19970902T090000
+RRULE:FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := recurrenceDates(set, 5); got != "1998-02-13 09:00, 1998-03-13 09:00, 1998-11-13 09:00, 1999-08-13 09:00, 2000-10-13 09:00" {
+		t.Errorf("Friday the 13th: got %s", got)
+	}
+
+	set, err = ParseRecurrenceSet("DTSTART;TZID=Europe/Amsterdam:20240101T100000\r\n"+
+		"RRULE:FREQ=WEEKLY;\r\n COUNT=4\r\n"+
+		"RDATE;TZID=Europe/Amsterdam:20240103T120000,20240101T100000\r\n"+
+		"RDATE:20240110T090000Z\r\n"+
+		"EXDATE;VALUE=DATE:20240115\r\n", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "2024-01-01 10:00, 2024-01-03 12:00, 2024-01-08 10:00, 2024-01-10 10:00, 2024-01-22 10:00"
+	if got := recurrenceDates(set, 0); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	start, _ := parseICalDateTime("20240101T100000", set.Start.TzInfo)
+	end, _ := parseICalDateTime("20240110T090000Z", nil)
+	if got := len(set.Between(start, end)); got != 4 {
+		t.Errorf("Between returned %d dates, want 4", got)
+	}
+
+	// Without rules, the set is the start date and the extra dates
+	set, err = ParseRecurrenceSet("DTSTART;VALUE=DATE:20240101\nRDATE;VALUE=DATE:20240301,20240201", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := recurrenceDates(set, 0); got != "2024-01-01 00:00, 2024-02-01 00:00, 2024-03-01 00:00" {
+		t.Errorf("dates: got %s", got)
+	}
+}
+
+// TestParseRRule tests parsing, validating and serializing rules
+func TestParseRRule(t *testing.T) {
+	for _, spec := range []string{
+		"FREQ=DAILY",
+		"FREQ=WEEKLY;INTERVAL=2;COUNT=8;BYDAY=TU,TH;WKST=SU",
+		"FREQ=MONTHLY;UNTIL=19971224T000000Z;BYDAY=1FR",
+		"FREQ=MONTHLY;UNTIL=19971224;BYDAY=-2MO",
+		"FREQ=YEARLY;BYDAY=SU;BYWEEKNO=1,-1;BYMONTH=1,12",
+		"FREQ=YEARLY;BYYEARDAY=1,100,-1;BYSETPOS=1,-1",
+		"FREQ=MINUTELY;INTERVAL=20;BYSECOND=0;BYMINUTE=0,20,40;BYHOUR=9,10",
+	} {
+		r, err := ParseRRule("RRULE:" + spec)
+		if err != nil {
+			t.Errorf("%s: %v", spec, err)
+			continue
+		}
+		if got := r.String(); got != spec {
+			t.Errorf("round trip of %s gave %s", spec, got)
+		}
+	}
+
+	r, err := ParseRRule("freq=weekly;byday=mo,+2fr;count=3;freq2=x")
+	if err == nil {
+		t.Errorf("expected an error for an unknown part, got %s", r)
+	}
+
+	for _, spec := range []string{
+		"",
+		"COUNT=3",
+		"FREQ=FORTNIGHTLY",
+		"FREQ=DAILY;FREQ=DAILY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=3;UNTIL=19970902",
+		"FREQ=DAILY;BYHOUR=24",
+		"FREQ=DAILY;BYMONTHDAY=0",
+		"FREQ=DAILY;BYMONTH=13",
+		"FREQ=DAILY;BYDAY=XX",
+		"FREQ=DAILY;BYDAY=1MO",
+		"FREQ=MONTHLY;BYWEEKNO=1",
+		"FREQ=MONTHLY;BYYEARDAY=1",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=YEARLY;BYWEEKNO=1;BYDAY=1MO",
+		"FREQ=DAILY;BYSETPOS=1",
+		"FREQ=DAILY;UNTIL=19970230",
+		"FREQ=DAILY;COUNT",
+	} {
+		if _, err := ParseRRule(spec); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+
+	set, err := ParseRecurrenceSet("DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=DAILY;COUNT=3\nEXDATE:19970903T130000Z", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=DAILY;COUNT=3\nEXDATE:19970903T130000Z"
+	if got := set.String(); got != want {
+		t.Errorf("set serialized as\n%s\nwant\n%s", got, want)
+	}
+	if got := recurrenceDates(set, 0); got != "1997-09-02 09:00, 1997-09-04 09:00" {
+		t.Errorf("got %s", got)
+	}
+
+	for _, spec := range []string{
+		"RRULE:FREQ=DAILY",
+		"DTSTART;TZID=Nowhere/Special:19970902T090000",
+		"DTSTART:19970902T090000\nRDATE;VALUE=PERIOD:19970902T090000Z/PT1H",
+	} {
+		if _, err := ParseRecurrenceSet(spec, nil); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+}
@@ -4,6 +4,7 @@ package timelib
 
 import (
 	"errors"
+	"io/fs"
 	"math"
 	"strings"
 	"time"
@@ -277,11 +278,19 @@ type TzDBIndexEntry struct {
 
 // TzDB represents timezone database
 type TzDB struct {
-	Version   string
-	IndexSize int
-	Index     []TzDBIndexEntry
-	Data      []byte
-	BaseDir   string // Base directory for file-based databases
+	Version      string
+	IndexSize    int
+	Index        []TzDBIndexEntry
+	Data         []byte
+	BaseDir      string              // Base directory for file-based databases
+	FS           fs.FS               // File system for file-based databases (set by ZoneinfoFS and ZoneinfoDir)
+	Locations    map[string]TLocInfo // Zone locations from zone.tab/zone1970.tab (file-based databases)
+	CountryNames map[string]string   // ISO 3166 country names from iso3166.tab (file-based databases)
+
+	acceptWindowsNames bool // Resolve Windows zone names when loading and parsing, see WithWindowsZoneNames
+
+	Layers     []*TzDB                        // Databases consulted in order, see NewLayeredTzDB
+	registered map[string]*registeredTimezone // Zones added with RegisterTimezone
 }
 
 // FormatSpecifier represents a format specifier
@@ -605,10 +614,25 @@ func SetTimezone(t *Time, tz *TzInfo) {
 	t.ZoneType = TIMELIB_ZONETYPE_ID
 }
 
-// ConvertTime converts a Time structure to Go's time.Time
+// ConvertTime converts a Time structure to Go's time.Time. A zone identifier
+// is kept as a named *time.Location (see TzInfoToLocation), so the result
+// still follows e.g. "America/Chicago" rather than a fixed offset; offsets
+// and abbreviations become fixed zones, and times without a zone are UTC.
+// A nil time gives the zero time.Time.
 func ConvertTime(t *Time) time.Time {
-	// For now, return zero time - this will be implemented later
-	return time.Time{}
+	if t == nil {
+		return time.Time{}
+	}
+	if !t.SseUptodate {
+		t = t.Clone()
+		t.UpdateTS(t.TzInfo)
+	}
+
+	us := t.US
+	if us == TIMELIB_UNSET {
+		us = 0
+	}
+	return time.Unix(t.Sse, us*1000).In(timeLocation(t))
 }
 
 // Helper functions and lookup tables for date calculations
@@ -849,8 +873,27 @@ func DateFromIsoDate(iy, iw, id int64) (y, m, d int64) {
 	return
 }
 
-// ConvertFromTime converts Go's time.Time to a Time structure
+// ConvertFromTime converts Go's time.Time to a Time structure. The location
+// is resolved with LocationToTzInfo against BuiltinDB(), so a time in
+// "America/Chicago" keeps that identifier; if that fails, the time gets the
+// fixed offset in effect at that instant.
 func ConvertFromTime(t time.Time) *Time {
-	// For now, return basic structure - this will be implemented later
-	return TimeCtor()
+	tm := TimeCtor()
+
+	if tz, err := LocationToTzInfo(t.Location(), nil); err == nil {
+		tm.TzInfo = tz
+		tm.ZoneType = TIMELIB_ZONETYPE_ID
+	} else {
+		_, offset := t.Zone()
+		tm.Z = int32(offset)
+		tm.ZoneType = TIMELIB_ZONETYPE_OFFSET
+	}
+
+	tm.Unixtime2local(t.Unix())
+	tm.US = int64(t.Nanosecond() / 1000)
+	tm.HaveDate = true
+	tm.HaveTime = true
+	tm.SseUptodate = true
+	tm.TimUptodate = true
+	return tm
 }
@@ -0,0 +1,281 @@
+package timelib
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// TimeRange is the half-open range of instants [Start, End). The ends keep
+// their zones, but ranges are compared by instant (Sse and US), so ranges in
+// different zones can be combined.
+//
+// The ends must not be modified once the range is created, as sets share
+// them between ranges.
+type TimeRange struct {
+	Start *Time
+	End   *Time
+}
+
+// NewTimeRange creates the range [start, end). It copies both times and
+// computes their timestamps if needed.
+func NewTimeRange(start, end *Time) (*TimeRange, error) {
+	if start == nil || end == nil {
+		return nil, fmt.Errorf("time range start or end is nil")
+	}
+
+	s, e := start.Clone(), end.Clone()
+	if !s.SseUptodate {
+		s.UpdateTS(s.TzInfo)
+	}
+	if !e.SseUptodate {
+		e.UpdateTS(e.TzInfo)
+	}
+	if timeKey(e) < timeKey(s) {
+		return nil, fmt.Errorf("time range ends before it starts")
+	}
+	return &TimeRange{Start: s, End: e}, nil
+}
+
+// timeKey returns an instant as microseconds since the epoch
+func timeKey(t *Time) int64 {
+	us := t.US
+	if us == TIMELIB_UNSET {
+		us = 0
+	}
+	return t.Sse*1000000 + us
+}
+
+// instantKey is timeKey for a time from the caller, which computes the
+// timestamp on a copy if it is not up to date
+func instantKey(t *Time) int64 {
+	if !t.SseUptodate {
+		t = t.Clone()
+		t.UpdateTS(t.TzInfo)
+	}
+	return timeKey(t)
+}
+
+// Empty checks whether the range contains no instants
+func (r *TimeRange) Empty() bool {
+	return timeKey(r.End) <= timeKey(r.Start)
+}
+
+// Duration returns the length of the range
+func (r *TimeRange) Duration() time.Duration {
+	return time.Duration(timeKey(r.End)-timeKey(r.Start)) * time.Microsecond
+}
+
+// Contains checks whether an instant is in the range
+func (r *TimeRange) Contains(t *Time) bool {
+	k := instantKey(t)
+	return timeKey(r.Start) <= k && k < timeKey(r.End)
+}
+
+// Overlaps checks whether two ranges have an instant in common
+func (r *TimeRange) Overlaps(other *TimeRange) bool {
+	return timeKey(r.Start) < timeKey(other.End) && timeKey(other.Start) < timeKey(r.End)
+}
+
+// Intersect returns the instants two ranges have in common, or false if
+// they do not overlap
+func (r *TimeRange) Intersect(other *TimeRange) (*TimeRange, bool) {
+	if !r.Overlaps(other) {
+		return nil, false
+	}
+	start, end := r.Start, r.End
+	if timeKey(other.Start) > timeKey(start) {
+		start = other.Start
+	}
+	if timeKey(other.End) < timeKey(end) {
+		end = other.End
+	}
+	return &TimeRange{Start: start, End: end}, true
+}
+
+// TimeRangeSet is a set of instants stored as sorted ranges, which are
+// merged when they overlap or touch, so that e.g. [09:00, 12:00) and
+// [12:00, 17:00) become [09:00, 17:00). Empty ranges are dropped.
+//
+// Union, Intersect and Subtract take time linear in the number of ranges of
+// both sets, and the containment queries logarithmic time. Operations return
+// new sets and do not modify their operands.
+type TimeRangeSet struct {
+	ranges []*TimeRange
+}
+
+// NewTimeRangeSet creates a set of the instants in the given ranges
+func NewTimeRangeSet(ranges ...*TimeRange) *TimeRangeSet {
+	sorted := make([]*TimeRange, 0, len(ranges))
+	for _, r := range ranges {
+		if r != nil && !r.Empty() {
+			sorted = append(sorted, r)
+		}
+	}
+	sort.SliceStable(sorted, func(a, b int) bool { return timeKey(sorted[a].Start) < timeKey(sorted[b].Start) })
+
+	set := &TimeRangeSet{}
+	for _, r := range sorted {
+		set.appendRange(r.Start, r.End)
+	}
+	return set
+}
+
+// appendRange adds a range that starts at or after the start of the last
+// range of the set, merging it with the last range if they overlap or touch
+func (set *TimeRangeSet) appendRange(start, end *Time) {
+	if timeKey(end) <= timeKey(start) {
+		return
+	}
+	if n := len(set.ranges); n > 0 && timeKey(start) <= timeKey(set.ranges[n-1].End) {
+		if last := set.ranges[n-1]; timeKey(end) > timeKey(last.End) {
+			set.ranges[n-1] = &TimeRange{Start: last.Start, End: end}
+		}
+		return
+	}
+	set.ranges = append(set.ranges, &TimeRange{Start: start, End: end})
+}
+
+// Ranges returns the sorted, disjoint ranges of the set
+func (set *TimeRangeSet) Ranges() []*TimeRange {
+	return append([]*TimeRange(nil), set.ranges...)
+}
+
+// Len returns the number of ranges of the set
+func (set *TimeRangeSet) Len() int {
+	return len(set.ranges)
+}
+
+// Empty checks whether the set contains no instants
+func (set *TimeRangeSet) Empty() bool {
+	return len(set.ranges) == 0
+}
+
+// Duration returns the total length of the ranges of the set
+func (set *TimeRangeSet) Duration() time.Duration {
+	var total time.Duration
+	for _, r := range set.ranges {
+		total += r.Duration()
+	}
+	return total
+}
+
+// Bounds returns the range from the start of the first range of the set to
+// the end of the last one, or false if the set is empty
+func (set *TimeRangeSet) Bounds() (*TimeRange, bool) {
+	if len(set.ranges) == 0 {
+		return nil, false
+	}
+	return &TimeRange{Start: set.ranges[0].Start, End: set.ranges[len(set.ranges)-1].End}, true
+}
+
+// Add returns the set with the instants of a range added
+func (set *TimeRangeSet) Add(r *TimeRange) *TimeRangeSet {
+	return set.Union(NewTimeRangeSet(r))
+}
+
+// Union returns the instants that are in either set
+func (set *TimeRangeSet) Union(other *TimeRangeSet) *TimeRangeSet {
+	result := &TimeRangeSet{ranges: make([]*TimeRange, 0, len(set.ranges)+len(other.ranges))}
+	i, j := 0, 0
+	for i < len(set.ranges) || j < len(other.ranges) {
+		var next *TimeRange
+		if j >= len(other.ranges) || (i < len(set.ranges) && timeKey(set.ranges[i].Start) <= timeKey(other.ranges[j].Start)) {
+			next = set.ranges[i]
+			i++
+		} else {
+			next = other.ranges[j]
+			j++
+		}
+		result.appendRange(next.Start, next.End)
+	}
+	return result
+}
+
+// Intersect returns the instants that are in both sets
+func (set *TimeRangeSet) Intersect(other *TimeRangeSet) *TimeRangeSet {
+	result := &TimeRangeSet{}
+	i, j := 0, 0
+	for i < len(set.ranges) && j < len(other.ranges) {
+		a, b := set.ranges[i], other.ranges[j]
+		if common, ok := a.Intersect(b); ok {
+			result.appendRange(common.Start, common.End)
+		}
+		if timeKey(a.End) < timeKey(b.End) {
+			i++
+		} else {
+			j++
+		}
+	}
+	return result
+}
+
+// Subtract returns the instants of the set that are not in the other set
+func (set *TimeRangeSet) Subtract(other *TimeRangeSet) *TimeRangeSet {
+	result := &TimeRangeSet{}
+	j := 0
+	for _, r := range set.ranges {
+		start := r.Start
+
+		// Skip the ranges that end before this one starts
+		for j < len(other.ranges) && timeKey(other.ranges[j].End) <= timeKey(start) {
+			j++
+		}
+
+		k := j
+		for ; k < len(other.ranges) && timeKey(other.ranges[k].Start) < timeKey(r.End); k++ {
+			cut := other.ranges[k]
+			result.appendRange(start, cut.Start)
+			if timeKey(cut.End) > timeKey(start) {
+				start = cut.End
+			}
+		}
+		if timeKey(start) < timeKey(r.End) {
+			result.appendRange(start, r.End)
+		}
+
+		// The last range may extend into the next range of the set
+		if k > j {
+			j = k - 1
+		}
+	}
+	return result
+}
+
+// Gaps returns the instants within a range that are not in the set, such as
+// the free time within working hours
+func (set *TimeRangeSet) Gaps(within *TimeRange) *TimeRangeSet {
+	return NewTimeRangeSet(within).Subtract(set)
+}
+
+// find returns the index of the first range that ends after the instant
+func (set *TimeRangeSet) find(k int64) int {
+	return sort.Search(len(set.ranges), func(i int) bool { return timeKey(set.ranges[i].End) > k })
+}
+
+// Contains checks whether an instant is in the set
+func (set *TimeRangeSet) Contains(t *Time) bool {
+	k := instantKey(t)
+	i := set.find(k)
+	return i < len(set.ranges) && timeKey(set.ranges[i].Start) <= k
+}
+
+// ContainsRange checks whether all instants of a range are in the set. An
+// empty range is contained in any set.
+func (set *TimeRangeSet) ContainsRange(r *TimeRange) bool {
+	if r.Empty() {
+		return true
+	}
+	i := set.find(timeKey(r.Start))
+	return i < len(set.ranges) && timeKey(set.ranges[i].Start) <= timeKey(r.Start) &&
+		timeKey(r.End) <= timeKey(set.ranges[i].End)
+}
+
+// Overlaps checks whether any instant of a range is in the set
+func (set *TimeRangeSet) Overlaps(r *TimeRange) bool {
+	if r.Empty() {
+		return false
+	}
+	i := set.find(timeKey(r.Start))
+	return i < len(set.ranges) && timeKey(set.ranges[i].Start) < timeKey(r.End)
+}
@@ -0,0 +1,153 @@
+package timelib
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rangeSetString formats the ranges of a set as local times
+func rangeSetString(set *TimeRangeSet) string {
+	var parts []string
+	for _, r := range set.Ranges() {
+		parts = append(parts, fmt.Sprintf("%02d %02d:%02d-%02d %02d:%02d", r.Start.D, r.Start.H, r.Start.I, r.End.D, r.End.H, r.End.I))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// TestTimeRangeSet tests set operations on ranges within a week
+func TestTimeRangeSet(t *testing.T) {
+	tz, err := ParseTzfile("America/New_York", BuiltinDB(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(d, h, i int64) *Time {
+		return testTime(2024, 3, d, h, i, 0, tz)
+	}
+	span := func(d1, h1, i1, d2, h2, i2 int64) *TimeRange {
+		r, err := NewTimeRange(at(d1, h1, i1), at(d2, h2, i2))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	// Working hours from Monday to Wednesday, with the morning and
+	// afternoon given as separate, adjacent ranges
+	work := NewTimeRangeSet(
+		span(13, 13, 0, 13, 17, 0),
+		span(11, 9, 0, 11, 12, 0),
+		span(11, 12, 0, 11, 17, 0),
+		span(12, 9, 0, 12, 17, 0),
+		span(13, 9, 0, 13, 13, 0),
+	)
+	if got, want := rangeSetString(work), "11 09:00-11 17:00, 12 09:00-12 17:00, 13 09:00-13 17:00"; got != want {
+		t.Errorf("merge:\n got %s\nwant %s", got, want)
+	}
+	if work.Duration() != 24*time.Hour {
+		t.Errorf("duration: got %v", work.Duration())
+	}
+
+	busy := NewTimeRangeSet(
+		span(11, 8, 0, 11, 10, 0),
+		span(11, 11, 30, 11, 12, 30),
+		span(11, 16, 0, 12, 10, 0),
+		span(13, 0, 0, 14, 0, 0),
+	)
+
+	tests := []struct {
+		name string
+		set  *TimeRangeSet
+		want string
+	}{
+		{"union", work.Union(busy),
+			"11 08:00-12 17:00, 13 00:00-14 00:00"},
+		{"intersect", work.Intersect(busy),
+			"11 09:00-11 10:00, 11 11:30-11 12:30, 11 16:00-11 17:00, 12 09:00-12 10:00, 13 09:00-13 17:00"},
+		{"subtract", work.Subtract(busy),
+			"11 10:00-11 11:30, 11 12:30-11 16:00, 12 10:00-12 17:00"},
+		{"gaps", work.Gaps(span(11, 0, 0, 13, 0, 0)),
+			"11 00:00-11 09:00, 11 17:00-12 09:00, 12 17:00-13 00:00"},
+		{"add", work.Add(span(12, 17, 0, 12, 18, 0)),
+			"11 09:00-11 17:00, 12 09:00-12 18:00, 13 09:00-13 17:00"},
+		{"empty", work.Subtract(work), ""},
+	}
+	for _, test := range tests {
+		if got := rangeSetString(test.set); got != test.want {
+			t.Errorf("%s:\n got %s\nwant %s", test.name, got, test.want)
+		}
+	}
+
+	if !work.Contains(at(11, 9, 0)) || work.Contains(at(11, 17, 0)) || work.Contains(at(12, 8, 59)) {
+		t.Errorf("Contains: range ends are wrong")
+	}
+
+	// A time changed after its timestamp was computed is compared by its
+	// fields
+	moved := at(11, 9, 0)
+	moved.D, moved.SseUptodate = 14, false
+	if work.Contains(moved) || span(11, 9, 0, 11, 17, 0).Contains(moved) {
+		t.Errorf("Contains: used the stale timestamp of a changed time")
+	}
+	if moved.SseUptodate || moved.D != 14 {
+		t.Errorf("Contains: changed the time")
+	}
+	if !work.ContainsRange(span(11, 9, 0, 11, 17, 0)) || work.ContainsRange(span(11, 16, 0, 12, 10, 0)) {
+		t.Errorf("ContainsRange: wrong result")
+	}
+	if !work.Overlaps(span(11, 16, 59, 12, 9, 1)) || work.Overlaps(span(11, 17, 0, 12, 9, 0)) {
+		t.Errorf("Overlaps: wrong result")
+	}
+
+	// Ranges in other zones are compared by instant: 14:00 UTC is 10:00 EDT,
+	// and the ends cut by the UTC range keep its zone
+	utc := NewTimeRangeSet(&TimeRange{Start: testTime(2024, 3, 12, 14, 0, 0, nil), End: testTime(2024, 3, 12, 15, 0, 0, nil)})
+	if got, want := rangeSetString(work.Subtract(utc)), "11 09:00-11 17:00, 12 09:00-12 14:00, 12 15:00-12 17:00, 13 09:00-13 17:00"; got != want {
+		t.Errorf("zones: got %s, want %s", got, want)
+	}
+
+	// The range over the DST change is an hour shorter
+	if d := span(9, 12, 0, 10, 12, 0).Duration(); d != 23*time.Hour {
+		t.Errorf("DST duration: got %v", d)
+	}
+
+	if _, err := NewTimeRange(at(12, 0, 0), at(11, 0, 0)); err == nil {
+		t.Errorf("expected an error for a reversed range")
+	}
+}
+
+// TestTimeRangeSetLarge tests operations on sets with many ranges
+func TestTimeRangeSetLarge(t *testing.T) {
+	const n = 20000
+	instant := func(sse int64) *Time {
+		t := TimeCtor()
+		t.Unixtime2gmt(sse)
+		return t
+	}
+
+	// Even and odd hours, given in reverse order
+	var even, odd []*TimeRange
+	for i := int64(n - 1); i >= 0; i-- {
+		even = append(even, &TimeRange{Start: instant(i * 7200), End: instant(i*7200 + 3600)})
+		odd = append(odd, &TimeRange{Start: instant(i*7200 + 3600), End: instant(i*7200 + 7200)})
+	}
+	evenSet, oddSet := NewTimeRangeSet(even...), NewTimeRangeSet(odd...)
+
+	if evenSet.Len() != n || !evenSet.Intersect(oddSet).Empty() {
+		t.Fatalf("got %d ranges", evenSet.Len())
+	}
+	all := evenSet.Union(oddSet)
+	if all.Len() != 1 || all.Duration() != 2*n*time.Hour {
+		t.Errorf("union: got %d ranges of %v", all.Len(), all.Duration())
+	}
+	if got := all.Subtract(evenSet); got.Len() != n || got.Duration() != n*time.Hour {
+		t.Errorf("subtract: got %d ranges of %v", got.Len(), got.Duration())
+	}
+	if bounds, _ := all.Bounds(); evenSet.Gaps(bounds).Duration() != oddSet.Duration() {
+		t.Errorf("gaps do not match the odd hours")
+	}
+	if !evenSet.Contains(instant(2*n*3600-7200)) || evenSet.Contains(instant(2*n*3600-3600)) {
+		t.Errorf("Contains: wrong result at the last range")
+	}
+}
@@ -174,9 +174,14 @@ func GetTimeZoneInfo(ts int64, tz *TzInfo) *TimeOffset {
 		transitionTime = 0
 	}
 
+	leapSecs := 0
+	if tl := fetchLeaptimeOffset(tz, ts); tl != nil {
+		leapSecs = int(tl.Corr)
+	}
+
 	return &TimeOffset{
 		Offset:         offset,
-		LeapSecs:       0, // TODO: implement leap seconds
+		LeapSecs:       leapSecs,
 		IsDst:          int(isDst),
 		Abbr:           abbr,
 		TransitionTime: transitionTime,
@@ -0,0 +1,166 @@
+package timelib
+
+import (
+	"fmt"
+)
+
+// TzTransition describes a change of local time at a UTC instant, for
+// building zones with NewTransitionsTzInfo
+type TzTransition struct {
+	At     int64  `json:"at"`     // UTC timestamp at which the local time starts to apply
+	Offset int32  `json:"offset"` // UTC offset in seconds, east of UTC positive
+	IsDst  bool   `json:"is_dst"` // Whether the local time is daylight saving time
+	Abbr   string `json:"abbr"`   // Abbreviation, e.g. "CEST"; empty for a numeric one like "+02"
+}
+
+// NewFixedOffsetTzInfo creates a zone with a single UTC offset (in seconds,
+// east of UTC positive) that never changes. An empty abbreviation selects a
+// numeric one such as "+0530".
+func NewFixedOffsetTzInfo(name string, offset int32, abbr string) (*TzInfo, error) {
+	if abbr == "" {
+		abbr = zicOffsetAbbreviation(int64(offset))
+	}
+	if err := validateTzAbbreviation(abbr); err != nil {
+		return nil, err
+	}
+
+	posixOffset, ok := zicPosixOffset(-int64(offset))
+	if !ok {
+		return nil, fmt.Errorf("UTC offset %d out of range", offset)
+	}
+
+	tz := &TzInfo{Name: name}
+	addNewTTInfoIndex(tz, offset, 0, abbr)
+
+	return finishTzInfo(tz, posixAbbreviation(abbr)+posixOffset)
+}
+
+// NewPosixTzInfo creates a zone from a POSIX TZ string alone, such as
+// "EST5EDT,M3.2.0,M11.1.0" or "<+0330>-3:30". Local time follows the
+// string's rules for all instants.
+func NewPosixTzInfo(name string, posix string) (*TzInfo, error) {
+	if posix == "" {
+		return nil, fmt.Errorf("empty POSIX string")
+	}
+	return finishTzInfo(&TzInfo{Name: name}, posix)
+}
+
+// NewTransitionsTzInfo creates a zone from a list of transitions, sorted by
+// time. The local time of the first transition also applies before it, so
+// its time only matters for the ordering. The optional POSIX TZ string
+// describes local time after the last transition; without it the last
+// transition's local time applies.
+func NewTransitionsTzInfo(name string, transitions []TzTransition, posix string) (*TzInfo, error) {
+	if len(transitions) == 0 {
+		return nil, fmt.Errorf("timezone %s needs at least one transition", name)
+	}
+
+	tz := &TzInfo{Name: name}
+	current := -1
+	for i, tr := range transitions {
+		if i > 0 && tr.At <= transitions[i-1].At {
+			return nil, fmt.Errorf("transition %d at %d is not after the previous one", i, tr.At)
+		}
+
+		abbr := tr.Abbr
+		if abbr == "" {
+			abbr = zicOffsetAbbreviation(int64(tr.Offset))
+		}
+		if err := validateTzAbbreviation(abbr); err != nil {
+			return nil, err
+		}
+
+		isDst := 0
+		if tr.IsDst {
+			isDst = 1
+		}
+		idx := findTTInfoIndex(tz, tr.Offset, isDst, abbr)
+		if idx == -1 {
+			if len(tz.Type) == 256 {
+				return nil, fmt.Errorf("timezone %s has more than 256 local time types", name)
+			}
+			idx = addNewTTInfoIndex(tz, tr.Offset, isDst, abbr)
+		}
+
+		// The first entry only sets type 0; later ones that do not change
+		// the local time are dropped like zic does
+		if i > 0 && idx != current {
+			tz.Trans = append(tz.Trans, tr.At)
+			tz.TransIdx = append(tz.TransIdx, uint8(idx))
+		}
+		current = idx
+	}
+
+	return finishTzInfo(tz, posix)
+}
+
+// finishTzInfo parses the POSIX string into the zone, fills in the counts
+// that the lookup functions rely on and sets the default location
+func finishTzInfo(tz *TzInfo, posix string) (*TzInfo, error) {
+	if posix != "" {
+		info, err := ParsePosixString(posix, tz)
+		if err != nil {
+			return nil, fmt.Errorf("invalid POSIX string %q: %v", posix, err)
+		}
+		if info.Dst != "" && (info.DstBegin == nil || info.DstEnd == nil) {
+			return nil, fmt.Errorf("POSIX string %q has no DST transition rules", posix)
+		}
+		if len(tz.Type) == 0 {
+			addNewTTInfoIndex(tz, int32(info.StdOffset), 0, info.Std)
+		}
+		tz.PosixString = posix
+		tz.PosixInfo = info
+	}
+
+	tz.Bit64.Timecnt = uint64(len(tz.Trans))
+	tz.Bit64.Typecnt = uint64(len(tz.Type))
+	tz.Bit64.Charcnt = uint64(len(tz.TimezoneAbbr))
+	for _, t := range tz.Trans {
+		if fitsInt32(t) {
+			tz.Bit32.Timecnt++
+		}
+	}
+	tz.Bit32.Typecnt = uint32(len(tz.Type))
+	tz.Bit32.Charcnt = uint32(len(tz.TimezoneAbbr))
+
+	tz.Bc = 1
+	setDefaultLocation(tz)
+
+	return tz, nil
+}
+
+// validateTzAbbreviation checks that an abbreviation can be stored in a
+// TZif file and a POSIX TZ string: at least three letters, digits, '+' or '-'
+func validateTzAbbreviation(abbr string) error {
+	if len(abbr) < 3 {
+		return fmt.Errorf("abbreviation %q is shorter than three characters", abbr)
+	}
+	for i := 0; i < len(abbr); i++ {
+		c := abbr[i]
+		if !isAlphaNum(c) && c != '+' && c != '-' {
+			return fmt.Errorf("abbreviation %q contains invalid character %q", abbr, c)
+		}
+	}
+	return nil
+}
+
+// posixAbbreviation returns an abbreviation for use in a POSIX TZ string,
+// quoted in angle brackets unless it consists of letters only
+func posixAbbreviation(abbr string) string {
+	for i := 0; i < len(abbr); i++ {
+		if !isAlpha(abbr[i]) {
+			return "<" + abbr + ">"
+		}
+	}
+	return abbr
+}
+
+// isAlphaNum checks for an ASCII letter or digit
+func isAlphaNum(c byte) bool {
+	return isAlpha(c) || isDigit(c)
+}
+
+// isAlpha checks for an ASCII letter
+func isAlpha(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
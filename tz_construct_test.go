@@ -0,0 +1,168 @@
+package timelib
+
+import (
+	"testing"
+)
+
+// TestNewFixedOffsetTzInfo tests fixed offset zones
+func TestNewFixedOffsetTzInfo(t *testing.T) {
+	tests := []struct {
+		offset int32
+		abbr   string
+		want   string
+		posix  string
+	}{
+		{19800, "IST", "IST", "IST-5:30"},
+		{-10800, "", "-03", "<-03>3"},
+		{20700, "", "+0545", "<+0545>-5:45"},
+		{0, "UTC", "UTC", "UTC0"},
+	}
+
+	for _, tt := range tests {
+		tz, err := NewFixedOffsetTzInfo("Test/Fixed", tt.offset, tt.abbr)
+		if err != nil {
+			t.Fatalf("NewFixedOffsetTzInfo(%d, %q) failed: %v", tt.offset, tt.abbr, err)
+		}
+		if tz.PosixString != tt.posix {
+			t.Errorf("POSIX string %q, want %q", tz.PosixString, tt.posix)
+		}
+		for _, ts := range []int64{-5000000000, 0, 1720000000, 5000000000} {
+			to := GetTimeZoneInfo(ts, tz)
+			if to.Offset != tt.offset || to.IsDst != 0 || to.Abbr != tt.want {
+				t.Errorf("At %d: got %d/%d/%s, want %d/0/%s", ts, to.Offset, to.IsDst, to.Abbr, tt.offset, tt.want)
+			}
+		}
+	}
+
+	if _, err := NewFixedOffsetTzInfo("Bad", 3600, "X"); err == nil {
+		t.Error("Expected error for short abbreviation")
+	}
+	if _, err := NewFixedOffsetTzInfo("Bad", 3600, "A B"); err == nil {
+		t.Error("Expected error for invalid abbreviation")
+	}
+}
+
+// TestNewPosixTzInfo tests zones defined by a POSIX string only
+func TestNewPosixTzInfo(t *testing.T) {
+	tz, err := NewPosixTzInfo("EST5EDT", "EST5EDT,M3.2.0,M11.1.0")
+	if err != nil {
+		t.Fatalf("NewPosixTzInfo failed: %v", err)
+	}
+
+	// Compare with the builtin zone, which uses the same rules since 2007
+	want, err := ParseTzfile("America/New_York", BuiltinDB(), nil)
+	if err != nil {
+		t.Fatalf("cannot load: %v", err)
+	}
+	instants := []int64{}
+	for _, tr := range want.Trans {
+		if tr > 1200000000 {
+			instants = append(instants, tr-1, tr, tr+1)
+		}
+	}
+	for ts := int64(1200000000); ts < 4000000000; ts += 86400 * 17 {
+		instants = append(instants, ts)
+	}
+	compareTzOffsets(t, "EST5EDT", want, tz, instants)
+
+	// Local to UTC conversion through UpdateTS
+	local := &Time{Y: 2024, M: 7, D: 4, H: 12, ZoneType: TIMELIB_ZONETYPE_ID, TzInfo: tz, IsLocaltime: true}
+	local.UpdateTS(tz)
+	if local.Sse != 1720108800 {
+		t.Errorf("UpdateTS: got %d, want 1720108800", local.Sse)
+	}
+
+	// UTC to local conversion
+	utc := &Time{TzInfo: tz, ZoneType: TIMELIB_ZONETYPE_ID}
+	utc.Unixtime2local(1704110400)
+	if utc.H != 7 || utc.Dst != 0 || utc.Z != -18000 {
+		t.Errorf("Unixtime2local: got %02d h, dst %d, z %d", utc.H, utc.Dst, utc.Z)
+	}
+
+	// Southern hemisphere rules
+	syd, err := NewPosixTzInfo("Sydney", "AEST-10AEDT,M10.1.0,M4.1.0/3")
+	if err != nil {
+		t.Fatalf("NewPosixTzInfo failed: %v", err)
+	}
+	if to := GetTimeZoneInfo(1704110400, syd); to.Offset != 39600 || to.Abbr != "AEDT" {
+		t.Errorf("Sydney in January: got %d/%s", to.Offset, to.Abbr)
+	}
+	if to := GetTimeZoneInfo(1720000000, syd); to.Offset != 36000 || to.Abbr != "AEST" {
+		t.Errorf("Sydney in July: got %d/%s", to.Offset, to.Abbr)
+	}
+	if dst, err := IsTimestampInDST(1704110400, syd); err != nil || dst != 1 {
+		t.Errorf("IsTimestampInDST: got %d, %v", dst, err)
+	}
+
+	for _, posix := range []string{"", "EST5EDT", "EST5EDT,M3.2.0", "5EST"} {
+		if _, err := NewPosixTzInfo("Bad", posix); err == nil {
+			t.Errorf("Expected error for %q", posix)
+		}
+	}
+}
+
+// TestNewTransitionsTzInfo tests zones built from a list of transitions
+func TestNewTransitionsTzInfo(t *testing.T) {
+	transitions := []TzTransition{
+		{At: 0, Offset: 3600, Abbr: "CET"},
+		{At: 1000000000, Offset: 7200, IsDst: true, Abbr: "CEST"},
+		{At: 1010000000, Offset: 3600, Abbr: "CET"},
+		{At: 1020000000, Offset: 3600, Abbr: "CET"},
+		{At: 1030000000, Offset: 10800},
+	}
+
+	tz, err := NewTransitionsTzInfo("Test/Transitions", transitions, "")
+	if err != nil {
+		t.Fatalf("NewTransitionsTzInfo failed: %v", err)
+	}
+	if len(tz.Trans) != 3 || len(tz.Type) != 3 {
+		t.Errorf("Expected 3 transitions and 3 types, got %d and %d", len(tz.Trans), len(tz.Type))
+	}
+
+	tests := []struct {
+		ts     int64
+		offset int32
+		isDst  int
+		abbr   string
+	}{
+		{-1000000000, 3600, 0, "CET"},
+		{999999999, 3600, 0, "CET"},
+		{1000000000, 7200, 1, "CEST"},
+		{1015000000, 3600, 0, "CET"},
+		{1030000000, 10800, 0, "+03"},
+		{2000000000, 10800, 0, "+03"},
+	}
+	for _, tt := range tests {
+		to := GetTimeZoneInfo(tt.ts, tz)
+		if to.Offset != tt.offset || to.IsDst != tt.isDst || to.Abbr != tt.abbr {
+			t.Errorf("At %d: got %d/%d/%s, want %d/%d/%s", tt.ts, to.Offset, to.IsDst, to.Abbr, tt.offset, tt.isDst, tt.abbr)
+		}
+	}
+
+	// With a POSIX string for later instants
+	withRules, err := NewTransitionsTzInfo("Test/Rules", transitions[:3], "CET-1CEST,M3.5.0,M10.5.0/3")
+	if err != nil {
+		t.Fatalf("NewTransitionsTzInfo failed: %v", err)
+	}
+	if to := GetTimeZoneInfo(1720000000, withRules); to.Offset != 7200 || to.Abbr != "CEST" {
+		t.Errorf("Expected POSIX rules after the last transition, got %d/%s", to.Offset, to.Abbr)
+	}
+
+	// The zone can be written as a TZif file
+	data, err := EncodeTzfile(withRules, 0)
+	if err != nil {
+		t.Fatalf("EncodeTzfile failed: %v", err)
+	}
+	parsed, err := ParseTzfileData("Test/Rules", data, nil)
+	if err != nil {
+		t.Fatalf("cannot parse written data: %v", err)
+	}
+	compareTzOffsets(t, "Test/Rules", withRules, parsed, tzSampleInstants(withRules))
+
+	if _, err := NewTransitionsTzInfo("Bad", nil, ""); err == nil {
+		t.Error("Expected error for empty transition list")
+	}
+	if _, err := NewTransitionsTzInfo("Bad", []TzTransition{transitions[1], transitions[0]}, ""); err == nil {
+		t.Error("Expected error for unsorted transitions")
+	}
+}
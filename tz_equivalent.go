@@ -0,0 +1,107 @@
+package timelib
+
+import (
+	"sort"
+)
+
+// TimezonesEquivalent checks whether two zones produce the same UTC offset,
+// DST flag and abbreviation at every instant of the range [begin, end) of UTC
+// timestamps, including the instants covered by their POSIX rules. Unlike
+// SameTimezone, which compares names, it reports e.g. "America/Detroit" and
+// "America/New_York" as equivalent from 1976 on. If the zones differ, the
+// first instant at which they do is returned as well.
+func TimezonesEquivalent(one, two *TzInfo, begin, end int64) (bool, int64) {
+	if one == nil || two == nil {
+		return one == two, begin
+	}
+	if begin >= end {
+		return true, end
+	}
+
+	oneList := zoneTransitions(one, begin, end)
+	twoList := zoneTransitions(two, begin, end)
+
+	// Local time is constant between transitions, so it is enough to
+	// compare both zones at the transitions of either
+	i, j := 0, 0
+	for i < len(oneList) || j < len(twoList) {
+		var at int64
+		switch {
+		case j == len(twoList) || (i < len(oneList) && oneList[i].At < twoList[j].At):
+			at = oneList[i].At
+			i++
+		case i == len(oneList) || twoList[j].At < oneList[i].At:
+			at = twoList[j].At
+			j++
+		default:
+			at = oneList[i].At
+			i++
+			j++
+		}
+
+		if !sameLocalTime(localTimeAt(one, at), localTimeAt(two, at)) {
+			return false, at
+		}
+	}
+	return true, end
+}
+
+// zoneTransitions returns the changes of local time of a zone in the range
+// [begin, end), both from the transition table and from the POSIX rules. The
+// first entry is the local time in effect at begin.
+func zoneTransitions(tz *TzInfo, begin, end int64) []TzTransition {
+	var candidates []int64
+	for _, at := range tz.Trans {
+		if at > begin && at < end {
+			candidates = append(candidates, at)
+		}
+	}
+
+	if tz.PosixInfo != nil && tz.PosixInfo.DstBegin != nil && tz.PosixInfo.DstEnd != nil {
+		from := begin
+		if n := len(tz.Trans); n > 0 && tz.Trans[n-1] > from {
+			from = tz.Trans[n-1]
+		}
+		if from < end {
+			var first, last Time
+			first.Unixtime2gmt(from)
+			last.Unixtime2gmt(end - 1)
+			for year := first.Y - 1; year <= last.Y+1; year++ {
+				var transitions PosixTransitions
+				GetTransitionsForYear(tz, year, &transitions)
+				for i := 0; i < transitions.Count; i++ {
+					if at := transitions.Times[i]; at > from && at < end {
+						candidates = append(candidates, at)
+					}
+				}
+			}
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i] < candidates[j] })
+
+	result := []TzTransition{localTimeAt(tz, begin)}
+	for _, at := range candidates {
+		local := localTimeAt(tz, at)
+		if !sameLocalTime(local, result[len(result)-1]) {
+			result = append(result, local)
+		}
+	}
+	return result
+}
+
+// localTimeAt returns the local time of a zone at a UTC timestamp
+func localTimeAt(tz *TzInfo, ts int64) TzTransition {
+	local := TzTransition{At: ts}
+	if offset := GetTimeZoneInfo(ts, tz); offset != nil {
+		local.Offset = offset.Offset
+		local.IsDst = offset.IsDst != 0
+		local.Abbr = offset.Abbr
+	}
+	return local
+}
+
+// sameLocalTime checks whether two transitions lead to the same local time
+func sameLocalTime(a, b TzTransition) bool {
+	return a.Offset == b.Offset && a.IsDst == b.IsDst && a.Abbr == b.Abbr
+}
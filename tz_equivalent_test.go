@@ -0,0 +1,73 @@
+package timelib
+
+import (
+	"testing"
+)
+
+// TestTimezonesEquivalent tests zones with different names but the same rules
+func TestTimezonesEquivalent(t *testing.T) {
+	load := func(id string) *TzInfo {
+		tz, err := ParseTzfile(id, BuiltinDB(), nil)
+		if err != nil {
+			t.Fatalf("%s: %v", id, err)
+		}
+		return tz
+	}
+	detroit := load("America/Detroit")
+	newYork := load("America/New_York")
+	toronto := load("America/Toronto")
+	chicago := load("America/Chicago")
+	usEastern, err := NewPosixTzInfo("Test/Eastern", "EST5EDT,M3.2.0,M11.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cet, err := NewPosixTzInfo("Test/CET", "CET-1CEST,M3.5.0,M10.5.0/3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plusOne, err := NewFixedOffsetTzInfo("Test/Plus1", 3600, "CET")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		one, two   *TzInfo
+		begin, end int64
+		equivalent bool
+		divergence int64
+	}{
+		// Detroit started DST in 1975 two months after New York
+		{"Detroit from 1976", detroit, newYork, TsAtStartOfYear(1976), TsAtStartOfYear(2100), true, TsAtStartOfYear(2100)},
+		{"Detroit from 1975", detroit, newYork, TsAtStartOfYear(1975), TsAtStartOfYear(2100), false, 162370800},
+		{"New York and Toronto", newYork, toronto, TsAtStartOfYear(1974), TsAtStartOfYear(2100), false, 126687600},
+		{"New York and Toronto since 1976", newYork, toronto, TsAtStartOfYear(1976), TsAtStartOfYear(2100), true, TsAtStartOfYear(2100)},
+		// The table of New York against the POSIX rules alone, which start
+		// DST on March 12 2006 instead of April 2
+		{"POSIX rules", newYork, usEastern, TsAtStartOfYear(2007), TsAtStartOfYear(2200), true, TsAtStartOfYear(2200)},
+		{"POSIX rules before 2007", newYork, usEastern, TsAtStartOfYear(2006), TsAtStartOfYear(2200), false, 1142146800},
+		{"different offsets", newYork, chicago, 0, 1, false, 0},
+		// Same offset, but no DST for the fixed zone
+		{"fixed offset", cet, plusOne, TsAtStartOfYear(2020), TsAtStartOfYear(2021), false, 1585443600},
+		{"fixed offset in winter", cet, plusOne, TsAtStartOfYear(2020), TsAtStartOfYear(2020) + 30*SECS_PER_DAY, true, TsAtStartOfYear(2020) + 30*SECS_PER_DAY},
+		{"itself", chicago, chicago, -1 << 40, 1 << 33, true, 1 << 33},
+		{"empty range", newYork, chicago, 100, 100, true, 100},
+	}
+
+	for _, test := range tests {
+		equivalent, divergence := TimezonesEquivalent(test.one, test.two, test.begin, test.end)
+		if equivalent != test.equivalent || divergence != test.divergence {
+			t.Errorf("%s: got %v at %d, want %v at %d", test.name, equivalent, divergence, test.equivalent, test.divergence)
+		}
+
+		// The check is symmetric
+		equivalent, divergence = TimezonesEquivalent(test.two, test.one, test.begin, test.end)
+		if equivalent != test.equivalent || divergence != test.divergence {
+			t.Errorf("%s (swapped): got %v at %d, want %v at %d", test.name, equivalent, divergence, test.equivalent, test.divergence)
+		}
+	}
+
+	if equivalent, _ := TimezonesEquivalent(nil, newYork, 0, 1); equivalent {
+		t.Error("a nil zone is not equivalent to New York")
+	}
+}
@@ -0,0 +1,150 @@
+package timelib
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Range over which LocationToTzInfo probes a *time.Location for transitions
+// when the zone cannot be found in the database by name
+var (
+	locationProbeStart = time.Date(1800, time.January, 1, 0, 0, 0, 0, time.UTC)
+	locationProbeEnd   = time.Date(2100, time.January, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// Locations converted by timeLocation, per zone. The cache is emptied when
+// it is full, so that it does not keep every zone ever converted alive.
+var (
+	locationCacheMu sync.Mutex
+	locationCache   = map[*TzInfo]*time.Location{}
+)
+
+const locationCacheSize = 256
+
+// TzInfoToLocation converts a zone into a *time.Location with the same name,
+// transitions and POSIX rules, by serializing it as TZif data for
+// time.LoadLocationFromTZData. Leap seconds are dropped, as Go ignores them.
+func TzInfoToLocation(tz *TzInfo) (*time.Location, error) {
+	if tz == nil {
+		return nil, fmt.Errorf("timezone info is nil")
+	}
+
+	if len(tz.LeapTimes) > 0 {
+		clone := *tz
+		clone.LeapTimes = nil
+		clone.Bit32.Leapcnt = 0
+		clone.Bit64.Leapcnt = 0
+		tz = &clone
+	}
+
+	data, err := EncodeTzfile(tz, 0)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := time.LoadLocationFromTZData(tz.Name, data)
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert timezone %s: %v", tz.Name, err)
+	}
+	return loc, nil
+}
+
+// LocationToTzInfo converts a *time.Location into a zone. Locations whose
+// name is an identifier in tzdb (which defaults to BuiltinDB()), such as
+// those from time.LoadLocation, are loaded from the database so that they
+// keep their full rules; time.Local is resolved with LocalTimezone. Other
+// locations, e.g. from time.FixedZone, are rebuilt from the offsets Go
+// reports between 1800 and 2100, with the last one applying after that.
+func LocationToTzInfo(loc *time.Location, tzdb *TzDB) (*TzInfo, error) {
+	if loc == nil {
+		return nil, fmt.Errorf("location is nil")
+	}
+	if tzdb == nil {
+		tzdb = BuiltinDB()
+	}
+
+	name := loc.String()
+	if loc == time.Local {
+		_, tz, err := LocalTimezone(tzdb)
+		return tz, err
+	}
+	if TimezoneIDIsValid(name, tzdb) {
+		return ParseTzfile(name, tzdb, nil)
+	}
+
+	var transitions []TzTransition
+	for t := locationProbeStart.In(loc); ; {
+		abbr, offset := t.Zone()
+		if validateTzAbbreviation(abbr) != nil {
+			abbr = "" // e.g. from time.FixedZone("", ...), use a numeric one
+		}
+		transitions = append(transitions, TzTransition{
+			At:     t.Unix(),
+			Offset: int32(offset),
+			IsDst:  t.IsDST(),
+			Abbr:   abbr,
+		})
+
+		_, end := t.ZoneBounds()
+		if end.IsZero() || !end.Before(locationProbeEnd) {
+			break
+		}
+		if !end.After(t) {
+			// Go's POSIX rule evaluation can return an empty range at the
+			// end of a leap year; step over it, the repeated local time
+			// is dropped by NewTransitionsTzInfo
+			end = t.Add(time.Hour)
+		}
+		t = end
+	}
+
+	if len(transitions) == 1 {
+		tr := transitions[0]
+		return NewFixedOffsetTzInfo(name, tr.Offset, tr.Abbr)
+	}
+	return NewTransitionsTzInfo(name, transitions, "")
+}
+
+// cachedLocation is TzInfoToLocation, converting each zone only once
+func cachedLocation(tz *TzInfo) (*time.Location, error) {
+	locationCacheMu.Lock()
+	loc, ok := locationCache[tz]
+	locationCacheMu.Unlock()
+	if ok {
+		return loc, nil
+	}
+
+	loc, err := TzInfoToLocation(tz)
+	if err != nil {
+		return nil, err
+	}
+	locationCacheMu.Lock()
+	if len(locationCache) >= locationCacheSize {
+		locationCache = map[*TzInfo]*time.Location{}
+	}
+	locationCache[tz] = loc
+	locationCacheMu.Unlock()
+	return loc, nil
+}
+
+// timeLocation returns the *time.Location that corresponds to the zone of t
+func timeLocation(t *Time) *time.Location {
+	switch t.ZoneType {
+	case TIMELIB_ZONETYPE_ID:
+		if t.TzInfo != nil {
+			if loc, err := cachedLocation(t.TzInfo); err == nil {
+				return loc
+			}
+		}
+		return time.FixedZone(t.TzAbbr, int(t.Z))
+
+	case TIMELIB_ZONETYPE_ABBR:
+		return time.FixedZone(t.TzAbbr, int(t.Z)+t.Dst*3600)
+
+	case TIMELIB_ZONETYPE_OFFSET:
+		return time.FixedZone(zicOffsetAbbreviation(int64(t.Z)), int(t.Z))
+
+	default:
+		return time.UTC
+	}
+}
@@ -0,0 +1,207 @@
+package timelib
+
+import (
+	"testing"
+	"time"
+)
+
+// compareTzLocation checks that a zone and a *time.Location agree at the given instants
+func compareTzLocation(t *testing.T, tz *TzInfo, loc *time.Location, instants []int64) {
+	t.Helper()
+
+	for _, ts := range instants {
+		if len(tz.Trans) > 0 && ts < tz.Trans[0] {
+			continue
+		}
+		w := GetTimeZoneInfo(ts, tz)
+		gt := time.Unix(ts, 0).In(loc)
+		abbr, offset := gt.Zone()
+		isDst := 0
+		if gt.IsDST() {
+			isDst = 1
+		}
+		if int32(offset) != w.Offset || isDst != int(w.IsDst) || abbr != w.Abbr {
+			t.Errorf("%s at %d: got %d/%d/%s, want %d/%d/%s", tz.Name, ts, offset, isDst, abbr, w.Offset, w.IsDst, w.Abbr)
+			return
+		}
+	}
+}
+
+func TestTzInfoToLocation(t *testing.T) {
+	for _, id := range []string{"America/Chicago", "Europe/Amsterdam", "Australia/Lord_Howe", "Asia/Kathmandu", "UTC"} {
+		tz, err := ParseTzfile(id, BuiltinDB(), nil)
+		if err != nil {
+			t.Fatalf("%s: %v", id, err)
+		}
+		loc, err := TzInfoToLocation(tz)
+		if err != nil {
+			t.Fatalf("%s: %v", id, err)
+		}
+		if loc.String() != id {
+			t.Errorf("%s: location name %q", id, loc.String())
+		}
+		compareTzLocation(t, tz, loc, tzSampleInstants(tz))
+	}
+
+	posix, err := NewPosixTzInfo("Custom/Posix", "EST5EDT,M3.2.0,M11.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	loc, err := TzInfoToLocation(posix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareTzLocation(t, posix, loc, tzSampleInstants(posix))
+
+	if _, err := TzInfoToLocation(nil); err == nil {
+		t.Error("expected an error for a nil zone")
+	}
+}
+
+func TestLocationToTzInfo(t *testing.T) {
+	tz, err := ParseTzfile("America/Chicago", BuiltinDB(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loc, err := TzInfoToLocation(tz)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Known identifiers come from the database, rules included
+	got, err := LocationToTzInfo(loc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "America/Chicago" || got.PosixString != tz.PosixString {
+		t.Errorf("got %s with %q, want America/Chicago with %q", got.Name, got.PosixString, tz.PosixString)
+	}
+
+	// Unknown names are rebuilt from Go's offsets
+	data, err := EncodeTzfile(tz, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	custom, err := time.LoadLocationFromTZData("Custom/Chicago", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = LocationToTzInfo(custom, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "Custom/Chicago" {
+		t.Errorf("name %q, want Custom/Chicago", got.Name)
+	}
+	var instants []int64
+	for _, ts := range tzSampleInstants(tz) {
+		if ts >= tz.Trans[0] && ts < locationProbeEnd.Unix() {
+			instants = append(instants, ts)
+		}
+	}
+	compareTzOffsets(t, "Custom/Chicago", tz, got, instants)
+
+	tests := []struct {
+		loc    *time.Location
+		offset int32
+		abbr   string
+	}{
+		{time.FixedZone("IST", 19800), 19800, "IST"},
+		{time.FixedZone("", -12600), -12600, "-0330"},
+		{time.FixedZone("X", 3600), 3600, "+01"},
+	}
+	for _, test := range tests {
+		got, err := LocationToTzInfo(test.loc, nil)
+		if err != nil {
+			t.Errorf("%v: %v", test.loc, err)
+			continue
+		}
+		info := GetTimeZoneInfo(0, got)
+		if info.Offset != test.offset || info.Abbr != test.abbr {
+			t.Errorf("%v: got %d/%s, want %d/%s", test.loc, info.Offset, info.Abbr, test.offset, test.abbr)
+		}
+	}
+
+	if _, err := LocationToTzInfo(nil, nil); err == nil {
+		t.Error("expected an error for a nil location")
+	}
+}
+
+func TestConvertTimeKeepsZone(t *testing.T) {
+	tz, err := ParseTzfile("America/Chicago", BuiltinDB(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tm := TimeCtor()
+	tm.Y, tm.M, tm.D = 2024, 3, 9
+	tm.H, tm.I, tm.S, tm.US = 12, 0, 0, 250000
+	tm.TzInfo = tz
+	tm.ZoneType = TIMELIB_ZONETYPE_ID
+	tm.HaveZone = true
+
+	gt := ConvertTime(tm)
+	if gt.Location().String() != "America/Chicago" {
+		t.Fatalf("location %q, want America/Chicago", gt.Location())
+	}
+	if want := "2024-03-09 12:00:00.25 -0600 CST"; gt.Format("2006-01-02 15:04:05.999 -0700 MST") != want {
+		t.Errorf("got %s, want %s", gt.Format("2006-01-02 15:04:05.999 -0700 MST"), want)
+	}
+
+	// The zone is converted once
+	if again := ConvertTime(tm); again.Location() != gt.Location() {
+		t.Errorf("the location was converted again")
+	}
+	if !ConvertTime(nil).IsZero() {
+		t.Errorf("expected the zero time for a nil time")
+	}
+
+	// The named zone follows the DST change a day later
+	next := gt.AddDate(0, 0, 1)
+	if _, offset := next.Zone(); offset != -5*3600 {
+		t.Errorf("offset after the DST change: %d", offset)
+	}
+
+	back := ConvertFromTime(next)
+	if back.ZoneType != TIMELIB_ZONETYPE_ID || back.TzInfo == nil || back.TzInfo.Name != "America/Chicago" {
+		t.Fatalf("zone not kept: type %d", back.ZoneType)
+	}
+	if back.Y != 2024 || back.M != 3 || back.D != 10 || back.H != 12 || back.US != 250000 {
+		t.Errorf("got %04d-%02d-%02d %02d:%02d:%02d.%06d", back.Y, back.M, back.D, back.H, back.I, back.S, back.US)
+	}
+	if back.Sse != next.Unix() || back.TzAbbr != "CDT" || back.Dst != 1 {
+		t.Errorf("got sse %d abbr %s dst %d", back.Sse, back.TzAbbr, back.Dst)
+	}
+}
+
+func TestConvertTimeFixedZones(t *testing.T) {
+	tm := TimeCtor()
+	tm.Y, tm.M, tm.D, tm.H, tm.I, tm.S = 2023, 12, 25, 15, 30, 45
+	tm.Z = 19800
+	tm.ZoneType = TIMELIB_ZONETYPE_OFFSET
+	tm.HaveZone = true
+
+	gt := ConvertTime(tm)
+	if _, offset := gt.Zone(); offset != 19800 {
+		t.Errorf("offset %d, want 19800", offset)
+	}
+	if gt.Unix() != 1703498445 {
+		t.Errorf("got %d, want 1703498445", gt.Unix())
+	}
+
+	tm.ZoneType = TIMELIB_ZONETYPE_ABBR
+	tm.Z = -18000
+	tm.Dst = 1
+	tm.TzAbbr = "EDT"
+	gt = ConvertTime(tm)
+	if abbr, offset := gt.Zone(); abbr != "EDT" || offset != -14400 {
+		t.Errorf("got %s/%d, want EDT/-14400", abbr, offset)
+	}
+
+	// Times without a zone are UTC
+	tm = TimeCtor()
+	tm.Y, tm.M, tm.D, tm.H, tm.I, tm.S = 2023, 12, 25, 15, 30, 45
+	if gt := ConvertTime(tm); !gt.Equal(time.Date(2023, 12, 25, 15, 30, 45, 0, time.UTC)) {
+		t.Errorf("got %v", gt)
+	}
+}
@@ -0,0 +1,167 @@
+package timelib
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// NewLayeredTzDB creates a timezone database that consults the given
+// databases in order, e.g. custom overrides, ZoneinfoDir("/usr/share/zoneinfo")
+// and BuiltinDB(). A zone is loaded from the first database that contains it.
+// The version is that of the first layer with a known (not "custom") version.
+//
+// The index is the merged list of identifiers of all layers, so that
+// TimezoneIdentifiersList and friends see every zone once. It reflects the
+// layers at the time of the call; zones registered with RegisterTimezone on
+// the layered database itself are added to it.
+func NewLayeredTzDB(layers ...*TzDB) *TzDB {
+	tzdb := &TzDB{
+		Version: "custom",
+		Index:   []TzDBIndexEntry{},
+		Data:    []byte{},
+	}
+
+	seen := make(map[string]bool)
+	for _, layer := range layers {
+		if layer == nil {
+			continue
+		}
+		tzdb.Layers = append(tzdb.Layers, layer)
+
+		if tzdb.Version == "custom" && layer.Version != "" && layer.Version != "custom" {
+			tzdb.Version = layer.Version
+		}
+
+		for _, entry := range layer.Index {
+			if !seen[entry.ID] {
+				seen[entry.ID] = true
+				tzdb.Index = append(tzdb.Index, TzDBIndexEntry{ID: entry.ID})
+			}
+		}
+
+		// Earlier layers win for location and country metadata as well
+		for id, loc := range zoneLocations(layer) {
+			if tzdb.Locations == nil {
+				tzdb.Locations = make(map[string]TLocInfo)
+			}
+			if _, ok := tzdb.Locations[id]; !ok {
+				tzdb.Locations[id] = loc
+			}
+		}
+		for code, name := range layer.CountryNames {
+			if tzdb.CountryNames == nil {
+				tzdb.CountryNames = make(map[string]string)
+			}
+			if _, ok := tzdb.CountryNames[code]; !ok {
+				tzdb.CountryNames[code] = name
+			}
+		}
+	}
+
+	sortTzDBIndex(tzdb)
+	return tzdb
+}
+
+// registeredTimezone is a zone added with RegisterTimezone
+type registeredTimezone struct {
+	data    []byte // PHP format TZif data
+	indexed bool   // whether registering added the identifier to the index
+}
+
+// RegisterTimezone adds a user-defined zone, such as a fixed offset or a zone
+// with custom rules, to the database under the given name. ParseTzfile and
+// the date parser resolve it like any other identifier, and it takes
+// precedence over a zone of the same name in the database or its layers.
+//
+// The zone is copied, so later changes to tz have no effect. Registering is
+// not safe while the database is used concurrently; register zones before
+// sharing the database. To leave the shared builtin database untouched,
+// register on NewLayeredTzDB(BuiltinDB()) instead.
+func RegisterTimezone(tzdb *TzDB, name string, tz *TzInfo) error {
+	if tzdb == nil {
+		return fmt.Errorf("timezone database is nil")
+	}
+	if name == "" {
+		return fmt.Errorf("timezone name cannot be empty")
+	}
+
+	data, err := EncodePHPTzfile(tz, true)
+	if err != nil {
+		return err
+	}
+	if _, err := ParseTzfileData(name, data, nil); err != nil {
+		return fmt.Errorf("cannot register %s: %v", name, err)
+	}
+
+	if tzdb.registered == nil {
+		tzdb.registered = make(map[string]*registeredTimezone)
+	}
+	reg := &registeredTimezone{data: data}
+	if prev, ok := tzdb.registered[name]; ok {
+		reg.indexed = prev.indexed
+	} else if !TimezoneIDIsValid(name, tzdb) {
+		tzdb.Index = append(tzdb.Index, TzDBIndexEntry{ID: name})
+		sortTzDBIndex(tzdb)
+		reg.indexed = true
+	}
+	tzdb.registered[name] = reg
+
+	if hasZoneLocation(tz.Location) && tzdb.Locations != nil {
+		tzdb.Locations[name] = tz.Location
+	}
+	return nil
+}
+
+// UnregisterTimezone removes a zone added with RegisterTimezone. A zone of
+// the same name in the database or its layers becomes visible again.
+func UnregisterTimezone(tzdb *TzDB, name string) bool {
+	if tzdb == nil {
+		return false
+	}
+	reg, ok := tzdb.registered[name]
+	if !ok {
+		return false
+	}
+	delete(tzdb.registered, name)
+
+	if reg.indexed {
+		index := tzdb.Index[:0]
+		for _, entry := range tzdb.Index {
+			if entry.ID != name {
+				index = append(index, entry)
+			}
+		}
+		tzdb.Index = index
+		tzdb.IndexSize = len(index)
+	}
+	return true
+}
+
+// loadLayeredTzFile returns the data of a registered zone or of the first
+// layer that contains the zone. The bool result reports whether the database
+// handles the zone this way at all.
+func loadLayeredTzFile(tzName string, tzdb *TzDB) ([]byte, string, bool, error) {
+	if reg, ok := tzdb.registered[tzName]; ok {
+		return reg.data, tzName, true, nil
+	}
+	if len(tzdb.Layers) == 0 {
+		return nil, "", false, nil
+	}
+
+	for _, layer := range tzdb.Layers {
+		if TimezoneIDIsValid(tzName, layer) {
+			data, location, err := LoadTzFileFromDB(tzName, layer)
+			return data, location, true, err
+		}
+	}
+	return nil, "", true, fmt.Errorf("timezone '%s' not found in database", tzName)
+}
+
+// sortTzDBIndex sorts the index by ID like ZoneinfoDir does
+func sortTzDBIndex(tzdb *TzDB) {
+	sort.SliceStable(tzdb.Index, func(i, j int) bool {
+		return strings.ToLower(tzdb.Index[i].ID) < strings.ToLower(tzdb.Index[j].ID)
+	})
+	tzdb.IndexSize = len(tzdb.Index)
+}
@@ -0,0 +1,181 @@
+package timelib
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// fixedTestZone returns a zone with a single fixed offset
+func fixedTestZone(offset int32, abbr string) *TzInfo {
+	return &TzInfo{
+		Type:         []TTInfo{{Offset: offset}},
+		TimezoneAbbr: abbr + "\x00",
+		PosixString:  "<" + abbr + ">0",
+	}
+}
+
+// TestLayeredTzDB tests lookup order and the merged index
+func TestLayeredTzDB(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("tests", "files", "New_York_Slim"))
+	if err != nil {
+		t.Skipf("fixture not available: %v", err)
+	}
+
+	// An override layer that maps Europe/London onto the New York rules
+	override, err := ZoneinfoFS(fstest.MapFS{
+		"Europe/London": {Data: fixture},
+		"Custom/Local":  {Data: fixture},
+	})
+	if err != nil {
+		t.Fatalf("ZoneinfoFS failed: %v", err)
+	}
+
+	tzdb := NewLayeredTzDB(override, BuiltinDB())
+	if tzdb.Version != "2025.2" {
+		t.Errorf("Expected version of the first versioned layer, got %q", tzdb.Version)
+	}
+	if tzdb.IndexSize != BuiltinDB().IndexSize+1 {
+		t.Errorf("Expected %d merged entries, got %d", BuiltinDB().IndexSize+1, tzdb.IndexSize)
+	}
+
+	ids := TimezoneIdentifiersList(tzdb, nil)
+	count := 0
+	for _, entry := range ids {
+		if entry.ID == "Europe/London" {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("Expected Europe/London once in the merged list, got %d", count)
+	}
+
+	london, err := ParseTzfile("Europe/London", tzdb, nil)
+	if err != nil {
+		t.Fatalf("ParseTzfile failed: %v", err)
+	}
+	if to := GetTimeZoneInfo(1720000000, london); to.Abbr != "EDT" {
+		t.Errorf("Expected override layer to win, got %s", to.Abbr)
+	}
+
+	paris, err := ParseTzfileFromDB("Europe/Paris", tzdb, nil)
+	if err != nil {
+		t.Fatalf("ParseTzfileFromDB failed: %v", err)
+	}
+	if to := GetTimeZoneInfo(1720000000, paris); to.Abbr != "CEST" {
+		t.Errorf("Expected Europe/Paris from the builtin layer, got %s", to.Abbr)
+	}
+	if string(paris.Location.CountryCode[:2]) != "FR" {
+		t.Errorf("Expected location from the builtin layer, got %+v", paris.Location)
+	}
+
+	if _, err := ParseTzfileFromDB("Nowhere/Zone", tzdb, nil); err == nil {
+		t.Error("Expected error for unknown zone")
+	}
+
+	europe, _ := TimezoneIdentifiersListGroup(tzdb, TIMELIB_TZ_GROUP_EUROPE, "")
+	if !slices.Contains(europe, "Europe/Paris") {
+		t.Error("Expected Europe/Paris in the merged Europe group")
+	}
+}
+
+// TestRegisterTimezone tests user-defined zones
+func TestRegisterTimezone(t *testing.T) {
+	tzdb := NewLayeredTzDB(BuiltinDB())
+
+	if err := RegisterTimezone(tzdb, "Company/HQ", fixedTestZone(19800, "HQT")); err != nil {
+		t.Fatalf("RegisterTimezone failed: %v", err)
+	}
+	if !TimezoneIDIsValid("Company/HQ", tzdb) {
+		t.Error("Expected registered zone in the index")
+	}
+	if TimezoneIDIsValid("Company/HQ", BuiltinDB()) {
+		t.Error("Registering on a layered database must not change its layers")
+	}
+
+	tz, err := ParseTzfile("Company/HQ", tzdb, nil)
+	if err != nil {
+		t.Fatalf("ParseTzfile failed: %v", err)
+	}
+	if to := GetTimeZoneInfo(1700000000, tz); to.Offset != 19800 || to.Abbr != "HQT" {
+		t.Errorf("Got %d/%s, want 19800/HQT", to.Offset, to.Abbr)
+	}
+
+	all, _ := TimezoneIdentifiersListGroup(tzdb, TIMELIB_TZ_GROUP_ALL_WITH_BC, "")
+	if !slices.Contains(all, "Company/HQ") {
+		t.Error("Expected registered zone in TIMELIB_TZ_GROUP_ALL_WITH_BC")
+	}
+
+	// The date parser resolves registered zones like IANA identifiers
+	parsed, err := StrToTime("2024-03-01 12:00:00 Company/HQ", tzdb)
+	if err != nil {
+		t.Fatalf("StrToTime failed: %v", err)
+	}
+	if parsed.TzInfo == nil || parsed.TzInfo.Name != "Company/HQ" {
+		t.Fatalf("Expected Company/HQ zone, got %+v", parsed.TzInfo)
+	}
+	parsed.UpdateTS(nil)
+	if parsed.Sse != 1709294400-19800 {
+		t.Errorf("Expected %d, got %d", 1709294400-19800, parsed.Sse)
+	}
+
+	// Overriding an existing identifier and removing the override again
+	if err := RegisterTimezone(tzdb, "Europe/Paris", fixedTestZone(0, "XXX")); err != nil {
+		t.Fatalf("RegisterTimezone failed: %v", err)
+	}
+	paris, _ := ParseTzfile("Europe/Paris", tzdb, nil)
+	if to := GetTimeZoneInfo(1720000000, paris); to.Abbr != "XXX" {
+		t.Errorf("Expected override, got %s", to.Abbr)
+	}
+	if !UnregisterTimezone(tzdb, "Europe/Paris") {
+		t.Error("Expected Europe/Paris to be unregistered")
+	}
+	paris, _ = ParseTzfile("Europe/Paris", tzdb, nil)
+	if to := GetTimeZoneInfo(1720000000, paris); to.Abbr != "CEST" {
+		t.Errorf("Expected builtin zone after unregistering, got %s", to.Abbr)
+	}
+	if !TimezoneIDIsValid("Europe/Paris", tzdb) {
+		t.Error("Europe/Paris should stay in the index")
+	}
+
+	UnregisterTimezone(tzdb, "Company/HQ")
+	if TimezoneIDIsValid("Company/HQ", tzdb) {
+		t.Error("Company/HQ should be removed from the index")
+	}
+	if UnregisterTimezone(tzdb, "Company/HQ") {
+		t.Error("Expected false for a zone that is not registered")
+	}
+
+	if err := RegisterTimezone(tzdb, "Bad/Zone", &TzInfo{}); err == nil {
+		t.Error("Expected error for zone without types")
+	}
+}
+
+// TestRegisterTimezoneCompiled tests registering a zone with custom rules
+func TestRegisterTimezoneCompiled(t *testing.T) {
+	src := NewTzSource()
+	err := src.Parse(strings.NewReader(
+		"R Moon 2000 ma - Ja 1 0 1 S\n"+
+			"R Moon 2000 ma - Jul 1 0 0 -\n"+
+			"Z Moon/Base 3 Moon MB%sT\n"), "moon.zi")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	tz, err := src.Compile("Moon/Base")
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	tzdb := NewLayeredTzDB(BuiltinDB())
+	if err := RegisterTimezone(tzdb, "Moon/Base", tz); err != nil {
+		t.Fatalf("RegisterTimezone failed: %v", err)
+	}
+	got, err := ParseTzfile("Moon/Base", tzdb, nil)
+	if err != nil {
+		t.Fatalf("ParseTzfile failed: %v", err)
+	}
+	compareTzOffsets(t, "Moon/Base", tz, got, tzSampleInstants(tz))
+}
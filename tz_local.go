@@ -0,0 +1,183 @@
+package timelib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// System files consulted by LocalTimezone, variables so that tests can
+// point them elsewhere
+var (
+	localtimePath    = "/etc/localtime"
+	timezoneFilePath = "/etc/timezone"
+)
+
+// defaultTimezone holds the process-wide default zone, see SetDefaultTimezone
+var defaultTimezone struct {
+	mu sync.Mutex
+	id string
+	tz *TzInfo
+}
+
+// LocalTimezone detects the time zone of the host, like the C library does
+// on Linux. The TZ environment variable is used first: an identifier from the
+// database, ":" followed by an identifier or a file path, an absolute path,
+// or a POSIX TZ string such as "EST5EDT,M3.2.0,M11.1.0". Without TZ, the
+// target of the /etc/localtime symlink, the name in /etc/timezone and the
+// contents of /etc/localtime are tried in turn. If nothing is found, or TZ is
+// set but empty, UTC is returned.
+//
+// Identifiers are looked up in tzdb, which defaults to BuiltinDB().
+func LocalTimezone(tzdb *TzDB) (string, *TzInfo, error) {
+	if tzdb == nil {
+		tzdb = BuiltinDB()
+	}
+
+	if value, ok := os.LookupEnv("TZ"); ok {
+		return timezoneFromTZ(value, tzdb)
+	}
+
+	// /etc/localtime -> /usr/share/zoneinfo/Europe/Amsterdam
+	if target, err := os.Readlink(localtimePath); err == nil {
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(localtimePath), target)
+		}
+		if id := zoneinfoPathID(target); id != "" && TimezoneIDIsValid(id, tzdb) {
+			tz, err := ParseTzfile(id, tzdb, nil)
+			return id, tz, err
+		}
+	}
+
+	if data, err := os.ReadFile(timezoneFilePath); err == nil {
+		id := strings.TrimSpace(string(data))
+		if TimezoneIDIsValid(id, tzdb) {
+			tz, err := ParseTzfile(id, tzdb, nil)
+			return id, tz, err
+		}
+	}
+
+	if tz, err := ParseTzfileFromFile(localtimePath, nil); err == nil {
+		tz.Name = "localtime"
+		return tz.Name, tz, nil
+	}
+
+	return utcTimezone(tzdb)
+}
+
+// timezoneFromTZ interprets the value of the TZ environment variable
+func timezoneFromTZ(value string, tzdb *TzDB) (string, *TzInfo, error) {
+	value = strings.TrimPrefix(value, ":")
+	if value == "" {
+		return utcTimezone(tzdb)
+	}
+
+	if TimezoneIDIsValid(value, tzdb) {
+		tz, err := ParseTzfile(value, tzdb, nil)
+		return value, tz, err
+	}
+
+	if filepath.IsAbs(value) {
+		if id := zoneinfoPathID(value); id != "" && TimezoneIDIsValid(id, tzdb) {
+			tz, err := ParseTzfile(id, tzdb, nil)
+			return id, tz, err
+		}
+		tz, err := ParseTzfileFromFile(value, nil)
+		if err != nil {
+			return "", nil, fmt.Errorf("cannot load TZ file %s: %v", value, err)
+		}
+		tz.Name = value
+		return value, tz, nil
+	}
+
+	tz, err := NewPosixTzInfo(value, value)
+	if err != nil {
+		return "", nil, fmt.Errorf("TZ %q is neither a known timezone nor a POSIX TZ string", value)
+	}
+	return value, tz, nil
+}
+
+// zoneinfoPathID returns the identifier for a path inside a zoneinfo tree,
+// e.g. "Europe/Amsterdam" for "/usr/share/zoneinfo/Europe/Amsterdam", or an
+// empty string if the path is not in one
+func zoneinfoPathID(path string) string {
+	path = filepath.ToSlash(filepath.Clean(path))
+
+	idx := strings.LastIndex(path, "/zoneinfo/")
+	if idx < 0 {
+		return ""
+	}
+	id := path[idx+len("/zoneinfo/"):]
+	for _, prefix := range []string{"posix/", "right/"} {
+		id = strings.TrimPrefix(id, prefix)
+	}
+	return id
+}
+
+// utcTimezone returns the UTC zone from the database, or a fixed offset zone
+// if the database has none
+func utcTimezone(tzdb *TzDB) (string, *TzInfo, error) {
+	if TimezoneIDIsValid("UTC", tzdb) {
+		tz, err := ParseTzfile("UTC", tzdb, nil)
+		return "UTC", tz, err
+	}
+	tz, err := NewFixedOffsetTzInfo("UTC", 0, "UTC")
+	return "UTC", tz, err
+}
+
+// SetDefaultTimezone sets the process-wide default timezone returned by
+// DefaultTimezone, like PHP's date_default_timezone_set(). The identifier is
+// looked up in tzdb, which defaults to BuiltinDB().
+func SetDefaultTimezone(id string, tzdb *TzDB) error {
+	if tzdb == nil {
+		tzdb = BuiltinDB()
+	}
+
+	tz, err := ParseTzfile(id, tzdb, nil)
+	if err != nil {
+		return err
+	}
+	SetDefaultTimezoneInfo(id, tz)
+	return nil
+}
+
+// SetDefaultTimezoneInfo sets the process-wide default timezone to a zone
+// that does not come from a database, e.g. one built with NewPosixTzInfo
+func SetDefaultTimezoneInfo(id string, tz *TzInfo) {
+	defaultTimezone.mu.Lock()
+	defer defaultTimezone.mu.Unlock()
+
+	defaultTimezone.id = id
+	defaultTimezone.tz = tz
+}
+
+// ResetDefaultTimezone clears the default set with SetDefaultTimezone, so
+// that the host's timezone is detected again on the next DefaultTimezone call
+func ResetDefaultTimezone() {
+	defaultTimezone.mu.Lock()
+	defer defaultTimezone.mu.Unlock()
+
+	defaultTimezone.id = ""
+	defaultTimezone.tz = nil
+}
+
+// DefaultTimezone returns the process-wide default timezone, like PHP's
+// date_default_timezone_get(). Unless one was set with SetDefaultTimezone,
+// the host's timezone is detected once with LocalTimezone (falling back to
+// UTC). The returned TzInfo is shared and must not be modified.
+func DefaultTimezone() (string, *TzInfo) {
+	defaultTimezone.mu.Lock()
+	defer defaultTimezone.mu.Unlock()
+
+	if defaultTimezone.tz == nil {
+		id, tz, err := LocalTimezone(nil)
+		if err != nil || tz == nil {
+			id, tz, _ = utcTimezone(BuiltinDB())
+		}
+		defaultTimezone.id = id
+		defaultTimezone.tz = tz
+	}
+	return defaultTimezone.id, defaultTimezone.tz
+}
@@ -0,0 +1,180 @@
+package timelib
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setLocalTimezonePaths points the system files at a test directory
+func setLocalTimezonePaths(t *testing.T, dir string) {
+	t.Helper()
+
+	oldLocaltime, oldTimezone := localtimePath, timezoneFilePath
+	localtimePath = filepath.Join(dir, "localtime")
+	timezoneFilePath = filepath.Join(dir, "timezone")
+	t.Cleanup(func() {
+		localtimePath, timezoneFilePath = oldLocaltime, oldTimezone
+	})
+}
+
+// unsetTZ removes the TZ environment variable for the duration of the test
+func unsetTZ(t *testing.T) {
+	t.Helper()
+	t.Setenv("TZ", "")
+	os.Unsetenv("TZ")
+}
+
+// TestLocalTimezoneTZ tests the interpretation of the TZ environment variable
+func TestLocalTimezoneTZ(t *testing.T) {
+	fixture, err := filepath.Abs(filepath.Join("tests", "files", "New_York_Slim"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		tz     string
+		id     string
+		ts     int64
+		offset int32
+		abbr   string
+	}{
+		{"Europe/Amsterdam", "Europe/Amsterdam", 1720000000, 7200, "CEST"},
+		{":America/New_York", "America/New_York", 1720000000, -14400, "EDT"},
+		{"/usr/share/zoneinfo/Asia/Tokyo", "Asia/Tokyo", 1720000000, 32400, "JST"},
+		{":/usr/share/zoneinfo/posix/Asia/Tokyo", "Asia/Tokyo", 1720000000, 32400, "JST"},
+		{fixture, fixture, 1720000000, -14400, "EDT"},
+		{"XST5XDT,M3.2.0,M11.1.0", "XST5XDT,M3.2.0,M11.1.0", 1720000000, -14400, "XDT"},
+		{"<+0330>-3:30", "<+0330>-3:30", 1720000000, 12600, "+0330"},
+		{"", "UTC", 1720000000, 0, "UTC"},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("TZ", tt.tz)
+
+		id, tz, err := LocalTimezone(nil)
+		if err != nil {
+			t.Errorf("TZ=%q: %v", tt.tz, err)
+			continue
+		}
+		if id != tt.id {
+			t.Errorf("TZ=%q: identifier %q, want %q", tt.tz, id, tt.id)
+		}
+		if to := GetTimeZoneInfo(tt.ts, tz); to.Offset != tt.offset || to.Abbr != tt.abbr {
+			t.Errorf("TZ=%q: got %d/%s, want %d/%s", tt.tz, to.Offset, to.Abbr, tt.offset, tt.abbr)
+		}
+	}
+
+	for _, bad := range []string{"Bogus/Zone", "/nonexistent/file"} {
+		t.Setenv("TZ", bad)
+		if _, _, err := LocalTimezone(nil); err == nil {
+			t.Errorf("TZ=%q: expected error", bad)
+		}
+	}
+}
+
+// TestLocalTimezoneSystemFiles tests detection from /etc/localtime and /etc/timezone
+func TestLocalTimezoneSystemFiles(t *testing.T) {
+	unsetTZ(t)
+
+	fixture, err := os.ReadFile(filepath.Join("tests", "files", "New_York_Slim"))
+	if err != nil {
+		t.Skipf("fixture not available: %v", err)
+	}
+
+	// Symlink into a zoneinfo tree
+	dir := t.TempDir()
+	setLocalTimezonePaths(t, dir)
+	if err := os.Symlink("zoneinfo/Europe/Paris", filepath.Join(dir, "localtime")); err != nil {
+		t.Skipf("cannot create symlink: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "timezone"), []byte("Asia/Tokyo\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if id, _, err := LocalTimezone(nil); err != nil || id != "Europe/Paris" {
+		t.Errorf("Symlink: got %q, %v, want Europe/Paris", id, err)
+	}
+
+	// /etc/timezone when /etc/localtime is a copy
+	os.Remove(filepath.Join(dir, "localtime"))
+	if err := os.WriteFile(filepath.Join(dir, "localtime"), fixture, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if id, _, err := LocalTimezone(nil); err != nil || id != "Asia/Tokyo" {
+		t.Errorf("/etc/timezone: got %q, %v, want Asia/Tokyo", id, err)
+	}
+
+	// Only the contents of /etc/localtime
+	os.Remove(filepath.Join(dir, "timezone"))
+	id, tz, err := LocalTimezone(nil)
+	if err != nil || id != "localtime" {
+		t.Fatalf("/etc/localtime contents: got %q, %v, want localtime", id, err)
+	}
+	if to := GetTimeZoneInfo(1720000000, tz); to.Abbr != "EDT" {
+		t.Errorf("Expected EDT from the localtime file, got %s", to.Abbr)
+	}
+
+	// Nothing at all
+	os.Remove(filepath.Join(dir, "localtime"))
+	if id, _, err := LocalTimezone(nil); err != nil || id != "UTC" {
+		t.Errorf("No configuration: got %q, %v, want UTC", id, err)
+	}
+}
+
+// TestDefaultTimezone tests the process-wide default timezone
+func TestDefaultTimezone(t *testing.T) {
+	t.Cleanup(ResetDefaultTimezone)
+
+	t.Setenv("TZ", "America/Chicago")
+	ResetDefaultTimezone()
+	if id, _ := DefaultTimezone(); id != "America/Chicago" {
+		t.Errorf("Expected detected America/Chicago, got %q", id)
+	}
+
+	if err := SetDefaultTimezone("Europe/Amsterdam", nil); err != nil {
+		t.Fatalf("SetDefaultTimezone failed: %v", err)
+	}
+	if id, tz := DefaultTimezone(); id != "Europe/Amsterdam" || tz == nil {
+		t.Errorf("Expected Europe/Amsterdam, got %q", id)
+	}
+	if err := SetDefaultTimezone("Europe/Amsterdm", nil); err == nil {
+		t.Error("Expected error for unknown timezone")
+	}
+	if id, _ := DefaultTimezone(); id != "Europe/Amsterdam" {
+		t.Errorf("Failed set must keep the default, got %q", id)
+	}
+
+	// 2024-07-01 12:00 in Amsterdam is 10:00 UTC
+	if ts := StrtotimeInTimezone("2024-07-01 12:00", 0, nil, nil); ts != 1719828000 {
+		t.Errorf("StrtotimeInTimezone = %d, want 1719828000", ts)
+	}
+	// An explicit zone in the string wins
+	if ts := StrtotimeInTimezone("2024-07-01 12:00 UTC", 0, nil, nil); ts != 1719835200 {
+		t.Errorf("StrtotimeInTimezone with UTC = %d, want 1719835200", ts)
+	}
+	// "tomorrow" is midnight in the zone; base is 2024-06-30 23:30 UTC, 01:30 on July 1st in Amsterdam
+	if ts := StrtotimeInTimezone("tomorrow", 1719790200, nil, nil); ts != 1719871200 {
+		t.Errorf("StrtotimeInTimezone(tomorrow) = %d, want 1719871200", ts)
+	}
+
+	posix, err := NewPosixTzInfo("XST5XDT", "XST5XDT,M3.2.0,M11.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetDefaultTimezoneInfo("XST5XDT", posix)
+	if ts := StrtotimeInTimezone("2024-07-01 12:00", 0, nil, nil); ts != 1719849600 {
+		t.Errorf("StrtotimeInTimezone with POSIX zone = %d, want 1719849600", ts)
+	}
+	if ts := StrtotimeInTimezone("not a date at all", 0, nil, nil); ts != -1 {
+		t.Errorf("Expected -1 for an invalid string, got %d", ts)
+	}
+
+	// A zone in the string is looked up in the database given
+	tzdb := NewLayeredTzDB(BuiltinDB())
+	if err := RegisterTimezone(tzdb, "Company/HQ", fixedTestZone(19800, "HQT")); err != nil {
+		t.Fatal(err)
+	}
+	if ts := StrtotimeInTimezone("2024-07-01 12:00 Company/HQ", 0, posix, tzdb); ts != 1719815400 {
+		t.Errorf("StrtotimeInTimezone with a registered zone = %d, want 1719815400", ts)
+	}
+}
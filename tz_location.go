@@ -0,0 +1,372 @@
+package timelib
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Timezone group flags, matching PHP's DateTimeZone::listIdentifiers() constants
+const (
+	TIMELIB_TZ_GROUP_AFRICA      = 0x0001
+	TIMELIB_TZ_GROUP_AMERICA     = 0x0002
+	TIMELIB_TZ_GROUP_ANTARCTICA  = 0x0004
+	TIMELIB_TZ_GROUP_ARCTIC      = 0x0008
+	TIMELIB_TZ_GROUP_ASIA        = 0x0010
+	TIMELIB_TZ_GROUP_ATLANTIC    = 0x0020
+	TIMELIB_TZ_GROUP_AUSTRALIA   = 0x0040
+	TIMELIB_TZ_GROUP_EUROPE      = 0x0080
+	TIMELIB_TZ_GROUP_INDIAN      = 0x0100
+	TIMELIB_TZ_GROUP_PACIFIC     = 0x0200
+	TIMELIB_TZ_GROUP_UTC         = 0x0400
+	TIMELIB_TZ_GROUP_ALL         = 0x07FF
+	TIMELIB_TZ_GROUP_ALL_WITH_BC = 0x0FFF
+	TIMELIB_TZ_GROUP_PER_COUNTRY = 0x1000
+)
+
+// tzGroupPrefixes maps group flags to the identifier prefix they select
+var tzGroupPrefixes = []struct {
+	Group  int
+	Prefix string
+}{
+	{TIMELIB_TZ_GROUP_AFRICA, "Africa/"},
+	{TIMELIB_TZ_GROUP_AMERICA, "America/"},
+	{TIMELIB_TZ_GROUP_ANTARCTICA, "Antarctica/"},
+	{TIMELIB_TZ_GROUP_ARCTIC, "Arctic/"},
+	{TIMELIB_TZ_GROUP_ASIA, "Asia/"},
+	{TIMELIB_TZ_GROUP_ATLANTIC, "Atlantic/"},
+	{TIMELIB_TZ_GROUP_AUSTRALIA, "Australia/"},
+	{TIMELIB_TZ_GROUP_EUROPE, "Europe/"},
+	{TIMELIB_TZ_GROUP_INDIAN, "Indian/"},
+	{TIMELIB_TZ_GROUP_PACIFIC, "Pacific/"},
+}
+
+// embeddedLocationsMu guards the lazily filled Locations of embedded databases
+var embeddedLocationsMu sync.Mutex
+
+// loadZoneTabs loads zone.tab, zone1970.tab and iso3166.tab from a zoneinfo
+// file system into the database. Missing files are silently skipped.
+func loadZoneTabs(tzdb *TzDB, fsys fs.FS) {
+	// zone1970.tab first, so that the single-country zone.tab entries win
+	for _, name := range []string{"zone1970.tab", "zone.tab"} {
+		f, err := fsys.Open(name)
+		if err != nil {
+			continue
+		}
+		locations, err := ParseZoneTab(f)
+		f.Close()
+		if err != nil {
+			continue
+		}
+
+		if tzdb.Locations == nil {
+			tzdb.Locations = make(map[string]TLocInfo, len(locations))
+		}
+		for id, loc := range locations {
+			tzdb.Locations[id] = loc
+		}
+	}
+
+	if f, err := fsys.Open("iso3166.tab"); err == nil {
+		names, err := ParseISO3166Tab(f)
+		f.Close()
+		if err == nil {
+			tzdb.CountryNames = names
+		}
+	}
+}
+
+// ParseZoneTab parses the contents of a zone.tab or zone1970.tab file.
+// For zone1970.tab entries that list several countries, the first (most
+// populous) country is used as the zone's country code.
+func ParseZoneTab(r io.Reader) (map[string]TLocInfo, error) {
+	locations := make(map[string]TLocInfo)
+	scanner := bufio.NewScanner(r)
+	lineNr := 0
+
+	for scanner.Scan() {
+		lineNr++
+		line := scanner.Text()
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("line %d: expected at least 3 fields", lineNr)
+		}
+
+		lat, lon, err := parseISO6709(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNr, err)
+		}
+
+		code := fields[0]
+		if comma := strings.IndexByte(code, ','); comma >= 0 {
+			code = code[:comma]
+		}
+		if len(code) != 2 {
+			return nil, fmt.Errorf("line %d: invalid country code %q", lineNr, fields[0])
+		}
+
+		loc := TLocInfo{
+			Latitude:  lat,
+			Longitude: lon,
+		}
+		copy(loc.CountryCode[:], code)
+		if len(fields) > 3 {
+			loc.Comments = fields[3]
+		}
+
+		locations[fields[2]] = loc
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return locations, nil
+}
+
+// ParseISO3166Tab parses the contents of an iso3166.tab file into a map
+// from two-letter country code to country name
+func ParseISO3166Tab(r io.Reader) (map[string]string, error) {
+	names := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		code, name, found := strings.Cut(line, "\t")
+		if !found || len(code) != 2 {
+			continue
+		}
+		names[code] = name
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// parseISO6709 parses the ±DDMM±DDDMM or ±DDMMSS±DDDMMSS coordinates used in
+// the tz tab files into decimal degrees
+func parseISO6709(s string) (lat, lon float64, err error) {
+	split := strings.IndexAny(s[min(1, len(s)):], "+-") + 1
+	if len(s) == 0 || split <= 0 {
+		return 0, 0, fmt.Errorf("invalid coordinates %q", s)
+	}
+
+	lat, err = parseISO6709Part(s[:split], 2)
+	if err != nil {
+		return 0, 0, err
+	}
+	lon, err = parseISO6709Part(s[split:], 3)
+	if err != nil {
+		return 0, 0, err
+	}
+	return lat, lon, nil
+}
+
+// parseISO6709Part parses one signed coordinate with the given number of degree digits
+func parseISO6709Part(s string, degDigits int) (float64, error) {
+	if len(s) < 1+degDigits+2 || (s[0] != '+' && s[0] != '-') {
+		return 0, fmt.Errorf("invalid coordinate %q", s)
+	}
+
+	digits := s[1:]
+	if len(digits) != degDigits+2 && len(digits) != degDigits+4 {
+		return 0, fmt.Errorf("invalid coordinate %q", s)
+	}
+
+	value := 0.0
+	divisor := 1.0
+	for pos := 0; pos < len(digits); {
+		width := 2
+		if pos == 0 {
+			width = degDigits
+		}
+		n, err := strconv.Atoi(digits[pos : pos+width])
+		if err != nil {
+			return 0, fmt.Errorf("invalid coordinate %q", s)
+		}
+		value += float64(n) / divisor
+		divisor *= 60
+		pos += width
+	}
+
+	if s[0] == '-' {
+		value = -value
+	}
+	return value, nil
+}
+
+// hasZoneLocation reports whether location data is known for the entry
+func hasZoneLocation(loc TLocInfo) bool {
+	return loc.CountryCode[0] != 0 && loc.CountryCode[0] != '?'
+}
+
+// zoneLocations returns the location metadata of all zones in the database.
+// For embedded (PHP format) databases this is read from the zone data itself
+// on first use and cached in tzdb.Locations.
+func zoneLocations(tzdb *TzDB) map[string]TLocInfo {
+	if tzdb == nil || len(tzdb.Data) == 0 {
+		if tzdb == nil {
+			return nil
+		}
+		return tzdb.Locations
+	}
+
+	embeddedLocationsMu.Lock()
+	defer embeddedLocationsMu.Unlock()
+
+	if tzdb.Locations != nil {
+		return tzdb.Locations
+	}
+
+	locations := make(map[string]TLocInfo, len(tzdb.Index))
+	for _, entry := range tzdb.Index {
+		data, _, err := LoadTzFileFromDB(entry.ID, tzdb)
+		if err != nil {
+			continue
+		}
+		tz, err := ParseTzfileData(entry.ID, data, nil)
+		if err != nil || !hasZoneLocation(tz.Location) {
+			continue
+		}
+		locations[entry.ID] = tz.Location
+	}
+	tzdb.Locations = locations
+
+	return locations
+}
+
+// applyZoneLocation fills in location data from the database's tab files for
+// a zone that was loaded from a plain TZif file
+func applyZoneLocation(tz *TzInfo, id string, tzdb *TzDB) {
+	if tz == nil || tzdb == nil || tzdb.Locations == nil || hasZoneLocation(tz.Location) {
+		return
+	}
+	if loc, ok := tzdb.Locations[id]; ok {
+		tz.Location = loc
+	}
+}
+
+// TimezoneLocation returns the country code, coordinates and comments of a zone
+func TimezoneLocation(timezone string, tzdb *TzDB) (*TLocInfo, error) {
+	if tzdb == nil {
+		return nil, fmt.Errorf("timezone database is nil")
+	}
+	if !TimezoneIDIsValid(timezone, tzdb) {
+		return nil, fmt.Errorf("timezone '%s' not found%s", timezone, tzSuggestionHint(timezone, tzdb))
+	}
+
+	loc, ok := zoneLocations(tzdb)[timezone]
+	if !ok {
+		return nil, fmt.Errorf("no location information for timezone '%s'", timezone)
+	}
+	return &loc, nil
+}
+
+// TimezoneCountryName returns the ISO 3166 name for a two-letter country
+// code, or an empty string if the database has no iso3166.tab data
+func TimezoneCountryName(code string, tzdb *TzDB) string {
+	if tzdb == nil || tzdb.CountryNames == nil {
+		return ""
+	}
+	return tzdb.CountryNames[strings.ToUpper(code)]
+}
+
+// isCanonicalZone reports whether a zone is a canonical (non-backwards
+// compatible) identifier. Embedded databases carry a flag per zone; for
+// file-based databases a zone is canonical when it appears in the tab files.
+func isCanonicalZone(entry TzDBIndexEntry, tzdb *TzDB, locations map[string]TLocInfo) bool {
+	if entry.ID == "UTC" {
+		return true
+	}
+	if _, ok := tzdb.registered[entry.ID]; ok {
+		return true
+	}
+	if len(tzdb.Data) > 0 {
+		return entry.Pos+4 < len(tzdb.Data) && tzdb.Data[entry.Pos+4] == 1
+	}
+	if len(locations) == 0 {
+		return true
+	}
+	_, ok := locations[entry.ID]
+	return ok
+}
+
+// TimezoneIdentifiersListGroup returns the identifiers in the given groups,
+// like PHP's DateTimeZone::listIdentifiers(). With TIMELIB_TZ_GROUP_PER_COUNTRY
+// the zones of the two-letter country code are returned instead. Unless
+// TIMELIB_TZ_GROUP_ALL_WITH_BC is requested, backwards compatible aliases are
+// left out.
+func TimezoneIdentifiersListGroup(tzdb *TzDB, group int, country string) ([]string, error) {
+	if tzdb == nil {
+		return nil, fmt.Errorf("timezone database is nil")
+	}
+
+	if group == TIMELIB_TZ_GROUP_PER_COUNTRY {
+		if len(country) != 2 {
+			return nil, fmt.Errorf("a two-letter ISO 3166-1 compatible country code is expected")
+		}
+		return TimezoneIdentifiersForCountry(tzdb, country), nil
+	}
+
+	if group < 0 || group > TIMELIB_TZ_GROUP_ALL_WITH_BC {
+		return nil, fmt.Errorf("invalid timezone group %#x", group)
+	}
+
+	locations := zoneLocations(tzdb)
+	result := make([]string, 0)
+
+	for _, entry := range tzdb.Index {
+		if group != TIMELIB_TZ_GROUP_ALL_WITH_BC {
+			if !isCanonicalZone(entry, tzdb, locations) || !tzGroupMatches(entry.ID, group) {
+				continue
+			}
+		}
+		result = append(result, entry.ID)
+	}
+
+	sort.Strings(result)
+	return result, nil
+}
+
+// tzGroupMatches checks whether an identifier belongs to one of the groups
+func tzGroupMatches(id string, group int) bool {
+	if group&TIMELIB_TZ_GROUP_UTC != 0 && id == "UTC" {
+		return true
+	}
+	for _, g := range tzGroupPrefixes {
+		if group&g.Group != 0 && strings.HasPrefix(id, g.Prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// TimezoneIdentifiersForCountry returns the identifiers of all zones located
+// in the country with the given two-letter ISO 3166 code
+func TimezoneIdentifiersForCountry(tzdb *TzDB, country string) []string {
+	country = strings.ToUpper(country)
+	result := make([]string, 0)
+
+	for id, loc := range zoneLocations(tzdb) {
+		if string(loc.CountryCode[:2]) == country {
+			result = append(result, id)
+		}
+	}
+
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,197 @@
+package timelib
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestParseISO6709 tests conversion of tab file coordinates to decimal degrees
+func TestParseISO6709(t *testing.T) {
+	tests := []struct {
+		input    string
+		lat, lon float64
+		wantErr  bool
+	}{
+		{"+4230+00131", 42.5, 1.516666, false},
+		{"+404251-0740023", 40.714166, -74.006388, false},
+		{"-3352+15113", -33.866666, 151.216666, false},
+		{"+4230", 0, 0, true},
+		{"4230+00131", 0, 0, true},
+		{"+42x0+00131", 0, 0, true},
+		{"", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		lat, lon, err := parseISO6709(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseISO6709(%q): expected error", tt.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseISO6709(%q): unexpected error: %v", tt.input, err)
+			continue
+		}
+		if math.Abs(lat-tt.lat) > 1e-5 || math.Abs(lon-tt.lon) > 1e-5 {
+			t.Errorf("parseISO6709(%q) = %f, %f, want %f, %f", tt.input, lat, lon, tt.lat, tt.lon)
+		}
+	}
+}
+
+// TestParseZoneTab tests parsing of zone.tab and zone1970.tab contents
+func TestParseZoneTab(t *testing.T) {
+	input := "# comment\n" +
+		"US\t+404251-0740023\tAmerica/New_York\tEastern (most areas)\n" +
+		"CH,DE,LI\t+4723+00832\tEurope/Zurich\tBüsingen\n" +
+		"GB\t+513030-0000731\tEurope/London\n"
+
+	locations, err := ParseZoneTab(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseZoneTab failed: %v", err)
+	}
+	if len(locations) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(locations))
+	}
+
+	ny := locations["America/New_York"]
+	if string(ny.CountryCode[:2]) != "US" || ny.Comments != "Eastern (most areas)" {
+		t.Errorf("Unexpected New York entry: %+v", ny)
+	}
+	if zurich := locations["Europe/Zurich"]; string(zurich.CountryCode[:2]) != "CH" {
+		t.Errorf("Expected first country CH for Europe/Zurich, got %q", zurich.CountryCode[:2])
+	}
+	if london := locations["Europe/London"]; london.Comments != "" {
+		t.Errorf("Expected empty comment for Europe/London, got %q", london.Comments)
+	}
+
+	if _, err := ParseZoneTab(strings.NewReader("US\t+4042\n")); err == nil {
+		t.Error("Expected error for short line")
+	}
+}
+
+// TestZoneinfoDirLocations tests that ZoneinfoDir picks up the tab files
+func TestZoneinfoDirLocations(t *testing.T) {
+	dir := t.TempDir()
+
+	data, err := os.ReadFile(filepath.Join("tests", "files", "New_York_Slim"))
+	if err != nil {
+		t.Skipf("fixture not available: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "America"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"New_York", "Detroit"} {
+		if err := os.WriteFile(filepath.Join(dir, "America", name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string]string{
+		"zone.tab":    "US\t+404251-0740023\tAmerica/New_York\tEastern (most areas)\n",
+		"iso3166.tab": "# ISO 3166\nUS\tUnited States\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tzdb, err := ZoneinfoDir(dir)
+	if err != nil {
+		t.Fatalf("ZoneinfoDir failed: %v", err)
+	}
+
+	if got := TimezoneCountryName("us", tzdb); got != "United States" {
+		t.Errorf("TimezoneCountryName = %q, want United States", got)
+	}
+
+	tz, err := ParseTzfile("America/New_York", tzdb, nil)
+	if err != nil {
+		t.Fatalf("ParseTzfile failed: %v", err)
+	}
+	if string(tz.Location.CountryCode[:2]) != "US" || tz.Location.Comments != "Eastern (most areas)" {
+		t.Errorf("Location not applied: %+v", tz.Location)
+	}
+
+	// America/Detroit is not listed in zone.tab, so it is not canonical
+	ids, err := TimezoneIdentifiersListGroup(tzdb, TIMELIB_TZ_GROUP_ALL, "")
+	if err != nil {
+		t.Fatalf("TimezoneIdentifiersListGroup failed: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != "America/New_York" {
+		t.Errorf("Expected only America/New_York, got %v", ids)
+	}
+
+	ids, _ = TimezoneIdentifiersListGroup(tzdb, TIMELIB_TZ_GROUP_ALL_WITH_BC, "")
+	if len(ids) != 2 {
+		t.Errorf("Expected 2 identifiers with BC, got %v", ids)
+	}
+}
+
+// TestTimezoneIdentifiersBuiltin tests group and country listing on the builtin database
+func TestTimezoneIdentifiersBuiltin(t *testing.T) {
+	tzdb := BuiltinDB()
+
+	us := TimezoneIdentifiersForCountry(tzdb, "us")
+	if !slices.Contains(us, "America/New_York") || !slices.Contains(us, "Pacific/Honolulu") {
+		t.Errorf("US zones missing expected entries: %v", us)
+	}
+	if slices.Contains(us, "Europe/London") {
+		t.Error("US zones should not contain Europe/London")
+	}
+
+	europe, err := TimezoneIdentifiersListGroup(tzdb, TIMELIB_TZ_GROUP_EUROPE, "")
+	if err != nil {
+		t.Fatalf("TimezoneIdentifiersListGroup failed: %v", err)
+	}
+	for _, id := range europe {
+		if !strings.HasPrefix(id, "Europe/") {
+			t.Errorf("Unexpected identifier %q in Europe group", id)
+		}
+	}
+	if !slices.Contains(europe, "Europe/Amsterdam") {
+		t.Error("Europe group should contain Europe/Amsterdam")
+	}
+
+	all, _ := TimezoneIdentifiersListGroup(tzdb, TIMELIB_TZ_GROUP_ALL, "")
+	if slices.Contains(all, "US/Eastern") {
+		t.Error("ALL should not contain backwards compatible US/Eastern")
+	}
+	if !slices.Contains(all, "UTC") {
+		t.Error("ALL should contain UTC")
+	}
+	withBC, _ := TimezoneIdentifiersListGroup(tzdb, TIMELIB_TZ_GROUP_ALL_WITH_BC, "")
+	if !slices.Contains(withBC, "US/Eastern") {
+		t.Error("ALL_WITH_BC should contain US/Eastern")
+	}
+
+	nl, err := TimezoneIdentifiersListGroup(tzdb, TIMELIB_TZ_GROUP_PER_COUNTRY, "NL")
+	if err != nil || !slices.Contains(nl, "Europe/Amsterdam") {
+		t.Errorf("Expected Europe/Amsterdam for NL, got %v (%v)", nl, err)
+	}
+	if _, err := TimezoneIdentifiersListGroup(tzdb, TIMELIB_TZ_GROUP_PER_COUNTRY, "NLD"); err == nil {
+		t.Error("Expected error for three-letter country code")
+	}
+}
+
+// TestTimezoneLocation tests location lookup for a single zone
+func TestTimezoneLocation(t *testing.T) {
+	loc, err := TimezoneLocation("Europe/Amsterdam", BuiltinDB())
+	if err != nil {
+		t.Fatalf("TimezoneLocation failed: %v", err)
+	}
+	if string(loc.CountryCode[:2]) != "NL" {
+		t.Errorf("Expected NL, got %q", loc.CountryCode[:2])
+	}
+	if math.Abs(loc.Latitude-52.36) > 0.1 || math.Abs(loc.Longitude-4.9) > 0.1 {
+		t.Errorf("Unexpected coordinates %f, %f", loc.Latitude, loc.Longitude)
+	}
+
+	if _, err := TimezoneLocation("Europe/Amsterdm", BuiltinDB()); err == nil {
+		t.Error("Expected error for unknown timezone")
+	}
+}
@@ -0,0 +1,90 @@
+package timelib
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// earthRadiusKm is the mean Earth radius used for great-circle distances
+const earthRadiusKm = 6371.0088
+
+// TzDistance is a timezone together with its distance from a point
+type TzDistance struct {
+	ID         string   // Timezone identifier
+	DistanceKm float64  // Great-circle distance from the requested point in kilometres
+	Location   TLocInfo // Location of the zone's principal city
+}
+
+// greatCircleDistance returns the haversine distance in kilometres between two
+// points given in decimal degrees
+func greatCircleDistance(lat1, lon1, lat2, lon2 float64) float64 {
+	const rad = math.Pi / 180
+
+	dLat := (lat2 - lat1) * rad
+	dLon := (lon2 - lon1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// NearestTimezones returns the zones whose principal city is closest to the
+// given coordinates, nearest first. If country is not empty only zones in
+// that two-letter ISO 3166 country are considered. At most limit results are
+// returned; limit <= 0 means no limit.
+func NearestTimezones(latitude, longitude float64, country string, tzdb *TzDB, limit int) ([]TzDistance, error) {
+	if tzdb == nil {
+		return nil, fmt.Errorf("timezone database is nil")
+	}
+	if math.IsNaN(latitude) || latitude < -90 || latitude > 90 {
+		return nil, fmt.Errorf("latitude %f out of range", latitude)
+	}
+	if math.IsNaN(longitude) || longitude < -180 || longitude > 180 {
+		return nil, fmt.Errorf("longitude %f out of range", longitude)
+	}
+	if country != "" && len(country) != 2 {
+		return nil, fmt.Errorf("a two-letter ISO 3166-1 compatible country code is expected")
+	}
+	country = strings.ToUpper(country)
+
+	locations := zoneLocations(tzdb)
+	result := make([]TzDistance, 0, len(locations))
+
+	for id, loc := range locations {
+		if country != "" && string(loc.CountryCode[:2]) != country {
+			continue
+		}
+		result = append(result, TzDistance{
+			ID:         id,
+			DistanceKm: greatCircleDistance(latitude, longitude, loc.Latitude, loc.Longitude),
+			Location:   loc,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].DistanceKm != result[j].DistanceKm {
+			return result[i].DistanceKm < result[j].DistanceKm
+		}
+		return result[i].ID < result[j].ID
+	})
+
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// NearestTimezone returns the identifier of the zone closest to the given
+// coordinates, or an error if the database has no location data
+func NearestTimezone(latitude, longitude float64, country string, tzdb *TzDB) (string, error) {
+	result, err := NearestTimezones(latitude, longitude, country, tzdb, 1)
+	if err != nil {
+		return "", err
+	}
+	if len(result) == 0 {
+		return "", fmt.Errorf("no timezone with location information found")
+	}
+	return result[0].ID, nil
+}
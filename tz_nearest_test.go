@@ -0,0 +1,83 @@
+package timelib
+
+import (
+	"math"
+	"testing"
+)
+
+// TestGreatCircleDistance tests the haversine distance against known values
+func TestGreatCircleDistance(t *testing.T) {
+	// London to Paris is about 344 km
+	d := greatCircleDistance(51.5074, -0.1278, 48.8566, 2.3522)
+	if math.Abs(d-344) > 5 {
+		t.Errorf("London-Paris distance = %f, want ~344", d)
+	}
+
+	if d := greatCircleDistance(10, 20, 10, 20); d != 0 {
+		t.Errorf("Distance to self = %f, want 0", d)
+	}
+
+	// Antipodal points are half the circumference apart
+	if d := greatCircleDistance(0, 0, 0, 180); math.Abs(d-math.Pi*earthRadiusKm) > 1 {
+		t.Errorf("Antipodal distance = %f, want %f", d, math.Pi*earthRadiusKm)
+	}
+}
+
+// TestNearestTimezones tests nearest zone lookup on the builtin database
+func TestNearestTimezones(t *testing.T) {
+	tests := []struct {
+		name     string
+		lat, lon float64
+		country  string
+		expect   string
+	}{
+		{"Manhattan", 40.75, -73.99, "", "America/New_York"},
+		{"Rotterdam", 51.92, 4.48, "", "Europe/Amsterdam"},
+		{"Sydney suburb", -33.80, 151.0, "", "Australia/Sydney"},
+		{"Evanston", 42.05, -87.69, "US", "America/Chicago"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NearestTimezone(tt.lat, tt.lon, tt.country, BuiltinDB())
+			if err != nil {
+				t.Fatalf("NearestTimezone failed: %v", err)
+			}
+			if got != tt.expect {
+				t.Errorf("NearestTimezone(%f, %f, %q) = %q, want %q", tt.lat, tt.lon, tt.country, got, tt.expect)
+			}
+		})
+	}
+}
+
+// TestNearestTimezonesRanking tests ordering, limit and country filtering
+func TestNearestTimezonesRanking(t *testing.T) {
+	result, err := NearestTimezones(52.0, 13.0, "de", BuiltinDB(), 3)
+	if err != nil {
+		t.Fatalf("NearestTimezones failed: %v", err)
+	}
+	if len(result) == 0 || len(result) > 3 {
+		t.Fatalf("Expected 1 to 3 results, got %d", len(result))
+	}
+	for i, r := range result {
+		if string(r.Location.CountryCode[:2]) != "DE" {
+			t.Errorf("Result %s is not in DE", r.ID)
+		}
+		if i > 0 && r.DistanceKm < result[i-1].DistanceKm {
+			t.Errorf("Results not sorted by distance: %v", result)
+		}
+	}
+	if result[0].ID != "Europe/Berlin" {
+		t.Errorf("Expected Europe/Berlin first, got %s", result[0].ID)
+	}
+
+	if _, err := NearestTimezones(91, 0, "", BuiltinDB(), 1); err == nil {
+		t.Error("Expected error for latitude out of range")
+	}
+	if _, err := NearestTimezones(0, -181, "", BuiltinDB(), 1); err == nil {
+		t.Error("Expected error for longitude out of range")
+	}
+	if _, err := NearestTimezone(0, 0, "ZZ", BuiltinDB()); err == nil {
+		t.Error("Expected error for country without zones")
+	}
+}
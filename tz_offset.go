@@ -11,8 +11,11 @@ func GetOffsetInfo(ts int64, tzi *TzInfo) (*TTInfo, int64, error) {
 		return nil, 0, errors.New("no timezone info")
 	}
 
-	// If no transitions, use first type
+	// If no transitions, use POSIX rules or the first type
 	if len(tzi.Trans) == 0 {
+		if tzi.PosixInfo != nil {
+			return getPosixOffsetInfo(ts, tzi)
+		}
 		if len(tzi.Type) > 0 {
 			return &tzi.Type[0], ts, nil
 		}
@@ -0,0 +1,162 @@
+package timelib
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TzSuggestion is a candidate timezone identifier for an unknown name
+type TzSuggestion struct {
+	ID       string // Timezone identifier from the database index
+	Distance int    // Edit distance between the normalized name and the identifier (0 = exact)
+	CityOnly bool   // The name matched the city part of the identifier (e.g. "New_York")
+}
+
+// normalizeTzName lowercases a timezone name and folds the separators that
+// users commonly mix up (space, hyphen, underscore) into a single underscore
+func normalizeTzName(name string) string {
+	var b strings.Builder
+	lastSep := false
+
+	for _, c := range strings.ToLower(strings.TrimSpace(name)) {
+		if c == ' ' || c == '-' || c == '_' || c == '\t' {
+			if !lastSep {
+				b.WriteByte('_')
+			}
+			lastSep = true
+			continue
+		}
+		b.WriteRune(c)
+		lastSep = false
+	}
+
+	return b.String()
+}
+
+// levenshteinDistance returns the edit distance between two strings
+func levenshteinDistance(a, b string) int {
+	ra := []rune(a)
+	rb := []rune(b)
+
+	if len(ra) == 0 {
+		return len(rb)
+	}
+	if len(rb) == 0 {
+		return len(ra)
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// SuggestTimezoneIDs returns identifiers from the database that are likely
+// meant by an unknown timezone name, best match first. Matching is tolerant
+// of case and separators ("new york" finds "America/New_York") and also
+// considers the city part of each identifier on its own. If tzdb is nil the
+// builtin database is used. At most limit suggestions are returned; limit <= 0
+// means no limit.
+func SuggestTimezoneIDs(name string, tzdb *TzDB, limit int) []TzSuggestion {
+	if tzdb == nil {
+		tzdb = BuiltinDB()
+	}
+
+	query := normalizeTzName(name)
+	if query == "" {
+		return nil
+	}
+
+	// Allow roughly one typo per three characters, but always at least one
+	maxDistance := len(query) / 3
+	if maxDistance < 1 {
+		maxDistance = 1
+	}
+	hasArea := strings.Contains(query, "/")
+
+	type candidate struct {
+		TzSuggestion
+		fullDistance int
+	}
+	candidates := make([]candidate, 0)
+
+	for _, entry := range tzdb.Index {
+		id := normalizeTzName(entry.ID)
+		full := levenshteinDistance(query, id)
+
+		best := full
+		cityOnly := false
+		if !hasArea {
+			if slash := strings.LastIndexByte(id, '/'); slash >= 0 {
+				if city := levenshteinDistance(query, id[slash+1:]); city < best {
+					best = city
+					cityOnly = true
+				}
+			}
+		}
+
+		if best > maxDistance {
+			continue
+		}
+
+		candidates = append(candidates, candidate{
+			TzSuggestion: TzSuggestion{ID: entry.ID, Distance: best, CityOnly: cityOnly},
+			fullDistance: full,
+		})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Distance != candidates[j].Distance {
+			return candidates[i].Distance < candidates[j].Distance
+		}
+		if candidates[i].fullDistance != candidates[j].fullDistance {
+			return candidates[i].fullDistance < candidates[j].fullDistance
+		}
+		return candidates[i].ID < candidates[j].ID
+	})
+
+	if limit > 0 && len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	result := make([]TzSuggestion, len(candidates))
+	for i := range candidates {
+		result[i] = candidates[i].TzSuggestion
+	}
+	return result
+}
+
+// SuggestTimezoneID returns the best suggestion for an unknown timezone name,
+// or an empty string if nothing in the database is close enough
+func SuggestTimezoneID(name string, tzdb *TzDB) string {
+	suggestions := SuggestTimezoneIDs(name, tzdb, 1)
+	if len(suggestions) == 0 {
+		return ""
+	}
+	return suggestions[0].ID
+}
+
+// tzSuggestionHint formats the top suggestion for appending to an error message
+func tzSuggestionHint(name string, tzdb *TzDB) string {
+	suggestion := SuggestTimezoneID(name, tzdb)
+	if suggestion == "" || suggestion == name {
+		return ""
+	}
+	return fmt.Sprintf("; did you mean '%s'?", suggestion)
+}
@@ -0,0 +1,91 @@
+package timelib
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestSuggestTimezoneIDs tests ranking of suggestions for unknown identifiers
+func TestSuggestTimezoneIDs(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		expect string
+	}{
+		{"case insensitive", "america/new_york", "America/New_York"},
+		{"spaces for underscores", "new york", "America/New_York"},
+		{"city only", "Amsterdam", "Europe/Amsterdam"},
+		{"typo in city", "America/New_Yrok", "America/New_York"},
+		{"typo in area", "Europa/London", "Europe/London"},
+		{"hyphen for underscore", "Los-Angeles", "America/Los_Angeles"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SuggestTimezoneID(tt.input, BuiltinDB())
+			if got != tt.expect {
+				t.Errorf("SuggestTimezoneID(%q) = %q, want %q", tt.input, got, tt.expect)
+			}
+		})
+	}
+}
+
+// TestSuggestTimezoneIDsNoMatch tests that unrelated names yield no suggestions
+func TestSuggestTimezoneIDsNoMatch(t *testing.T) {
+	if got := SuggestTimezoneIDs("Xyzzyplugh/Qwerty", BuiltinDB(), 5); len(got) != 0 {
+		t.Errorf("Expected no suggestions, got %v", got)
+	}
+	if got := SuggestTimezoneIDs("", BuiltinDB(), 5); got != nil {
+		t.Errorf("Expected nil for empty name, got %v", got)
+	}
+}
+
+// TestSuggestTimezoneIDsLimit tests ordering and the result limit
+func TestSuggestTimezoneIDsLimit(t *testing.T) {
+	got := SuggestTimezoneIDs("Europe/Londn", BuiltinDB(), 2)
+	if len(got) == 0 || len(got) > 2 {
+		t.Fatalf("Expected 1 or 2 suggestions, got %d", len(got))
+	}
+	if got[0].ID != "Europe/London" || got[0].Distance != 1 {
+		t.Errorf("Expected Europe/London at distance 1, got %+v", got[0])
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i].Distance < got[i-1].Distance {
+			t.Errorf("Suggestions not sorted by distance: %v", got)
+		}
+	}
+}
+
+// TestParseTzfileSuggestion tests that ParseTzfile errors mention the best match
+func TestParseTzfileSuggestion(t *testing.T) {
+	var errorCode int
+	_, err := ParseTzfile("Europe/Amsterdm", BuiltinDB(), &errorCode)
+	if err == nil {
+		t.Fatal("Expected error for unknown timezone")
+	}
+	if errorCode != TIMELIB_ERROR_NO_SUCH_TIMEZONE {
+		t.Errorf("Expected error code %d, got %d", TIMELIB_ERROR_NO_SUCH_TIMEZONE, errorCode)
+	}
+	if !strings.Contains(err.Error(), "did you mean 'Europe/Amsterdam'") {
+		t.Errorf("Expected suggestion in error, got %q", err.Error())
+	}
+}
+
+// TestParseDateStringTzSuggestion tests that parser errors carry a suggestion
+func TestParseDateStringTzSuggestion(t *testing.T) {
+	_, errors, _ := ParseDateString("2005-07-14 22:30:41 America/Los_Angelos", BuiltinDB(), ParseTzfile)
+	if errors.ErrorCount != 1 {
+		t.Fatalf("Expected 1 error, got %d", errors.ErrorCount)
+	}
+
+	msg := errors.ErrorMessages[0]
+	if msg.ErrorCode != TIMELIB_ERR_TZID_NOT_FOUND {
+		t.Errorf("Expected TZID not found error, got %#x", msg.ErrorCode)
+	}
+	if !strings.HasPrefix(msg.Message, "The timezone could not be found in the database") {
+		t.Errorf("Unexpected message: %q", msg.Message)
+	}
+	if !strings.Contains(msg.Message, "'America/Los_Angeles'") {
+		t.Errorf("Expected suggestion in message, got %q", msg.Message)
+	}
+}
@@ -0,0 +1,254 @@
+package timelib
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// windowsZoneEntry is a single mapZone element of the CLDR windowsZones data
+type windowsZoneEntry struct {
+	Windows   string // Windows zone name, e.g. "W. Europe Standard Time"
+	Territory string // ISO 3166 territory, "001" for the default or "ZZ" for Etc zones
+	IDs       string // Space separated IANA identifiers, preferred one first
+}
+
+// windowsZoneAliases maps current IANA identifiers to the older spelling that
+// CLDR still uses in windowsZoneMap
+var windowsZoneAliases = map[string]string{
+	"America/Argentina/Buenos_Aires": "America/Buenos_Aires",
+	"America/Argentina/Catamarca":    "America/Catamarca",
+	"America/Argentina/Cordoba":      "America/Cordoba",
+	"America/Argentina/Jujuy":        "America/Jujuy",
+	"America/Argentina/Mendoza":      "America/Mendoza",
+	"America/Atikokan":               "America/Coral_Harbour",
+	"America/Indiana/Indianapolis":   "America/Indianapolis",
+	"America/Kentucky/Louisville":    "America/Louisville",
+	"America/Nuuk":                   "America/Godthab",
+	"Africa/Asmara":                  "Africa/Asmera",
+	"Asia/Ho_Chi_Minh":               "Asia/Saigon",
+	"Asia/Kathmandu":                 "Asia/Katmandu",
+	"Asia/Kolkata":                   "Asia/Calcutta",
+	"Asia/Yangon":                    "Asia/Rangoon",
+	"Atlantic/Faroe":                 "Atlantic/Faeroe",
+	"Europe/Kyiv":                    "Europe/Kiev",
+	"Pacific/Chuuk":                  "Pacific/Truk",
+	"Pacific/Kanton":                 "Pacific/Enderbury",
+	"Pacific/Pohnpei":                "Pacific/Ponape",
+	"UTC":                            "Etc/UTC",
+}
+
+// windowsZoneIndex holds the lookup tables derived from windowsZoneMap
+type windowsZoneIndex struct {
+	byWindows map[string]map[string][]string // lowercased Windows name -> territory -> IANA identifiers
+	names     map[string]string              // lowercased Windows name -> Windows name
+	byIANA    map[string]windowsZoneEntry    // IANA identifier -> Windows name and territory
+	sorted    []string                       // Windows names, longest first
+}
+
+var (
+	windowsZonesOnce sync.Once
+	windowsZones     *windowsZoneIndex
+)
+
+// getWindowsZones builds the Windows zone lookup tables on first use
+func getWindowsZones() *windowsZoneIndex {
+	windowsZonesOnce.Do(func() {
+		idx := &windowsZoneIndex{
+			byWindows: make(map[string]map[string][]string),
+			names:     make(map[string]string),
+			byIANA:    make(map[string]windowsZoneEntry),
+		}
+
+		for _, entry := range windowsZoneMap {
+			key := strings.ToLower(entry.Windows)
+			if idx.byWindows[key] == nil {
+				idx.byWindows[key] = make(map[string][]string)
+				idx.names[key] = entry.Windows
+				idx.sorted = append(idx.sorted, entry.Windows)
+			}
+
+			ids := strings.Fields(entry.IDs)
+			idx.byWindows[key][entry.Territory] = ids
+
+			// Prefer the territory specific entry over the "001" default
+			for _, id := range ids {
+				if existing, ok := idx.byIANA[id]; !ok || existing.Territory == "001" {
+					idx.byIANA[id] = windowsZoneEntry{Windows: entry.Windows, Territory: entry.Territory, IDs: id}
+				}
+			}
+		}
+
+		sort.SliceStable(idx.sorted, func(i, j int) bool {
+			return len(idx.sorted[i]) > len(idx.sorted[j])
+		})
+		windowsZones = idx
+	})
+
+	return windowsZones
+}
+
+// IsWindowsZoneName checks whether a name is a known Windows zone name
+func IsWindowsZoneName(name string) bool {
+	_, ok := getWindowsZones().byWindows[strings.ToLower(name)]
+	return ok
+}
+
+// WindowsZoneNames returns all known Windows zone names in sorted order
+func WindowsZoneNames() []string {
+	idx := getWindowsZones()
+	names := make([]string, 0, len(idx.names))
+	for _, name := range idx.names {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WindowsToIANA converts a Windows zone name to an IANA identifier. The
+// territory is a two-letter ISO 3166 code; an empty or unknown territory
+// selects the default zone for the Windows name. If tzdb is not nil, the
+// first identifier that exists in the database is returned.
+func WindowsToIANA(windowsName, territory string, tzdb *TzDB) (string, error) {
+	territories, ok := getWindowsZones().byWindows[strings.ToLower(strings.TrimSpace(windowsName))]
+	if !ok {
+		return "", fmt.Errorf("unknown Windows timezone '%s'", windowsName)
+	}
+
+	ids, ok := territories[strings.ToUpper(territory)]
+	if !ok || territory == "" {
+		ids = territories["001"]
+	}
+
+	for _, id := range ids {
+		if tzdb == nil || TimezoneIDIsValid(id, tzdb) {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("no timezone for Windows timezone '%s' found in the database", windowsName)
+}
+
+// IANAToWindows converts an IANA identifier to its Windows zone name and the
+// territory of the mapping ("001" when the identifier is the default zone of
+// the Windows name and has no territory specific entry)
+func IANAToWindows(timezone string) (windowsName, territory string, err error) {
+	idx := getWindowsZones()
+
+	entry, ok := idx.byIANA[timezone]
+	if !ok {
+		if alias, found := windowsZoneAliases[timezone]; found {
+			entry, ok = idx.byIANA[alias]
+		}
+	}
+	if !ok {
+		return "", "", fmt.Errorf("no Windows timezone for '%s'", timezone)
+	}
+	return entry.Windows, entry.Territory, nil
+}
+
+// WithWindowsZoneNames returns a database that consults tzdb (BuiltinDB()
+// if nil) and also accepts Windows zone names, such as "Eastern Standard
+// Time", when loading zones and parsing date strings. tzdb itself is not
+// changed, so other users of a shared database are not affected.
+func WithWindowsZoneNames(tzdb *TzDB) *TzDB {
+	if tzdb == nil {
+		tzdb = BuiltinDB()
+	}
+	wrapper := NewLayeredTzDB(tzdb)
+	wrapper.acceptWindowsNames = true
+	return wrapper
+}
+
+// resolveWindowsZoneName returns the IANA identifier for a Windows zone name
+// if the database accepts Windows names and the name is not already a valid
+// identifier, or the name itself otherwise
+func resolveWindowsZoneName(name string, tzdb *TzDB) string {
+	if tzdb == nil || !tzdb.acceptWindowsNames || TimezoneIDIsValid(name, tzdb) {
+		return name
+	}
+	if id, err := WindowsToIANA(name, "", tzdb); err == nil {
+		return id
+	}
+	return name
+}
+
+// windowsZoneReplacement records where replaceWindowsZoneNames put an
+// identifier in place of a Windows name
+type windowsZoneReplacement struct {
+	pos     int // position of the identifier in the new string
+	idLen   int
+	nameLen int
+}
+
+// replaceWindowsZoneNames replaces multi-word Windows zone names in a date
+// string with their IANA identifier, so that the scanner sees a single zone
+// token. It also returns the replacements made, so that error positions can
+// be mapped back to str with windowsZoneOriginalPosition.
+func replaceWindowsZoneNames(str string, tzdb *TzDB) (string, []windowsZoneReplacement) {
+	idx := getWindowsZones()
+	var b strings.Builder
+	var replacements []windowsZoneReplacement
+
+	for i := 0; i < len(str); {
+		if i > 0 && isWindowsNameChar(rune(str[i-1])) {
+			b.WriteByte(str[i])
+			i++
+			continue
+		}
+
+		replaced := false
+		for _, name := range idx.sorted {
+			end := i + len(name)
+			if !strings.Contains(name, " ") || end > len(str) || !strings.EqualFold(str[i:end], name) {
+				continue
+			}
+			if end < len(str) && isWindowsNameChar(rune(str[end])) {
+				continue
+			}
+
+			id, err := WindowsToIANA(name, "", tzdb)
+			if err != nil {
+				continue
+			}
+			replacements = append(replacements, windowsZoneReplacement{b.Len(), len(id), len(name)})
+			b.WriteString(id)
+			i = end
+			replaced = true
+			break
+		}
+
+		if !replaced {
+			b.WriteByte(str[i])
+			i++
+		}
+	}
+
+	if len(replacements) == 0 {
+		return str, nil
+	}
+	return b.String(), replacements
+}
+
+// windowsZoneOriginalPosition maps a position in a string returned by
+// replaceWindowsZoneNames back to the string it was given. Positions inside
+// an identifier map to the start of the Windows name it replaced.
+func windowsZoneOriginalPosition(pos int, replacements []windowsZoneReplacement) int {
+	shift := 0
+	for _, r := range replacements {
+		if pos < r.pos {
+			break
+		}
+		if pos < r.pos+r.idLen {
+			return r.pos + shift
+		}
+		shift += r.nameLen - r.idLen
+	}
+	return pos + shift
+}
+
+// isWindowsNameChar reports whether a character can continue a word
+func isWindowsNameChar(c rune) bool {
+	return unicode.IsLetter(c) || unicode.IsDigit(c)
+}
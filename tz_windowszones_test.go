@@ -0,0 +1,139 @@
+package timelib
+
+import (
+	"testing"
+)
+
+// TestWindowsToIANA tests Windows to IANA conversion with and without territory
+func TestWindowsToIANA(t *testing.T) {
+	tests := []struct {
+		windows   string
+		territory string
+		expect    string
+	}{
+		{"Eastern Standard Time", "", "America/New_York"},
+		{"Eastern Standard Time", "CA", "America/Toronto"},
+		{"Eastern Standard Time", "us", "America/New_York"},
+		{"W. Europe Standard Time", "", "Europe/Berlin"},
+		{"W. Europe Standard Time", "NL", "Europe/Amsterdam"},
+		{"W. Europe Standard Time", "FR", "Europe/Berlin"},
+		{"eastern standard time (mexico)", "", "America/Cancun"},
+		{"UTC", "", "Etc/UTC"},
+		{"Dateline Standard Time", "ZZ", "Etc/GMT+12"},
+	}
+
+	for _, tt := range tests {
+		got, err := WindowsToIANA(tt.windows, tt.territory, BuiltinDB())
+		if err != nil {
+			t.Errorf("WindowsToIANA(%q, %q): unexpected error: %v", tt.windows, tt.territory, err)
+			continue
+		}
+		if got != tt.expect {
+			t.Errorf("WindowsToIANA(%q, %q) = %q, want %q", tt.windows, tt.territory, got, tt.expect)
+		}
+	}
+
+	if _, err := WindowsToIANA("Mars Standard Time", "", nil); err == nil {
+		t.Error("Expected error for unknown Windows zone")
+	}
+}
+
+// TestIANAToWindows tests IANA to Windows conversion including alias names
+func TestIANAToWindows(t *testing.T) {
+	tests := []struct {
+		timezone  string
+		windows   string
+		territory string
+	}{
+		{"America/New_York", "Eastern Standard Time", "US"},
+		{"America/Detroit", "Eastern Standard Time", "US"},
+		{"Europe/Amsterdam", "W. Europe Standard Time", "NL"},
+		{"Asia/Kolkata", "India Standard Time", "IN"},
+		{"Europe/Kyiv", "FLE Standard Time", "UA"},
+		{"Etc/GMT+12", "Dateline Standard Time", "ZZ"},
+	}
+
+	for _, tt := range tests {
+		windows, territory, err := IANAToWindows(tt.timezone)
+		if err != nil {
+			t.Errorf("IANAToWindows(%q): unexpected error: %v", tt.timezone, err)
+			continue
+		}
+		if windows != tt.windows || territory != tt.territory {
+			t.Errorf("IANAToWindows(%q) = %q, %q, want %q, %q", tt.timezone, windows, territory, tt.windows, tt.territory)
+		}
+	}
+
+	if _, _, err := IANAToWindows("Mars/Olympus_Mons"); err == nil {
+		t.Error("Expected error for unknown identifier")
+	}
+}
+
+// TestWindowsZoneMapValid checks that all mapped identifiers exist in the builtin database
+func TestWindowsZoneMapValid(t *testing.T) {
+	for _, name := range WindowsZoneNames() {
+		if _, err := WindowsToIANA(name, "", BuiltinDB()); err != nil {
+			t.Errorf("WindowsToIANA(%q): %v", name, err)
+		}
+	}
+	for id := range getWindowsZones().byIANA {
+		if !TimezoneIDIsValid(id, BuiltinDB()) {
+			t.Errorf("Mapped identifier %q not in builtin database", id)
+		}
+	}
+}
+
+// TestParseWindowsZoneNames tests that the parser accepts Windows names when enabled
+func TestParseWindowsZoneNames(t *testing.T) {
+	builtin := BuiltinDB()
+	tzdb := WithWindowsZoneNames(builtin)
+
+	tests := []struct {
+		input  string
+		expect string
+	}{
+		{"2024-01-15 10:00 Eastern Standard Time", "America/New_York"},
+		{"2024-01-15 10:00 W. Europe Standard Time", "Europe/Berlin"},
+		{"2024-01-15 10:00 Pacific Standard Time (Mexico)", "America/Tijuana"},
+		{"2024-01-15 10:00 Europe/Paris", "Europe/Paris"},
+	}
+
+	for _, tt := range tests {
+		tm, errors, err := ParseDateString(tt.input, tzdb, ParseTzfile)
+		if err != nil || errors.ErrorCount > 0 {
+			t.Errorf("ParseDateString(%q): unexpected errors: %v %+v", tt.input, err, errors.ErrorMessages)
+			continue
+		}
+		if tm.ZoneType != TIMELIB_ZONETYPE_ID || tm.TzInfo == nil || tm.TzInfo.Name != tt.expect {
+			t.Errorf("ParseDateString(%q): expected zone %s, got type %d %+v", tt.input, tt.expect, tm.ZoneType, tm.TzInfo)
+		}
+		if tm.H != 10 || tm.D != 15 {
+			t.Errorf("ParseDateString(%q): unexpected date/time %d-%d %d:%d", tt.input, tm.Y, tm.D, tm.H, tm.I)
+		}
+	}
+
+	// Error positions refer to the string as given, whether the identifier
+	// is shorter or longer than the Windows name
+	for _, input := range []string{"2024-01-15 Tokyo Standard Time 10:00 !", "2024-01-15 Haiti Standard Time 10:00 !"} {
+		_, errors, _ := ParseDateString(input, tzdb, ParseTzfile)
+		if errors.ErrorCount != 1 || errors.ErrorMessages[0].Position != 37 || errors.ErrorMessages[0].Character != '!' {
+			t.Errorf("ParseDateString(%q): expected an error at 37, got %+v", input, errors.ErrorMessages)
+		}
+	}
+
+	// Without the wrapper the Windows name is not understood, and the
+	// wrapped database is unchanged
+	_, errors, _ := ParseDateString("2024-01-15 10:00 Eastern Standard Time", builtin, ParseTzfile)
+	if errors.ErrorCount == 0 {
+		t.Error("Expected errors when Windows names are not enabled")
+	}
+	if _, err := ParseTzfile("Tokyo Standard Time", builtin, nil); err == nil {
+		t.Error("Expected the builtin database to reject Windows names")
+	}
+
+	// ParseTzfile resolves Windows names directly
+	tz, err := ParseTzfile("Tokyo Standard Time", tzdb, nil)
+	if err != nil || tz.Name != "Asia/Tokyo" {
+		t.Errorf("ParseTzfile(Tokyo Standard Time) = %v, %v", tz, err)
+	}
+}
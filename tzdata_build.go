@@ -0,0 +1,94 @@
+package timelib
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Names of the files that make up the embedded timezone database
+const (
+	tzdataIndexFile   = "tzdata_index.bin"
+	tzdataDataFile    = "tzdata_data.bin"
+	tzdataVersionFile = "tzdata_version.txt"
+)
+
+// EncodeTzDB serializes a timezone database into the index and data blobs
+// that are embedded as the builtin database. Every index entry is written as
+// the name, a newline, the 4-byte big-endian position in the data blob and
+// another newline. Zones are stored in the PHP variant of the TZif format;
+// entries that are plain TZif files (e.g. from ZoneinfoDir) are converted.
+func EncodeTzDB(tzdb *TzDB) (index []byte, data []byte, err error) {
+	if tzdb == nil {
+		return nil, nil, fmt.Errorf("timezone database is nil")
+	}
+
+	entries := make([]TzDBIndexEntry, len(tzdb.Index))
+	copy(entries, tzdb.Index)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return strings.ToLower(entries[i].ID) < strings.ToLower(entries[j].ID)
+	})
+
+	locations := zoneLocations(tzdb)
+
+	var indexBuf, dataBuf bytes.Buffer
+	for _, entry := range entries {
+		blob, _, err := LoadTzFileFromDB(entry.ID, tzdb)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if !bytes.HasPrefix(blob, []byte("PHP")) {
+			tz, err := ParseTzfileData(entry.ID, blob, nil)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: %v", entry.ID, err)
+			}
+			applyZoneLocation(tz, entry.ID, tzdb)
+
+			blob, err = EncodePHPTzfile(tz, isCanonicalZone(entry, tzdb, locations))
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: %v", entry.ID, err)
+			}
+		}
+
+		if dataBuf.Len() > 0xffffffff-len(blob) {
+			return nil, nil, fmt.Errorf("timezone data exceeds 4GB at %s", entry.ID)
+		}
+
+		indexBuf.WriteString(entry.ID)
+		indexBuf.WriteByte('\n')
+		binary.Write(&indexBuf, binary.BigEndian, uint32(dataBuf.Len()))
+		indexBuf.WriteByte('\n')
+		dataBuf.Write(blob)
+	}
+
+	return indexBuf.Bytes(), dataBuf.Bytes(), nil
+}
+
+// WriteTzDBFiles writes the index, data and version files of the builtin
+// database format into a directory
+func WriteTzDBFiles(directory string, tzdb *TzDB) error {
+	index, data, err := EncodeTzDB(tzdb)
+	if err != nil {
+		return err
+	}
+
+	files := []struct {
+		name    string
+		content []byte
+	}{
+		{tzdataIndexFile, index},
+		{tzdataDataFile, data},
+		{tzdataVersionFile, []byte(tzdb.Version + "\n")},
+	}
+	for _, f := range files {
+		if err := os.WriteFile(filepath.Join(directory, f.name), f.content, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
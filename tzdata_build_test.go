@@ -0,0 +1,94 @@
+package timelib
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestEncodeTzDBBuiltin tests that re-encoding the builtin database
+// reproduces the embedded files
+func TestEncodeTzDBBuiltin(t *testing.T) {
+	index, data, err := EncodeTzDB(BuiltinDB())
+	if err != nil {
+		t.Fatalf("EncodeTzDB failed: %v", err)
+	}
+	if !bytes.Equal(index, builtinTzIndexRaw) {
+		t.Error("Index differs from the embedded index")
+	}
+	if !bytes.Equal(data, builtinTzData) {
+		t.Error("Data differs from the embedded data")
+	}
+	if BuiltinDB().Version != "2025.2" {
+		t.Errorf("Expected builtin version 2025.2, got %q", BuiltinDB().Version)
+	}
+}
+
+// TestWriteTzDBFilesZoneinfo tests building the database files from a
+// zoneinfo directory
+func TestWriteTzDBFilesZoneinfo(t *testing.T) {
+	dir := t.TempDir()
+
+	fixture, err := os.ReadFile(filepath.Join("tests", "files", "New_York_Slim"))
+	if err != nil {
+		t.Skipf("fixture not available: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "America"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{
+		"America/New_York": fixture,
+		"America/Detroit":  fixture,
+		"zone.tab":         []byte("US\t+404251-0740023\tAmerica/New_York\tEastern (most areas)\n"),
+		"+VERSION":         []byte("2099z\n"),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tzdb, err := ZoneinfoDir(dir)
+	if err != nil {
+		t.Fatalf("ZoneinfoDir failed: %v", err)
+	}
+	if tzdb.Version != "2099z" {
+		t.Errorf("Expected version 2099z, got %q", tzdb.Version)
+	}
+
+	out := t.TempDir()
+	if err := WriteTzDBFiles(out, tzdb); err != nil {
+		t.Fatalf("WriteTzDBFiles failed: %v", err)
+	}
+
+	index, _ := os.ReadFile(filepath.Join(out, tzdataIndexFile))
+	data, _ := os.ReadFile(filepath.Join(out, tzdataDataFile))
+	version, _ := os.ReadFile(filepath.Join(out, tzdataVersionFile))
+	if string(version) != "2099z\n" {
+		t.Errorf("Unexpected version file %q", version)
+	}
+
+	built := &TzDB{Version: "2099z", Index: parseTzDBIndex(index), Data: data}
+	built.IndexSize = len(built.Index)
+	if built.IndexSize != 2 || built.Index[0].ID != "America/Detroit" {
+		t.Fatalf("Unexpected index %v", built.Index)
+	}
+
+	want, err := ParseTzfile("America/New_York", tzdb, nil)
+	if err != nil {
+		t.Fatalf("ParseTzfile failed: %v", err)
+	}
+	got, err := ParseTzfile("America/New_York", built, nil)
+	if err != nil {
+		t.Fatalf("ParseTzfile on built database failed: %v", err)
+	}
+	if got.Bc != 1 || string(got.Location.CountryCode[:2]) != "US" || got.Location.Comments != "Eastern (most areas)" {
+		t.Errorf("Metadata not preserved: bc=%d location=%+v", got.Bc, got.Location)
+	}
+	compareTzOffsets(t, "America/New_York", want, got, tzSampleInstants(want))
+
+	if detroit, err := ParseTzfile("America/Detroit", built, nil); err != nil || detroit.Bc != 0 {
+		t.Errorf("Expected America/Detroit to be backwards compatible (%v)", err)
+	}
+}
@@ -14,10 +14,17 @@ var builtinTzData []byte
 //go:embed tzdata_index.bin
 var builtinTzIndexRaw []byte
 
+//go:embed tzdata_version.txt
+var builtinTzVersion string
+
 // parseBuiltinIndex parses the timezone index from the embedded data
 func parseBuiltinIndex() []TzDBIndexEntry {
+	return parseTzDBIndex(builtinTzIndexRaw)
+}
+
+// parseTzDBIndex parses an index blob as written by EncodeTzDB
+func parseTzDBIndex(data []byte) []TzDBIndexEntry {
 	entries := make([]TzDBIndexEntry, 0, 598)
-	data := builtinTzIndexRaw
 	pos := 0
 
 	for pos < len(data) {
@@ -66,7 +73,7 @@ func initBuiltinTzDB() {
 	index := parseBuiltinIndex()
 
 	builtinTzDB = &TzDB{
-		Version:   "2025.2",
+		Version:   strings.TrimSpace(builtinTzVersion),
 		IndexSize: len(index),
 		Index:     index,
 		Data:      builtinTzData,
@@ -1,15 +1,31 @@
 package timelib
 
 import (
+	"archive/zip"
+	"bytes"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 )
 
+// ZoneinfoDir flags
+const (
+	TIMELIB_ZONEINFO_INCLUDE_RIGHT = 0x01 // Also index the leap second aware "right/" tree
+)
+
 // ZoneinfoDir loads timezone database from a directory
 func ZoneinfoDir(directory string) (*TzDB, error) {
+	return ZoneinfoDirWithFlags(directory, 0)
+}
+
+// ZoneinfoDirWithFlags loads timezone database from a directory. With
+// TIMELIB_ZONEINFO_INCLUDE_RIGHT the zones under "right/" are indexed as
+// "right/<zone>", which carry leap second records.
+func ZoneinfoDirWithFlags(directory string, flags int) (*TzDB, error) {
 	if directory == "" {
 		return nil, fmt.Errorf("directory cannot be empty")
 	}
@@ -30,56 +46,71 @@ func ZoneinfoDir(directory string) (*TzDB, error) {
 		return nil, fmt.Errorf("cannot get absolute path for %s: %v", directory, err)
 	}
 
+	tzdb, err := ZoneinfoFSWithFlags(os.DirFS(absDir), flags)
+	if err != nil {
+		return nil, err
+	}
+	tzdb.BaseDir = absDir
+
+	return tzdb, nil
+}
+
+// ZoneinfoFS loads timezone database from a file system laid out like a
+// zoneinfo directory, such as an embed.FS, os.DirFS or a zip archive
+func ZoneinfoFS(fsys fs.FS) (*TzDB, error) {
+	return ZoneinfoFSWithFlags(fsys, 0)
+}
+
+// ZoneinfoFSWithFlags loads timezone database from a file system, see
+// ZoneinfoDirWithFlags for the flags
+func ZoneinfoFSWithFlags(fsys fs.FS, flags int) (*TzDB, error) {
+	if fsys == nil {
+		return nil, fmt.Errorf("file system cannot be nil")
+	}
+
 	tzdb := &TzDB{
 		Version:   "custom",
 		IndexSize: 0,
 		Index:     []TzDBIndexEntry{},
 		Data:      []byte{},
-		BaseDir:   absDir,
+		FS:        fsys,
 	}
 
-	// Walk the directory tree
-	err = filepath.Walk(directory, func(path string, info os.FileInfo, err error) error {
+	// Walk the file system
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
 		if err != nil {
+			if name == "." {
+				return err
+			}
 			return nil // Skip errors, continue walking
 		}
 
 		// Skip directories
-		if info.IsDir() {
+		if d.IsDir() {
 			// Skip certain directories
-			name := info.Name()
-			if name == "posix" || name == "right" {
-				return filepath.SkipDir
+			base := d.Name()
+			if base == "posix" || (base == "right" && flags&TIMELIB_ZONEINFO_INCLUDE_RIGHT == 0) {
+				return fs.SkipDir
 			}
 			return nil
 		}
 
 		// Filter out non-timezone files
-		name := info.Name()
-		if name == "." || name == ".." ||
-			name == "posixrules" || name == "localtime" ||
-			strings.Contains(name, ".list") ||
-			strings.Contains(name, ".tab") {
+		base := d.Name()
+		if base == "posixrules" || base == "localtime" ||
+			strings.Contains(base, ".list") ||
+			strings.Contains(base, ".tab") {
 			return nil
 		}
 
 		// Check if this looks like a timezone file
-		if !isValidTzFile(path) {
+		if !isValidTzFile(fsys, name) {
 			return nil
 		}
 
-		// Extract relative path from directory
-		relPath, err := filepath.Rel(directory, path)
-		if err != nil {
-			return nil // Skip this file
-		}
-
-		// Normalize path separators
-		relPath = filepath.ToSlash(relPath)
-
-		// Add to index
+		// Add to index, the ID is the slash separated path
 		entry := TzDBIndexEntry{
-			ID:  relPath,
+			ID:  name,
 			Pos: 0, // File-based entries use Pos=0
 		}
 		tzdb.Index = append(tzdb.Index, entry)
@@ -89,7 +120,7 @@ func ZoneinfoDir(directory string) (*TzDB, error) {
 	})
 
 	if err != nil {
-		return nil, fmt.Errorf("error scanning directory: %v", err)
+		return nil, fmt.Errorf("error scanning file system: %v", err)
 	}
 
 	// Sort index by ID
@@ -97,13 +128,58 @@ func ZoneinfoDir(directory string) (*TzDB, error) {
 		return strings.ToLower(tzdb.Index[i].ID) < strings.ToLower(tzdb.Index[j].ID)
 	})
 
+	// Pick up country, coordinate and comment metadata if the tab files exist
+	loadZoneTabs(tzdb, fsys)
+
+	if version := zoneinfoVersion(fsys); version != "" {
+		tzdb.Version = version
+	}
+
 	return tzdb, nil
 }
 
+// ZoneinfoZip loads timezone database from a zip archive of TZif files, like
+// the zoneinfo.zip shipped with Go. The archive is read into memory.
+func ZoneinfoZip(filename string) (*TzDB, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s: %v", filename, err)
+	}
+	return ZoneinfoZipReader(bytes.NewReader(data), int64(len(data)))
+}
+
+// ZoneinfoZipReader loads timezone database from a zip archive of TZif files
+func ZoneinfoZipReader(r io.ReaderAt, size int64) (*TzDB, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open zip archive: %v", err)
+	}
+	return ZoneinfoFS(archive)
+}
+
+// zoneinfoVersion returns the tzdata release of a zoneinfo file system, taken
+// from the "# version" line of tzdata.zi or from a +VERSION or version file.
+// It returns an empty string if the release cannot be determined.
+func zoneinfoVersion(fsys fs.FS) string {
+	if data, err := fs.ReadFile(fsys, "tzdata.zi"); err == nil {
+		line, _, _ := strings.Cut(string(data), "\n")
+		if version, ok := strings.CutPrefix(line, "# version "); ok {
+			return strings.TrimSpace(version)
+		}
+	}
+
+	for _, name := range []string{"+VERSION", "version"} {
+		if data, err := fs.ReadFile(fsys, name); err == nil {
+			return strings.TrimSpace(string(data))
+		}
+	}
+	return ""
+}
+
 // isValidTzFile checks if a file is likely a valid timezone file
-func isValidTzFile(path string) bool {
+func isValidTzFile(fsys fs.FS, name string) bool {
 	// Open file
-	file, err := os.Open(path)
+	file, err := fsys.Open(name)
 	if err != nil {
 		return false
 	}
@@ -121,8 +197,7 @@ func isValidTzFile(path string) bool {
 
 	// Read first 20 bytes to check magic number
 	buf := make([]byte, 20)
-	n, err := file.Read(buf)
-	if err != nil || n < 20 {
+	if _, err := io.ReadFull(file, buf); err != nil {
 		return false
 	}
 
@@ -138,12 +213,47 @@ func isValidTzFile(path string) bool {
 	return false
 }
 
+// tzdbFS returns the file system of a file-based database, or nil if the
+// database has none
+func tzdbFS(tzdb *TzDB) fs.FS {
+	if tzdb.FS != nil {
+		return tzdb.FS
+	}
+	if tzdb.BaseDir != "" {
+		return os.DirFS(tzdb.BaseDir)
+	}
+	return nil
+}
+
+// readTzDBFile reads the file of an index entry from the database file
+// system. The returned location is the path on disk for directory databases.
+func readTzDBFile(tzdb *TzDB, entry *TzDBIndexEntry) ([]byte, string, error) {
+	fsys := tzdbFS(tzdb)
+	if fsys == nil {
+		return nil, "", fmt.Errorf("timezone database has no file system")
+	}
+
+	data, err := fs.ReadFile(fsys, entry.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	if tzdb.BaseDir != "" {
+		return data, filepath.Join(tzdb.BaseDir, filepath.FromSlash(entry.ID)), nil
+	}
+	return data, entry.ID, nil
+}
+
 // LoadTzFileFromDB loads timezone file from database
 func LoadTzFileFromDB(tzName string, tzdb *TzDB) ([]byte, string, error) {
 	if tzdb == nil {
 		return nil, "", fmt.Errorf("timezone database is nil")
 	}
 
+	// Registered zones and layered databases
+	if data, location, ok, err := loadLayeredTzFile(tzName, tzdb); ok {
+		return data, location, err
+	}
+
 	// Find timezone in index
 	var entry *TzDBIndexEntry
 	for i := range tzdb.Index {
@@ -177,13 +287,9 @@ func LoadTzFileFromDB(tzName string, tzdb *TzDB) ([]byte, string, error) {
 	}
 
 	// Load from file - ID contains the relative path
-	// First try BaseDir if available
-	if tzdb.BaseDir != "" {
-		fullPath := filepath.Join(tzdb.BaseDir, filepath.FromSlash(entry.ID))
-		data, err := os.ReadFile(fullPath)
-		if err == nil {
-			return data, fullPath, nil
-		}
+	// First try the database file system if available
+	if data, location, err := readTzDBFile(tzdb, entry); err == nil {
+		return data, location, nil
 	}
 
 	// Fall back to system paths and direct path
@@ -205,6 +311,8 @@ func LoadTzFileFromDB(tzName string, tzdb *TzDB) ([]byte, string, error) {
 
 // UpdateParseTzfile updates the ParseTzfile function to use the new parser
 func UpdateParseTzfile(timezone string, tzdb *TzDB, errorCode *int) (*TzInfo, error) {
+	timezone = resolveWindowsZoneName(timezone, tzdb)
+
 	// Try to load from database first
 	if tzdb != nil {
 		// Search in index
@@ -220,7 +328,9 @@ func UpdateParseTzfile(timezone string, tzdb *TzDB, errorCode *int) (*TzInfo, er
 			// Try to load timezone file data
 			data, _, err := LoadTzFileFromDB(timezone, tzdb)
 			if err == nil {
-				return ParseTzfileData(timezone, data, errorCode)
+				tz, err := ParseTzfileData(timezone, data, errorCode)
+				applyZoneLocation(tz, timezone, tzdb)
+				return tz, err
 			}
 		}
 	}
@@ -243,5 +353,5 @@ func UpdateParseTzfile(timezone string, tzdb *TzDB, errorCode *int) (*TzInfo, er
 	if errorCode != nil {
 		*errorCode = TIMELIB_ERROR_NO_SUCH_TIMEZONE
 	}
-	return nil, fmt.Errorf("timezone '%s' not found", timezone)
+	return nil, fmt.Errorf("timezone '%s' not found%s", timezone, tzSuggestionHint(timezone, tzdb))
 }
@@ -0,0 +1,257 @@
+package timelib
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TzDBDiff is the result of DiffTzDB: the zones that were added, removed or
+// changed between two timezone databases
+type TzDBDiff struct {
+	OldVersion string       `json:"old_version"`
+	NewVersion string       `json:"new_version"`
+	Begin      int64        `json:"begin"` // Start of the compared range, UTC timestamp
+	End        int64        `json:"end"`   // End of the compared range (exclusive)
+	Added      []string     `json:"added,omitempty"`
+	Removed    []string     `json:"removed,omitempty"`
+	Changed    []TzZoneDiff `json:"changed,omitempty"`
+}
+
+// TzZoneDiff describes how a zone that is in both databases changed
+type TzZoneDiff struct {
+	ID                   string               `json:"id"`
+	Error                string               `json:"error,omitempty"` // Set if the zone could not be loaded from either database
+	Posix                *TzPosixChange       `json:"posix,omitempty"`
+	Transitions          []TzTransitionChange `json:"transitions,omitempty"`
+	AddedAbbreviations   []string             `json:"added_abbreviations,omitempty"`
+	RemovedAbbreviations []string             `json:"removed_abbreviations,omitempty"`
+}
+
+// TzPosixChange is a changed POSIX TZ string, which describes local time
+// after the last transition of a zone
+type TzPosixChange struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// TzTransitionChange is a transition that differs between the two versions of
+// a zone. Old or New is nil if the transition exists in one version only. A
+// change at the start of the range means the local time in effect there
+// differs.
+type TzTransitionChange struct {
+	At  int64         `json:"at"`
+	Old *TzTransition `json:"old,omitempty"`
+	New *TzTransition `json:"new,omitempty"`
+}
+
+// DiffTzDB compares two timezone databases zone by zone, e.g. BuiltinDB()
+// with ZoneinfoDir("/usr/share/zoneinfo") before upgrading. Transitions are
+// compared in the range [begin, end) of UTC timestamps, including those that
+// follow from the POSIX rules, so a zone whose transitions moved from the
+// table into its POSIX string is not reported as changed.
+func DiffTzDB(oldDB, newDB *TzDB, begin, end int64) (*TzDBDiff, error) {
+	if oldDB == nil || newDB == nil {
+		return nil, fmt.Errorf("timezone database is nil")
+	}
+	if begin >= end {
+		return nil, fmt.Errorf("empty range: %d is not before %d", begin, end)
+	}
+
+	diff := &TzDBDiff{
+		OldVersion: oldDB.Version,
+		NewVersion: newDB.Version,
+		Begin:      begin,
+		End:        end,
+	}
+
+	for _, entry := range TimezoneIdentifiersList(newDB, nil) {
+		if !TimezoneIDIsValid(entry.ID, oldDB) {
+			diff.Added = append(diff.Added, entry.ID)
+		}
+	}
+
+	for _, entry := range TimezoneIdentifiersList(oldDB, nil) {
+		if !TimezoneIDIsValid(entry.ID, newDB) {
+			diff.Removed = append(diff.Removed, entry.ID)
+			continue
+		}
+
+		zoneDiff := diffZone(entry.ID, oldDB, newDB, begin, end)
+		if zoneDiff != nil {
+			diff.Changed = append(diff.Changed, *zoneDiff)
+		}
+	}
+
+	return diff, nil
+}
+
+// diffZone compares a zone in two databases, returning nil if it did not change
+func diffZone(id string, oldDB, newDB *TzDB, begin, end int64) *TzZoneDiff {
+	zoneDiff := &TzZoneDiff{ID: id}
+
+	oldTz, err := ParseTzfile(id, oldDB, nil)
+	if err != nil {
+		zoneDiff.Error = fmt.Sprintf("old: %v", err)
+		return zoneDiff
+	}
+	newTz, err := ParseTzfile(id, newDB, nil)
+	if err != nil {
+		zoneDiff.Error = fmt.Sprintf("new: %v", err)
+		return zoneDiff
+	}
+
+	if oldTz.PosixString != newTz.PosixString {
+		zoneDiff.Posix = &TzPosixChange{Old: oldTz.PosixString, New: newTz.PosixString}
+	}
+
+	zoneDiff.Transitions = diffTransitions(zoneTransitions(oldTz, begin, end), zoneTransitions(newTz, begin, end))
+
+	oldAbbrs := zoneAbbreviations(oldTz)
+	newAbbrs := zoneAbbreviations(newTz)
+	for abbr := range newAbbrs {
+		if !oldAbbrs[abbr] {
+			zoneDiff.AddedAbbreviations = append(zoneDiff.AddedAbbreviations, abbr)
+		}
+	}
+	for abbr := range oldAbbrs {
+		if !newAbbrs[abbr] {
+			zoneDiff.RemovedAbbreviations = append(zoneDiff.RemovedAbbreviations, abbr)
+		}
+	}
+	sort.Strings(zoneDiff.AddedAbbreviations)
+	sort.Strings(zoneDiff.RemovedAbbreviations)
+
+	if zoneDiff.Posix == nil && len(zoneDiff.Transitions) == 0 &&
+		len(zoneDiff.AddedAbbreviations) == 0 && len(zoneDiff.RemovedAbbreviations) == 0 {
+		return nil
+	}
+	return zoneDiff
+}
+
+// diffTransitions merges two transition lists sorted by time into the list of
+// transitions that differ
+func diffTransitions(oldList, newList []TzTransition) []TzTransitionChange {
+	var changes []TzTransitionChange
+	i, j := 0, 0
+	for i < len(oldList) || j < len(newList) {
+		switch {
+		case j == len(newList) || (i < len(oldList) && oldList[i].At < newList[j].At):
+			changes = append(changes, TzTransitionChange{At: oldList[i].At, Old: &oldList[i]})
+			i++
+		case i == len(oldList) || newList[j].At < oldList[i].At:
+			changes = append(changes, TzTransitionChange{At: newList[j].At, New: &newList[j]})
+			j++
+		default:
+			if !sameLocalTime(oldList[i], newList[j]) {
+				changes = append(changes, TzTransitionChange{At: oldList[i].At, Old: &oldList[i], New: &newList[j]})
+			}
+			i++
+			j++
+		}
+	}
+	return changes
+}
+
+// zoneAbbreviations returns the set of abbreviations used by the local time
+// types of a zone
+func zoneAbbreviations(tz *TzInfo) map[string]bool {
+	abbrs := make(map[string]bool)
+	for _, tt := range tz.Type {
+		abbrs[extractNullTerminatedString(tz.TimezoneAbbr, tt.AbbrIdx)] = true
+	}
+	return abbrs
+}
+
+// Empty reports whether the databases contain the same zones with the same
+// local times
+func (d *TzDBDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// WriteText writes a human-readable report of the differences
+func (d *TzDBDiff) WriteText(w io.Writer) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Old version: %s\n", d.OldVersion)
+	fmt.Fprintf(&b, "New version: %s\n", d.NewVersion)
+	fmt.Fprintf(&b, "Range: %s to %s\n", formatDiffTimestamp(d.Begin), formatDiffTimestamp(d.End))
+
+	if d.Empty() {
+		b.WriteString("\nNo differences\n")
+	}
+
+	for _, list := range []struct {
+		title string
+		ids   []string
+	}{
+		{"Added zones", d.Added},
+		{"Removed zones", d.Removed},
+	} {
+		if len(list.ids) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s (%d):\n", list.title, len(list.ids))
+		for _, id := range list.ids {
+			fmt.Fprintf(&b, "  %s\n", id)
+		}
+	}
+
+	if len(d.Changed) > 0 {
+		fmt.Fprintf(&b, "\nChanged zones (%d):\n", len(d.Changed))
+	}
+	for _, zone := range d.Changed {
+		fmt.Fprintf(&b, "  %s\n", zone.ID)
+		if zone.Error != "" {
+			fmt.Fprintf(&b, "    error: %s\n", zone.Error)
+		}
+		if zone.Posix != nil {
+			fmt.Fprintf(&b, "    POSIX string: %q -> %q\n", zone.Posix.Old, zone.Posix.New)
+		}
+		if len(zone.AddedAbbreviations) > 0 {
+			fmt.Fprintf(&b, "    added abbreviations: %s\n", strings.Join(zone.AddedAbbreviations, ", "))
+		}
+		if len(zone.RemovedAbbreviations) > 0 {
+			fmt.Fprintf(&b, "    removed abbreviations: %s\n", strings.Join(zone.RemovedAbbreviations, ", "))
+		}
+		for _, change := range zone.Transitions {
+			fmt.Fprintf(&b, "    %s  %s -> %s\n", formatDiffTimestamp(change.At),
+				formatDiffLocalTime(change.Old), formatDiffLocalTime(change.New))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// formatDiffTimestamp formats a UTC timestamp for the text report
+func formatDiffTimestamp(ts int64) string {
+	return time.Unix(ts, 0).UTC().Format("2006-01-02 15:04:05 UTC")
+}
+
+// formatDiffLocalTime formats the local time after a transition for the text
+// report, e.g. "-05:00 CDT (DST)"
+func formatDiffLocalTime(tr *TzTransition) string {
+	if tr == nil {
+		return "(none)"
+	}
+
+	offset := int64(tr.Offset)
+	sign := '+'
+	if offset < 0 {
+		sign = '-'
+		offset = -offset
+	}
+	s := fmt.Sprintf("%c%02d:%02d", sign, offset/3600, offset/60%60)
+	if offset%60 != 0 {
+		s += fmt.Sprintf(":%02d", offset%60)
+	}
+
+	s += " " + tr.Abbr
+	if tr.IsDst {
+		s += " (DST)"
+	}
+	return s
+}
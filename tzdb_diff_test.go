@@ -0,0 +1,183 @@
+package timelib
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// diffTestDB returns a database with the given zones registered
+func diffTestDB(t *testing.T, version string, zones map[string]*TzInfo) *TzDB {
+	t.Helper()
+
+	tzdb := NewLayeredTzDB()
+	tzdb.Version = version
+	for name, tz := range zones {
+		if err := RegisterTimezone(tzdb, name, tz); err != nil {
+			t.Fatalf("RegisterTimezone(%s): %v", name, err)
+		}
+	}
+	return tzdb
+}
+
+// TestDiffTzDB tests added, removed and changed zones
+func TestDiffTzDB(t *testing.T) {
+	newYork, err := ParseTzfile("America/New_York", BuiltinDB(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	usRules, _ := NewPosixTzInfo("Test/Changed", "EST5EDT,M3.2.0,M11.1.0")
+	oldRules, _ := NewPosixTzInfo("Test/Changed", "EST5EDT,M4.1.0,M10.5.0")
+	fixed, _ := NewFixedOffsetTzInfo("Test/Fixed", 3600, "")
+
+	// The same local times from explicit transitions plus a POSIX string
+	// are not a change
+	begin2020 := TsAtStartOfYear(2020)
+	table, err := NewTransitionsTzInfo("Test/Table", []TzTransition{
+		{At: begin2020, Offset: -18000, Abbr: "EST"},
+		{At: begin2020 + 67*SECS_PER_DAY + 7*3600, Offset: -14400, IsDst: true, Abbr: "EDT"},
+		{At: begin2020 + 305*SECS_PER_DAY + 6*3600, Offset: -18000, Abbr: "EST"},
+	}, "EST5EDT,M3.2.0,M11.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oldDB := diffTestDB(t, "2024a", map[string]*TzInfo{
+		"America/New_York": newYork,
+		"Test/Changed":     oldRules,
+		"Test/Removed":     fixed,
+		"Test/Table":       usRules,
+	})
+	newDB := diffTestDB(t, "2024b", map[string]*TzInfo{
+		"America/New_York": newYork,
+		"Test/Changed":     usRules,
+		"Test/Added":       fixed,
+		"Test/Table":       table,
+	})
+
+	begin, end := TsAtStartOfYear(2020), TsAtStartOfYear(2022)
+	diff, err := DiffTzDB(oldDB, newDB, begin, end)
+	if err != nil {
+		t.Fatalf("DiffTzDB failed: %v", err)
+	}
+
+	if diff.OldVersion != "2024a" || diff.NewVersion != "2024b" {
+		t.Errorf("versions %s/%s", diff.OldVersion, diff.NewVersion)
+	}
+	if strings.Join(diff.Added, ",") != "Test/Added" {
+		t.Errorf("added %v", diff.Added)
+	}
+	if strings.Join(diff.Removed, ",") != "Test/Removed" {
+		t.Errorf("removed %v", diff.Removed)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].ID != "Test/Changed" {
+		t.Fatalf("changed %+v", diff.Changed)
+	}
+
+	changed := diff.Changed[0]
+	if changed.Posix == nil || changed.Posix.Old != "EST5EDT,M4.1.0,M10.5.0" || changed.Posix.New != "EST5EDT,M3.2.0,M11.1.0" {
+		t.Errorf("POSIX change %+v", changed.Posix)
+	}
+	if len(changed.AddedAbbreviations) != 0 || len(changed.RemovedAbbreviations) != 0 {
+		t.Errorf("abbreviations changed: %v/%v", changed.AddedAbbreviations, changed.RemovedAbbreviations)
+	}
+
+	// Both transitions of both years moved, each listed for old and new
+	if len(changed.Transitions) != 8 {
+		t.Fatalf("expected 8 changed transitions, got %d", len(changed.Transitions))
+	}
+	first := changed.Transitions[0]
+	if first.At != 1583650800 || first.Old != nil || first.New == nil || first.New.Abbr != "EDT" {
+		t.Errorf("first change %+v", first)
+	}
+	second := changed.Transitions[1]
+	if second.At != 1586070000 || second.New != nil || second.Old == nil || second.Old.Offset != -14400 {
+		t.Errorf("second change %+v", second)
+	}
+
+	var text strings.Builder
+	if err := diff.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Old version: 2024a\n",
+		"Range: 2020-01-01 00:00:00 UTC to 2022-01-01 00:00:00 UTC\n",
+		"Added zones (1):\n  Test/Added\n",
+		"Removed zones (1):\n  Test/Removed\n",
+		"Changed zones (1):\n  Test/Changed\n",
+		`POSIX string: "EST5EDT,M4.1.0,M10.5.0" -> "EST5EDT,M3.2.0,M11.1.0"`,
+		"2020-03-08 07:00:00 UTC  (none) -> -04:00 EDT (DST)\n",
+		"2020-04-05 07:00:00 UTC  -04:00 EDT (DST) -> (none)\n",
+	} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("report does not contain %q:\n%s", want, text.String())
+		}
+	}
+
+	data, err := json.Marshal(diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded TzDBDiff
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Changed) != 1 || len(decoded.Changed[0].Transitions) != 8 || decoded.Added[0] != "Test/Added" {
+		t.Errorf("JSON round trip lost data: %s", data)
+	}
+	if !strings.Contains(string(data), `"new":{"at":1583650800,"offset":-14400,"is_dst":true,"abbr":"EDT"}`) {
+		t.Errorf("unexpected JSON: %s", data)
+	}
+}
+
+// TestDiffTzDBAbbreviations tests that renamed abbreviations are reported
+func TestDiffTzDBAbbreviations(t *testing.T) {
+	oldZone, _ := NewFixedOffsetTzInfo("Test/Zone", 3600, "MET")
+	newZone, _ := NewFixedOffsetTzInfo("Test/Zone", 3600, "CET")
+
+	diff, err := DiffTzDB(
+		diffTestDB(t, "1", map[string]*TzInfo{"Test/Zone": oldZone}),
+		diffTestDB(t, "2", map[string]*TzInfo{"Test/Zone": newZone}),
+		0, TsAtStartOfYear(2000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Changed) != 1 {
+		t.Fatalf("changed %+v", diff.Changed)
+	}
+
+	zone := diff.Changed[0]
+	if strings.Join(zone.AddedAbbreviations, ",") != "CET" || strings.Join(zone.RemovedAbbreviations, ",") != "MET" {
+		t.Errorf("abbreviations %v/%v", zone.AddedAbbreviations, zone.RemovedAbbreviations)
+	}
+	// Only the local time at the start of the range differs
+	if len(zone.Transitions) != 1 || zone.Transitions[0].At != 0 ||
+		zone.Transitions[0].Old.Abbr != "MET" || zone.Transitions[0].New.Abbr != "CET" {
+		t.Errorf("transitions %+v", zone.Transitions)
+	}
+}
+
+// TestDiffTzDBIdentical tests that a database does not differ from itself
+func TestDiffTzDBIdentical(t *testing.T) {
+	diff, err := DiffTzDB(BuiltinDB(), BuiltinDB(), 0, TsAtStartOfYear(2038))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Empty() {
+		t.Errorf("expected no differences, got %d added, %d removed, %d changed",
+			len(diff.Added), len(diff.Removed), len(diff.Changed))
+	}
+
+	var text strings.Builder
+	diff.WriteText(&text)
+	if !strings.Contains(text.String(), "No differences") {
+		t.Errorf("unexpected report:\n%s", text.String())
+	}
+
+	if _, err := DiffTzDB(nil, BuiltinDB(), 0, 1); err == nil {
+		t.Error("expected an error for a nil database")
+	}
+	if _, err := DiffTzDB(BuiltinDB(), BuiltinDB(), 1, 1); err == nil {
+		t.Error("expected an error for an empty range")
+	}
+}
@@ -0,0 +1,140 @@
+package timelib
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+// TestZoneinfoFS tests indexing and loading zones from an fs.FS
+func TestZoneinfoFS(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("tests", "files", "New_York_Slim"))
+	if err != nil {
+		t.Skipf("fixture not available: %v", err)
+	}
+
+	fsys := fstest.MapFS{
+		"America/New_York":       {Data: fixture},
+		"right/America/New_York": {Data: fixture},
+		"posix/America/New_York": {Data: fixture},
+		"posixrules":             {Data: fixture},
+		"America/Broken":         {Data: []byte("not a timezone file at all")},
+		"zone.tab":               {Data: []byte("US\t+404251-0740023\tAmerica/New_York\tEastern (most areas)\n")},
+		"tzdata.zi":              {Data: []byte("# version 2099z\n")},
+	}
+
+	tzdb, err := ZoneinfoFS(fsys)
+	if err != nil {
+		t.Fatalf("ZoneinfoFS failed: %v", err)
+	}
+	if tzdb.IndexSize != 1 || tzdb.Index[0].ID != "America/New_York" {
+		t.Fatalf("Unexpected index %v", tzdb.Index)
+	}
+	if tzdb.Version != "2099z" {
+		t.Errorf("Expected version 2099z, got %q", tzdb.Version)
+	}
+
+	for _, load := range []func(string, *TzDB, *int) (*TzInfo, error){ParseTzfile, ParseTzfileFromDB} {
+		tz, err := load("America/New_York", tzdb, nil)
+		if err != nil {
+			t.Fatalf("cannot load from fs: %v", err)
+		}
+		if to := GetTimeZoneInfo(1720000000, tz); to.Offset != -14400 || to.Abbr != "EDT" {
+			t.Errorf("Unexpected offset %d/%s", to.Offset, to.Abbr)
+		}
+		if tz.Location.Comments != "Eastern (most areas)" {
+			t.Errorf("Location not applied: %+v", tz.Location)
+		}
+	}
+
+	withRight, err := ZoneinfoFSWithFlags(fsys, TIMELIB_ZONEINFO_INCLUDE_RIGHT)
+	if err != nil {
+		t.Fatalf("ZoneinfoFSWithFlags failed: %v", err)
+	}
+	if !TimezoneIDIsValid("right/America/New_York", withRight) {
+		t.Error("Expected right/America/New_York to be indexed")
+	}
+
+	if _, err := ZoneinfoFS(nil); err == nil {
+		t.Error("Expected error for nil file system")
+	}
+}
+
+// TestZoneinfoZip tests loading zones from a zip archive
+func TestZoneinfoZip(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("tests", "files", "New_York_Slim"))
+	if err != nil {
+		t.Skipf("fixture not available: %v", err)
+	}
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, name := range []string{"America/New_York", "US/Eastern"} {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write(fixture)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tzdb, err := ZoneinfoZipReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("ZoneinfoZipReader failed: %v", err)
+	}
+	if tzdb.IndexSize != 2 {
+		t.Fatalf("Expected 2 entries, got %v", tzdb.Index)
+	}
+	tz, err := ParseTzfile("US/Eastern", tzdb, nil)
+	if err != nil {
+		t.Fatalf("ParseTzfile failed: %v", err)
+	}
+	if to := GetTimeZoneInfo(1700000000, tz); to.Offset != -18000 {
+		t.Errorf("Expected -18000, got %d", to.Offset)
+	}
+
+	if _, err := ZoneinfoZipReader(bytes.NewReader([]byte("no zip")), 6); err == nil {
+		t.Error("Expected error for invalid archive")
+	}
+}
+
+// TestZoneinfoZipGoroot tests loading the zoneinfo.zip shipped with Go
+func TestZoneinfoZipGoroot(t *testing.T) {
+	path := filepath.Join(runtime.GOROOT(), "lib", "time", "zoneinfo.zip")
+	if _, err := os.Stat(path); err != nil {
+		t.Skip("zoneinfo.zip not available")
+	}
+
+	tzdb, err := ZoneinfoZip(path)
+	if err != nil {
+		t.Fatalf("ZoneinfoZip failed: %v", err)
+	}
+	ids := make([]string, 0, len(tzdb.Index))
+	for _, entry := range tzdb.Index {
+		ids = append(ids, entry.ID)
+	}
+	if !slices.Contains(ids, "Europe/Amsterdam") || !slices.Contains(ids, "UTC") {
+		t.Errorf("Expected Europe/Amsterdam and UTC in zoneinfo.zip")
+	}
+
+	want, err := ParseTzfile("Europe/Amsterdam", BuiltinDB(), nil)
+	if err != nil {
+		t.Fatalf("cannot load builtin zone: %v", err)
+	}
+	got, err := ParseTzfile("Europe/Amsterdam", tzdb, nil)
+	if err != nil {
+		t.Fatalf("cannot load zone from zip: %v", err)
+	}
+	for _, ts := range []int64{0, 1000000000, 1700000000, 1720000000} {
+		if w, g := GetTimeZoneInfo(ts, want), GetTimeZoneInfo(ts, got); w.Offset != g.Offset || w.Abbr != g.Abbr {
+			t.Errorf("At %d: got %d/%s, want %d/%s", ts, g.Offset, g.Abbr, w.Offset, w.Abbr)
+		}
+	}
+}
@@ -830,3 +830,35 @@ const (
 	TIMELIB_OVERRIDE_TIME = 0x01
 	TIMELIB_NO_CLONE      = 0x02
 )
+
+// orDefault returns the values, or the single default value if there are none
+func orDefault(values []int64, def int64) []int64 {
+	if len(values) > 0 {
+		return values
+	}
+	return []int64{def}
+}
+
+// containsInt64 checks whether a value is in a list
+func containsInt64(values []int64, v int64) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// floorDiv divides rounding towards negative infinity
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+// ceilDiv divides rounding towards positive infinity
+func ceilDiv(a, b int64) int64 {
+	return -floorDiv(-a, b)
+}
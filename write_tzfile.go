@@ -0,0 +1,296 @@
+package timelib
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// EncodeTzfile serializes a TzInfo as an RFC 8536 TZif file. Version must be
+// 2, 3 or 4, or 0 to pick the lowest version that can represent the zone.
+// Both the 32-bit and the 64-bit data blocks are written, followed by the
+// POSIX TZ string footer.
+func EncodeTzfile(tz *TzInfo, version int) ([]byte, error) {
+	if tz == nil {
+		return nil, errors.New("timezone info is nil")
+	}
+
+	required := tzfileRequiredVersion(tz)
+	if version == 0 {
+		version = required
+	}
+	if version < 2 || version > 4 {
+		return nil, fmt.Errorf("unsupported TZif version: %d", version)
+	}
+	if version < required {
+		return nil, fmt.Errorf("timezone %s needs at least TZif version %d", tz.Name, required)
+	}
+
+	if err := validateTzfileData(tz); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	// Version 1 data block with the transitions that fit in 32 bits
+	writeTzfileBlock(&buf, tz, version, false)
+
+	// Version 2+ data block with the full 64-bit data
+	writeTzfileBlock(&buf, tz, version, true)
+
+	// Footer
+	buf.WriteByte('\n')
+	buf.WriteString(tz.PosixString)
+	buf.WriteByte('\n')
+
+	return buf.Bytes(), nil
+}
+
+// WriteTzfile writes a TzInfo as an RFC 8536 TZif file, see EncodeTzfile
+func WriteTzfile(w io.Writer, tz *TzInfo, version int) error {
+	data, err := EncodeTzfile(tz, version)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// EncodePHPTzfile serializes a TzInfo in the PHP variant of the TZif format
+// used by the builtin database. The preamble carries the canonical flag and
+// the country code, and the zone location follows the POSIX string footer.
+func EncodePHPTzfile(tz *TzInfo, canonical bool) ([]byte, error) {
+	data, err := EncodeTzfile(tz, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// Replace the TZif magic and version of the first header by the PHP preamble
+	data[0], data[1], data[2] = 'P', 'H', 'P'
+	data[3] = data[4]
+	data[4] = 0
+	if canonical {
+		data[4] = 1
+	}
+	data[5], data[6] = '?', '?'
+	if hasZoneLocation(tz.Location) {
+		data[5], data[6] = tz.Location.CountryCode[0], tz.Location.CountryCode[1]
+	}
+
+	var buf bytes.Buffer
+	buf.Write(data)
+
+	lat, lon := uint32(0), uint32(0)
+	if hasZoneLocation(tz.Location) {
+		lat = uint32(math.Round((tz.Location.Latitude + 90) * 100000))
+		lon = uint32(math.Round((tz.Location.Longitude + 180) * 100000))
+	}
+	comments := tz.Location.Comments
+	if comments == "?" {
+		comments = ""
+	}
+	binary.Write(&buf, binary.BigEndian, lat)
+	binary.Write(&buf, binary.BigEndian, lon)
+	binary.Write(&buf, binary.BigEndian, uint32(len(comments)))
+	buf.WriteString(comments)
+
+	return buf.Bytes(), nil
+}
+
+// tzfileRequiredVersion returns the lowest TZif version (2, 3 or 4) that can
+// represent the zone
+func tzfileRequiredVersion(tz *TzInfo) int {
+	// Version 4: leap second table truncated at the start, or with an expiry record
+	if n := len(tz.LeapTimes); n > 0 {
+		if corr := tz.LeapTimes[0].Corr; corr != 1 && corr != -1 {
+			return 4
+		}
+		if n > 1 && tz.LeapTimes[n-1].Corr == tz.LeapTimes[n-2].Corr {
+			return 4
+		}
+	}
+
+	// Version 3: POSIX rule times outside 0-24 hours
+	if posixNeedsVersion3(tz.PosixString) {
+		return 3
+	}
+
+	return 2
+}
+
+// posixNeedsVersion3 checks whether a POSIX TZ string uses the version 3
+// extension of transition times that are negative or exceed 24 hours
+func posixNeedsVersion3(posix string) bool {
+	for _, rule := range strings.Split(posix, ",")[1:] {
+		slash := strings.IndexByte(rule, '/')
+		if slash < 0 {
+			continue
+		}
+
+		t := rule[slash+1:]
+		if strings.HasPrefix(t, "-") {
+			return true
+		}
+		hours := strings.TrimPrefix(t, "+")
+		if colon := strings.IndexByte(hours, ':'); colon >= 0 {
+			hours = hours[:colon]
+		}
+		if h, err := strconv.Atoi(hours); err == nil && h > 24 {
+			return true
+		}
+	}
+	return false
+}
+
+// validateTzfileData checks that the TzInfo is consistent enough to be written
+func validateTzfileData(tz *TzInfo) error {
+	if len(tz.Type) == 0 {
+		return fmt.Errorf("timezone %s has no local time types", tz.Name)
+	}
+	if len(tz.Type) > 256 {
+		return fmt.Errorf("timezone %s has more than 256 local time types", tz.Name)
+	}
+	if len(tz.TransIdx) != len(tz.Trans) {
+		return fmt.Errorf("timezone %s has %d transitions but %d transition types", tz.Name, len(tz.Trans), len(tz.TransIdx))
+	}
+	if err := validateTransitions(tz.Trans); err != nil {
+		return err
+	}
+
+	abbrLen := len(tzfileAbbreviations(tz))
+	for i, idx := range tz.TransIdx {
+		if int(idx) >= len(tz.Type) {
+			return fmt.Errorf("transition %d refers to unknown type %d", i, idx)
+		}
+	}
+	for i, tt := range tz.Type {
+		if tt.AbbrIdx < 0 || tt.AbbrIdx >= abbrLen {
+			return fmt.Errorf("type %d has invalid abbreviation index %d", i, tt.AbbrIdx)
+		}
+	}
+	return nil
+}
+
+// tzfileAbbreviations returns the abbreviation strings, NUL terminated
+func tzfileAbbreviations(tz *TzInfo) string {
+	if strings.HasSuffix(tz.TimezoneAbbr, "\x00") {
+		return tz.TimezoneAbbr
+	}
+	return tz.TimezoneAbbr + "\x00"
+}
+
+// tzfileHasIndicators checks whether any type has the standard/wall or UT/local flag set
+func tzfileHasIndicators(tz *TzInfo) (std, ut bool) {
+	for _, tt := range tz.Type {
+		if tt.IsStd != 0 {
+			std = true
+		}
+		if tt.IsUtc != 0 {
+			ut = true
+		}
+	}
+	return std, ut
+}
+
+// fitsInt32 checks whether a timestamp can be stored in a 32-bit data block
+func fitsInt32(ts int64) bool {
+	return ts >= math.MinInt32 && ts <= math.MaxInt32
+}
+
+// writeTzfileBlock writes a header and data block. The 32-bit block keeps
+// the transitions that fit in 32 bits; if earlier transitions had to be
+// dropped, a transition at the lowest 32-bit time keeps the type in effect.
+func writeTzfileBlock(buf *bytes.Buffer, tz *TzInfo, version int, use64bit bool) {
+	trans := tz.Trans
+	transIdx := tz.TransIdx
+	leaps := tz.LeapTimes
+
+	if !use64bit {
+		trans = make([]int64, 0, len(tz.Trans))
+		transIdx = make([]uint8, 0, len(tz.TransIdx))
+		for i, t := range tz.Trans {
+			if t < math.MinInt32 {
+				continue
+			}
+			if !fitsInt32(t) {
+				break
+			}
+			if len(trans) == 0 && i > 0 && t > math.MinInt32 {
+				trans = append(trans, math.MinInt32)
+				transIdx = append(transIdx, tz.TransIdx[i-1])
+			}
+			trans = append(trans, t)
+			transIdx = append(transIdx, tz.TransIdx[i])
+		}
+		if n := len(tz.Trans); len(trans) == 0 && n > 0 && tz.Trans[n-1] < math.MinInt32 {
+			trans = append(trans, math.MinInt32)
+			transIdx = append(transIdx, tz.TransIdx[n-1])
+		}
+
+		leaps = make([]TLInfo, 0, len(tz.LeapTimes))
+		for _, lt := range tz.LeapTimes {
+			if fitsInt32(lt.Trans) {
+				leaps = append(leaps, lt)
+			}
+		}
+	}
+
+	abbr := tzfileAbbreviations(tz)
+	hasStd, hasUt := tzfileHasIndicators(tz)
+	stdcnt, utcnt := 0, 0
+	if hasStd || hasUt {
+		stdcnt = len(tz.Type)
+	}
+	if hasUt {
+		utcnt = len(tz.Type)
+	}
+
+	// Header
+	buf.WriteString("TZif")
+	buf.WriteByte(byte('0' + version))
+	buf.Write(make([]byte, 15))
+	for _, n := range []int{utcnt, stdcnt, len(leaps), len(trans), len(tz.Type), len(abbr)} {
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+
+	// Transition times and types
+	for _, t := range trans {
+		if use64bit {
+			binary.Write(buf, binary.BigEndian, t)
+		} else {
+			binary.Write(buf, binary.BigEndian, int32(t))
+		}
+	}
+	buf.Write(transIdx)
+
+	// Local time types
+	for _, tt := range tz.Type {
+		binary.Write(buf, binary.BigEndian, tt.Offset)
+		buf.WriteByte(byte(tt.IsDst))
+		buf.WriteByte(byte(tt.AbbrIdx))
+	}
+	buf.WriteString(abbr)
+
+	// Leap second records
+	for _, lt := range leaps {
+		if use64bit {
+			binary.Write(buf, binary.BigEndian, lt.Trans)
+		} else {
+			binary.Write(buf, binary.BigEndian, int32(lt.Trans))
+		}
+		binary.Write(buf, binary.BigEndian, int32(lt.Corr))
+	}
+
+	// Standard/wall and UT/local indicators
+	for i := 0; i < stdcnt; i++ {
+		buf.WriteByte(byte(tz.Type[i].IsStd))
+	}
+	for i := 0; i < utcnt; i++ {
+		buf.WriteByte(byte(tz.Type[i].IsUtc))
+	}
+}
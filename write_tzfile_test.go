@@ -0,0 +1,199 @@
+package timelib
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// compareTzOffsets checks that two zones agree on offset, DST flag and
+// abbreviation at the given instants
+func compareTzOffsets(t *testing.T, name string, want, got *TzInfo, instants []int64) {
+	t.Helper()
+
+	for _, ts := range instants {
+		w := GetTimeZoneInfo(ts, want)
+		g := GetTimeZoneInfo(ts, got)
+		if w.Offset != g.Offset || w.IsDst != g.IsDst || w.Abbr != g.Abbr {
+			t.Errorf("%s at %d: got %d/%d/%s, want %d/%d/%s", name, ts, g.Offset, g.IsDst, g.Abbr, w.Offset, w.IsDst, w.Abbr)
+			return
+		}
+	}
+}
+
+// tzSampleInstants returns instants around every transition plus a range of
+// years handled by the POSIX string
+func tzSampleInstants(tz *TzInfo) []int64 {
+	instants := make([]int64, 0, len(tz.Trans)*3+600)
+	for _, tr := range tz.Trans {
+		instants = append(instants, tr-1, tr, tr+1)
+	}
+	for year := int64(1800); year <= 2100; year += 7 {
+		start := TsAtStartOfYear(year)
+		for month := int64(0); month < 12; month += 3 {
+			instants = append(instants, start+month*31*SECS_PER_DAY)
+		}
+	}
+	return instants
+}
+
+// TestEncodeTzfileRoundTripBuiltin round-trips every builtin zone through the writer and reader
+func TestEncodeTzfileRoundTripBuiltin(t *testing.T) {
+	tzdb := BuiltinDB()
+
+	for _, entry := range tzdb.Index {
+		orig, err := ParseTzfile(entry.ID, tzdb, nil)
+		if err != nil {
+			t.Errorf("%s: cannot load: %v", entry.ID, err)
+			continue
+		}
+
+		data, err := EncodeTzfile(orig, 0)
+		if err != nil {
+			t.Errorf("%s: EncodeTzfile failed: %v", entry.ID, err)
+			continue
+		}
+
+		parsed, err := ParseTzfileData(entry.ID, data, nil)
+		if err != nil {
+			t.Errorf("%s: cannot parse written data: %v", entry.ID, err)
+			continue
+		}
+		if parsed.PosixString != orig.PosixString {
+			t.Errorf("%s: POSIX string %q, want %q", entry.ID, parsed.PosixString, orig.PosixString)
+		}
+		if len(parsed.Trans) != len(orig.Trans) || len(parsed.Type) != len(orig.Type) {
+			t.Errorf("%s: %d transitions/%d types, want %d/%d", entry.ID, len(parsed.Trans), len(parsed.Type), len(orig.Trans), len(orig.Type))
+		}
+
+		compareTzOffsets(t, entry.ID, orig, parsed, tzSampleInstants(orig))
+	}
+}
+
+// TestEncodeTzfile32BitBlock tests the version 1 data block on its own
+func TestEncodeTzfile32BitBlock(t *testing.T) {
+	orig, err := ParseTzfile("America/New_York", BuiltinDB(), nil)
+	if err != nil {
+		t.Fatalf("cannot load: %v", err)
+	}
+
+	data, err := EncodeTzfile(orig, 2)
+	if err != nil {
+		t.Fatalf("EncodeTzfile failed: %v", err)
+	}
+
+	// Turn the file into a version 1 file, so that only the first block is read
+	v1 := bytes.Clone(data)
+	v1[4] = 0
+	parsed, err := ParseTzfileData("America/New_York", v1, nil)
+	if err != nil {
+		t.Fatalf("cannot parse 32-bit block: %v", err)
+	}
+
+	if len(parsed.Trans) == 0 {
+		t.Fatal("Expected transitions in the 32-bit block")
+	}
+
+	// The reader only looks up offsets in 64-bit data, so compare the
+	// transitions of the block directly
+	for i, tr := range parsed.Trans {
+		want := GetTimeZoneInfo(tr, orig)
+		got := parsed.Type[parsed.TransIdx[i]]
+		if got.Offset != want.Offset || got.IsDst != want.IsDst {
+			t.Errorf("Transition at %d: got %d/%d, want %d/%d", tr, got.Offset, got.IsDst, want.Offset, want.IsDst)
+		}
+		if tr < -2147483648 || tr > 2147483647 {
+			t.Errorf("Transition %d does not fit in 32 bits", tr)
+		}
+	}
+}
+
+// TestEncodeTzfileVersions tests version selection and validation
+func TestEncodeTzfileVersions(t *testing.T) {
+	tz, err := ParseTzfile("Europe/London", BuiltinDB(), nil)
+	if err != nil {
+		t.Fatalf("cannot load: %v", err)
+	}
+
+	for _, version := range []int{2, 3, 4} {
+		data, err := EncodeTzfile(tz, version)
+		if err != nil {
+			t.Fatalf("EncodeTzfile(v%d) failed: %v", version, err)
+		}
+		if data[4] != byte('0'+version) {
+			t.Errorf("Expected version byte %c, got %c", '0'+version, data[4])
+		}
+	}
+	if _, err := EncodeTzfile(tz, 1); err == nil {
+		t.Error("Expected error for version 1")
+	}
+
+	// Rule times beyond 24 hours need version 3
+	v3 := &TzInfo{
+		Name:         "Test/V3",
+		Type:         []TTInfo{{Offset: -10800}},
+		TimezoneAbbr: "-03\x00",
+		PosixString:  "<-03>3<-02>,M3.5.0/-2,M10.5.0/-1",
+	}
+	if _, err := EncodeTzfile(v3, 2); err == nil {
+		t.Error("Expected error writing version 3 rules as version 2")
+	}
+	data, err := EncodeTzfile(v3, 0)
+	if err != nil || data[4] != '3' {
+		t.Errorf("Expected automatic version 3, got %v", err)
+	}
+
+	// A leap second expiry record needs version 4
+	v4 := &TzInfo{
+		Name:         "Test/V4",
+		Type:         []TTInfo{{Offset: 0}},
+		TimezoneAbbr: "UTC\x00",
+		LeapTimes:    []TLInfo{{78796800, 1}, {94694401, 2}, {1750000000, 2}},
+		PosixString:  "UTC0",
+	}
+	if data, err := EncodeTzfile(v4, 0); err != nil || data[4] != '4' {
+		t.Errorf("Expected automatic version 4, got %v", err)
+	}
+
+	if _, err := EncodeTzfile(&TzInfo{Name: "Empty"}, 0); err == nil {
+		t.Error("Expected error for zone without types")
+	}
+}
+
+// TestEncodeTzfileLeapSeconds tests that leap records survive a round trip
+func TestEncodeTzfileLeapSeconds(t *testing.T) {
+	path := filepath.Join("/usr/share/zoneinfo", "right", "Europe", "London")
+	if _, err := os.Stat(path); err != nil {
+		t.Skip("right/ zoneinfo not available")
+	}
+
+	orig, err := ParseTzfileFromFile(path, nil)
+	if err != nil {
+		t.Fatalf("cannot load: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTzfile(&buf, orig, 0); err != nil {
+		t.Fatalf("WriteTzfile failed: %v", err)
+	}
+	parsed, err := ParseTzfileData("right/Europe/London", buf.Bytes(), nil)
+	if err != nil {
+		t.Fatalf("cannot parse written data: %v", err)
+	}
+
+	if len(parsed.LeapTimes) != len(orig.LeapTimes) {
+		t.Fatalf("Expected %d leap records, got %d", len(orig.LeapTimes), len(parsed.LeapTimes))
+	}
+	for i := range orig.LeapTimes {
+		if parsed.LeapTimes[i] != orig.LeapTimes[i] {
+			t.Errorf("Leap record %d: got %v, want %v", i, parsed.LeapTimes[i], orig.LeapTimes[i])
+		}
+	}
+	for i := range orig.Type {
+		if parsed.Type[i].IsStd != orig.Type[i].IsStd || parsed.Type[i].IsUtc != orig.Type[i].IsUtc {
+			t.Errorf("Type %d indicators differ", i)
+		}
+	}
+	compareTzOffsets(t, "right/Europe/London", orig, parsed, tzSampleInstants(orig))
+}
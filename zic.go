@@ -0,0 +1,1262 @@
+package timelib
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Day codes of a rule's ON field
+const (
+	zicDayOfMonth = iota // a fixed day, e.g. "5"
+	zicDowGEQ            // a weekday on or after a day, e.g. "Sun>=8"
+	zicDowLEQ            // a weekday on or before a day, e.g. "lastSun" or "Sun<=25"
+)
+
+// Year range limits for "minimum" and "maximum"
+const (
+	zicMinYear = math.MinInt32
+	zicMaxYear = math.MaxInt32
+)
+
+// zicSourceFiles are the IANA source files read by LoadTzSourceDir, in the
+// order zic is usually invoked with
+var zicSourceFiles = []string{
+	"africa", "antarctica", "asia", "australasia", "europe",
+	"northamerica", "southamerica", "etcetera", "backward", "factory",
+}
+
+var zicLineCodes = []string{"Rule", "Zone", "Link"}
+
+var zicMonthNames = []string{
+	"January", "February", "March", "April", "May", "June",
+	"July", "August", "September", "October", "November", "December",
+}
+
+var zicWeekdayNames = []string{
+	"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday",
+}
+
+var zicMonthLengths = [2][12]int{
+	{31, 28, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31},
+	{31, 29, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31},
+}
+
+// zicRule is a Rule line, also used for the UNTIL field of zone lines
+type zicRule struct {
+	loYear, hiYear int64
+	month          int
+	dayCode        int
+	dayOfMonth     int
+	weekday        int
+	tod            int64
+	todIsStd       bool
+	todIsUT        bool
+	save           int64
+	isDst          bool
+	letters        string
+
+	// Working state while compiling a zone
+	todo bool
+	temp int64
+}
+
+// zicZone is a single Zone line or continuation line
+type zicZone struct {
+	filename string
+	line     int
+
+	stdoff     int64
+	ruleName   string // name of the rules; empty if the zone has none or a fixed save
+	save       int64
+	isDst      bool
+	format     string
+	formatSpec byte // 's' or 'z' if the format contains %s or %z
+
+	hasUntil  bool
+	untilRule zicRule
+	untilTime int64
+	untilYear int64
+	rules     []zicRule
+}
+
+// TzSource holds parsed IANA time zone source data (Rule, Zone and Link
+// lines, in the full or the compact tzdata.zi form) and compiles it into
+// TzInfo structures like zic does
+type TzSource struct {
+	Version string
+
+	rules     map[string][]zicRule
+	zones     map[string][]zicZone
+	links     map[string]string
+	locations map[string]TLocInfo
+}
+
+// NewTzSource creates an empty time zone source
+func NewTzSource() *TzSource {
+	return &TzSource{
+		rules: make(map[string][]zicRule),
+		zones: make(map[string][]zicZone),
+		links: make(map[string]string),
+	}
+}
+
+// LoadTzSourceDir reads the IANA source files from a directory. If the
+// directory contains a tzdata.zi file only that is read; otherwise the
+// individual continent files are. zone.tab, zone1970.tab and the version
+// file are picked up as well if present.
+func LoadTzSourceDir(directory string) (*TzSource, error) {
+	src := NewTzSource()
+
+	files := []string{"tzdata.zi"}
+	if _, err := os.Stat(filepath.Join(directory, "tzdata.zi")); err != nil {
+		files = zicSourceFiles
+	}
+
+	found := false
+	for _, name := range files {
+		path := filepath.Join(directory, name)
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		err = src.Parse(f, path)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		found = true
+	}
+	if !found {
+		return nil, fmt.Errorf("no time zone source files found in %s", directory)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(directory, "version")); err == nil && src.Version == "" {
+		src.Version = strings.TrimSpace(string(data))
+	}
+
+	tzdb := &TzDB{}
+	loadZoneTabs(tzdb, os.DirFS(directory))
+	src.locations = tzdb.Locations
+
+	return src, nil
+}
+
+// Parse reads Rule, Zone and Link lines from r. The filename is only used in
+// error messages.
+func (src *TzSource) Parse(r io.Reader, filename string) error {
+	scanner := bufio.NewScanner(r)
+	lineNr := 0
+	var zoneName string
+	continuation := false
+
+	for scanner.Scan() {
+		lineNr++
+		line := scanner.Text()
+
+		// The tzdata.zi header carries the release
+		if lineNr == 1 && strings.HasPrefix(line, "# version ") {
+			src.Version = strings.TrimSpace(strings.TrimPrefix(line, "# version "))
+		}
+
+		fields, err := zicFields(line)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %v", filename, lineNr, err)
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		if continuation {
+			zone, err := parseZicZoneFields(fields, filename, lineNr)
+			if err != nil {
+				return fmt.Errorf("%s:%d: %v", filename, lineNr, err)
+			}
+			src.zones[zoneName] = append(src.zones[zoneName], zone)
+			continuation = zone.hasUntil
+			continue
+		}
+
+		switch zicLookup(fields[0], zicLineCodes) {
+		case 0: // Rule
+			if len(fields) != 10 {
+				return fmt.Errorf("%s:%d: wrong number of fields on Rule line", filename, lineNr)
+			}
+			rule, err := parseZicRuleFields(fields[2:])
+			if err != nil {
+				return fmt.Errorf("%s:%d: %v", filename, lineNr, err)
+			}
+			src.rules[fields[1]] = append(src.rules[fields[1]], rule)
+
+		case 1: // Zone
+			if len(fields) < 5 || len(fields) > 9 {
+				return fmt.Errorf("%s:%d: wrong number of fields on Zone line", filename, lineNr)
+			}
+			zoneName = fields[1]
+			if _, exists := src.zones[zoneName]; exists {
+				return fmt.Errorf("%s:%d: duplicate zone name %s", filename, lineNr, zoneName)
+			}
+			zone, err := parseZicZoneFields(fields[2:], filename, lineNr)
+			if err != nil {
+				return fmt.Errorf("%s:%d: %v", filename, lineNr, err)
+			}
+			src.zones[zoneName] = []zicZone{zone}
+			continuation = zone.hasUntil
+
+		case 2: // Link
+			if len(fields) != 3 {
+				return fmt.Errorf("%s:%d: wrong number of fields on Link line", filename, lineNr)
+			}
+			src.links[fields[2]] = fields[1]
+
+		default:
+			// Leap and Expires lines belong to the leapseconds file
+			if strings.EqualFold(fields[0], "Leap") || strings.EqualFold(fields[0], "Expires") {
+				continue
+			}
+			return fmt.Errorf("%s:%d: input line of unknown type", filename, lineNr)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if continuation {
+		return fmt.Errorf("%s: expected zone continuation line not found", filename)
+	}
+	return nil
+}
+
+// zicFields splits a source line into fields, dropping comments and
+// honouring double quotes
+func zicFields(line string) ([]string, error) {
+	var fields []string
+	var field strings.Builder
+	inField, inQuote := false, false
+
+	for _, c := range line {
+		switch {
+		case inQuote:
+			if c == '"' {
+				inQuote = false
+			} else {
+				field.WriteRune(c)
+			}
+		case c == '"':
+			inQuote, inField = true, true
+		case c == '#':
+			if inField {
+				fields = append(fields, field.String())
+			}
+			return fields, nil
+		case unicode.IsSpace(c):
+			if inField {
+				fields = append(fields, field.String())
+				field.Reset()
+				inField = false
+			}
+		default:
+			field.WriteRune(c)
+			inField = true
+		}
+	}
+
+	if inQuote {
+		return nil, fmt.Errorf("odd number of quotation marks")
+	}
+	if inField {
+		fields = append(fields, field.String())
+	}
+	return fields, nil
+}
+
+// zicLookup finds a word in a table, allowing unambiguous case insensitive
+// abbreviations like zic does. It returns -1 if there is no match.
+func zicLookup(word string, table []string) int {
+	for i, w := range table {
+		if strings.EqualFold(word, w) {
+			return i
+		}
+	}
+
+	found := -1
+	for i, w := range table {
+		if word != "" && len(word) <= len(w) && strings.EqualFold(word, w[:len(word)]) {
+			if found >= 0 {
+				return -1
+			}
+			found = i
+		}
+	}
+	return found
+}
+
+// parseZicTime parses [-]h[:mm[:ss[.frac]]], with "-" meaning zero
+func parseZicTime(s string) (int64, error) {
+	if s == "-" || s == "" {
+		return 0, nil
+	}
+
+	sign := int64(1)
+	if s[0] == '-' {
+		sign = -1
+		s = s[1:]
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+
+	var result int64
+	for i, part := range parts {
+		if i == 2 {
+			// Fractional seconds are rounded to the nearest second
+			if dot := strings.IndexByte(part, '.'); dot >= 0 {
+				frac, err := strconv.ParseFloat("0"+part[dot:], 64)
+				if err != nil {
+					return 0, fmt.Errorf("invalid time %q", s)
+				}
+				part = part[:dot]
+				if frac >= 0.5 {
+					result++
+				}
+			}
+		}
+		n, err := strconv.ParseInt(part, 10, 64)
+		if err != nil || n < 0 || (i > 0 && n >= 60) {
+			return 0, fmt.Errorf("invalid time %q", s)
+		}
+		switch i {
+		case 0:
+			result += n * 3600
+		case 1:
+			result += n * 60
+		case 2:
+			result += n
+		}
+	}
+	return sign * result, nil
+}
+
+// parseZicAt parses an AT or UNTIL time with its optional w/s/u suffix
+func parseZicAt(s string, rule *zicRule) error {
+	if s != "" {
+		switch s[len(s)-1] {
+		case 's':
+			rule.todIsStd = true
+			s = s[:len(s)-1]
+		case 'w':
+			s = s[:len(s)-1]
+		case 'u', 'g', 'z':
+			rule.todIsStd = true
+			rule.todIsUT = true
+			s = s[:len(s)-1]
+		}
+	}
+
+	tod, err := parseZicTime(s)
+	if err != nil {
+		return err
+	}
+	rule.tod = tod
+	return nil
+}
+
+// parseZicYear parses a FROM year
+func parseZicYear(s string) (int64, error) {
+	switch zicLookup(s, []string{"minimum", "maximum"}) {
+	case 0:
+		return zicMinYear, nil
+	case 1:
+		return zicMaxYear, nil
+	}
+	year, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid year %q", s)
+	}
+	return year, nil
+}
+
+// parseZicMonthDay fills in month and ON fields of a rule
+func parseZicMonthDay(month, day string, rule *zicRule) error {
+	rule.month = zicLookup(month, zicMonthNames)
+	if rule.month < 0 {
+		return fmt.Errorf("invalid month name %q", month)
+	}
+
+	switch {
+	case len(day) > 4 && strings.EqualFold(day[:4], "last"):
+		rule.dayCode = zicDowLEQ
+		rule.dayOfMonth = zicMonthLengths[1][rule.month]
+		rule.weekday = zicLookup(day[4:], zicWeekdayNames)
+		if rule.weekday < 0 {
+			return fmt.Errorf("invalid weekday name %q", day)
+		}
+		return nil
+
+	case strings.Contains(day, ">=") || strings.Contains(day, "<="):
+		op := ">="
+		rule.dayCode = zicDowGEQ
+		if strings.Contains(day, "<=") {
+			op = "<="
+			rule.dayCode = zicDowLEQ
+		}
+		wday, dom, _ := strings.Cut(day, op)
+		rule.weekday = zicLookup(wday, zicWeekdayNames)
+		if rule.weekday < 0 {
+			return fmt.Errorf("invalid weekday name %q", day)
+		}
+		day = dom
+
+	default:
+		rule.dayCode = zicDayOfMonth
+	}
+
+	d, err := strconv.Atoi(day)
+	if err != nil || d < 1 || d > zicMonthLengths[1][rule.month] {
+		return fmt.Errorf("invalid day of month %q", day)
+	}
+	rule.dayOfMonth = d
+	return nil
+}
+
+// parseZicSave parses a SAVE field with its optional s/d suffix
+func parseZicSave(s string) (int64, bool, error) {
+	explicit, isDst := false, false
+	if s != "" {
+		switch s[len(s)-1] {
+		case 'd':
+			explicit, isDst = true, true
+			s = s[:len(s)-1]
+		case 's':
+			explicit = true
+			s = s[:len(s)-1]
+		}
+	}
+
+	save, err := parseZicTime(s)
+	if err != nil {
+		return 0, false, err
+	}
+	if !explicit {
+		isDst = save != 0
+	}
+	return save, isDst, nil
+}
+
+// parseZicRuleFields parses the fields after "Rule NAME"
+func parseZicRuleFields(fields []string) (zicRule, error) {
+	var rule zicRule
+	var err error
+
+	if rule.loYear, err = parseZicYear(fields[0]); err != nil {
+		return rule, err
+	}
+
+	switch zicLookup(fields[1], []string{"minimum", "maximum", "only"}) {
+	case 0:
+		rule.hiYear = zicMinYear
+	case 1:
+		rule.hiYear = zicMaxYear
+	case 2:
+		rule.hiYear = rule.loYear
+	default:
+		if rule.hiYear, err = strconv.ParseInt(fields[1], 10, 64); err != nil {
+			return rule, fmt.Errorf("invalid ending year %q", fields[1])
+		}
+	}
+	if rule.loYear > rule.hiYear {
+		return rule, fmt.Errorf("starting year greater than ending year")
+	}
+
+	if fields[2] != "-" && fields[2] != "" {
+		return rule, fmt.Errorf("year type %q is unsupported", fields[2])
+	}
+
+	if err = parseZicMonthDay(fields[3], fields[4], &rule); err != nil {
+		return rule, err
+	}
+	if err = parseZicAt(fields[5], &rule); err != nil {
+		return rule, err
+	}
+	if rule.save, rule.isDst, err = parseZicSave(fields[6]); err != nil {
+		return rule, err
+	}
+
+	if fields[7] != "-" {
+		rule.letters = fields[7]
+	}
+	return rule, nil
+}
+
+// parseZicZoneFields parses STDOFF RULES FORMAT [UNTIL] of a zone line
+func parseZicZoneFields(fields []string, filename string, lineNr int) (zicZone, error) {
+	zone := zicZone{filename: filename, line: lineNr}
+	var err error
+
+	if len(fields) < 3 || len(fields) > 7 {
+		return zone, fmt.Errorf("wrong number of fields on zone line")
+	}
+
+	if zone.stdoff, err = parseZicTime(fields[0]); err != nil {
+		return zone, err
+	}
+
+	// RULES is "-", a fixed amount of saved time, or the name of a rule set
+	if fields[1] != "-" {
+		if c := fields[1][0]; c == '-' || (c >= '0' && c <= '9') {
+			if zone.save, zone.isDst, err = parseZicSave(fields[1]); err != nil {
+				return zone, err
+			}
+		} else {
+			zone.ruleName = fields[1]
+		}
+	}
+
+	zone.format = fields[2]
+	if strings.Contains(zone.format, "%z") {
+		zone.formatSpec = 'z'
+	} else if strings.Contains(zone.format, "%s") {
+		zone.formatSpec = 's'
+	}
+	if zone.formatSpec != 0 && strings.Contains(zone.format, "/") {
+		return zone, fmt.Errorf("format %q combines '/' and '%%'", zone.format)
+	}
+
+	if len(fields) > 3 {
+		zone.hasUntil = true
+		until := &zone.untilRule
+		if until.loYear, err = parseZicYear(fields[3]); err != nil {
+			return zone, err
+		}
+		until.hiYear = until.loYear
+		zone.untilYear = until.loYear
+
+		month, day, tod := "Jan", "1", "0"
+		if len(fields) > 4 {
+			month = fields[4]
+		}
+		if len(fields) > 5 {
+			day = fields[5]
+		}
+		if len(fields) > 6 {
+			tod = fields[6]
+		}
+		if err = parseZicMonthDay(month, day, until); err != nil {
+			return zone, err
+		}
+		if err = parseZicAt(tod, until); err != nil {
+			return zone, err
+		}
+		zone.untilTime = zicRuleTime(until, until.loYear)
+	}
+
+	return zone, nil
+}
+
+// zicDaysSinceEpoch returns the number of days from 1970-01-01 to the given date
+func zicDaysSinceEpoch(year int64, month, day int) int64 {
+	days := TsAtStartOfYear(year) / SECS_PER_DAY
+	leap := 0
+	if IsLeapYear(year) {
+		leap = 1
+	}
+	for m := 0; m < month; m++ {
+		days += int64(zicMonthLengths[leap][m])
+	}
+	return days + int64(day) - 1
+}
+
+// zicRuleTime returns the local time (in seconds since the epoch, before any
+// offset is applied) at which a rule takes effect in the given year
+func zicRuleTime(rule *zicRule, year int64) int64 {
+	days := zicDaysSinceEpoch(year, rule.month, rule.dayOfMonth)
+
+	if rule.dayCode != zicDayOfMonth {
+		// 1970-01-01 was a Thursday
+		wday := int((days%7 + 7 + 4) % 7)
+		for wday != rule.weekday {
+			if rule.dayCode == zicDowGEQ {
+				days++
+				wday = (wday + 1) % 7
+			} else {
+				days--
+				wday = (wday + 6) % 7
+			}
+		}
+	}
+
+	return days*SECS_PER_DAY + rule.tod
+}
+
+// zicAbbreviation formats the abbreviation of a zone line for the given
+// rule letters, DST flag and saved time. If quote is set, abbreviations with
+// characters other than letters are enclosed in angle brackets.
+func zicAbbreviation(zone *zicZone, letters string, isDst bool, save int64, quote bool) string {
+	var abbr string
+
+	if slash := strings.IndexByte(zone.format, '/'); slash >= 0 {
+		if isDst {
+			abbr = zone.format[slash+1:]
+		} else {
+			abbr = zone.format[:slash]
+		}
+	} else {
+		switch zone.formatSpec {
+		case 'z':
+			abbr = strings.Replace(zone.format, "%z", zicOffsetAbbreviation(zone.stdoff+save), 1)
+		case 's':
+			abbr = strings.Replace(zone.format, "%s", letters, 1)
+		default:
+			abbr = zone.format
+		}
+	}
+
+	if !quote {
+		return abbr
+	}
+	for _, c := range abbr {
+		if !(c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z') {
+			return "<" + abbr + ">"
+		}
+	}
+	return abbr
+}
+
+// zicOffsetAbbreviation formats a UT offset like "%z" does: "+05", "-0330"
+func zicOffsetAbbreviation(offset int64) string {
+	sign := '+'
+	if offset < 0 {
+		sign = '-'
+		offset = -offset
+	}
+
+	seconds := offset % 60
+	minutes := offset / 60 % 60
+	hours := offset / 3600
+
+	abbr := fmt.Sprintf("%c%02d", sign, hours)
+	if minutes != 0 || seconds != 0 {
+		abbr += fmt.Sprintf("%02d", minutes)
+		if seconds != 0 {
+			abbr += fmt.Sprintf("%02d", seconds)
+		}
+	}
+	return abbr
+}
+
+// ZoneNames returns the names of all zones and links in the source, sorted
+func (src *TzSource) ZoneNames() []string {
+	names := make([]string, 0, len(src.zones)+len(src.links))
+	for name := range src.zones {
+		names = append(names, name)
+	}
+	for name := range src.links {
+		if _, isZone := src.zones[name]; !isZone {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolveLink follows Link lines to the zone they refer to
+func (src *TzSource) resolveLink(name string) (string, error) {
+	for i := 0; i < 16; i++ {
+		if _, ok := src.zones[name]; ok {
+			return name, nil
+		}
+		target, ok := src.links[name]
+		if !ok {
+			return "", fmt.Errorf("timezone '%s' not found in source", name)
+		}
+		name = target
+	}
+	return "", fmt.Errorf("link loop for timezone '%s'", name)
+}
+
+// zicTransition is a transition to a local time type while compiling
+type zicTransition struct {
+	at  int64
+	typ int
+}
+
+// zicCompiler collects the local time types and transitions of one zone
+type zicCompiler struct {
+	types       []TTInfo
+	abbrs       []string
+	transitions []zicTransition
+}
+
+// addType returns the index of a local time type, adding it if needed
+func (c *zicCompiler) addType(offset int64, abbr string, isDst bool) int {
+	dst := 0
+	if isDst {
+		dst = 1
+	}
+	for i, tt := range c.types {
+		if int64(tt.Offset) == offset && tt.IsDst == dst && c.abbrs[i] == abbr {
+			return i
+		}
+	}
+	c.types = append(c.types, TTInfo{Offset: int32(offset), IsDst: dst})
+	c.abbrs = append(c.abbrs, abbr)
+	return len(c.types) - 1
+}
+
+// Compile compiles a zone or link from the source into a TzInfo
+func (src *TzSource) Compile(name string) (*TzInfo, error) {
+	target, err := src.resolveLink(name)
+	if err != nil {
+		return nil, err
+	}
+
+	zones := src.zones[target]
+	for i := range zones {
+		zones[i].rules = nil
+		if zones[i].ruleName == "" {
+			continue
+		}
+		rules, ok := src.rules[zones[i].ruleName]
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: rule '%s' not defined", zones[i].filename, zones[i].line, zones[i].ruleName)
+		}
+		zones[i].rules = make([]zicRule, len(rules))
+		copy(zones[i].rules, rules)
+	}
+
+	c := &zicCompiler{}
+	defaultType, err := src.outzone(c, zones)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+
+	tz := c.build(name, defaultType)
+	tz.PosixString = zicPosixString(&zones[len(zones)-1])
+	if tz.PosixString != "" {
+		tz.PosixInfo, _ = ParsePosixString(tz.PosixString, tz)
+	}
+
+	setDefaultLocation(tz)
+	if loc, ok := src.locations[name]; ok {
+		tz.Location = loc
+	}
+	tz.Bc = 0
+	if _, ok := src.locations[name]; ok || (src.locations == nil && name == target) {
+		tz.Bc = 1
+	}
+
+	return tz, nil
+}
+
+// zicYearRange returns the range of years to generate transitions for
+func (src *TzSource) zicYearRange(zones []zicZone) (int64, int64) {
+	minYear, maxYear := int64(math.MaxInt64), int64(2037)
+	for _, zone := range zones {
+		if zone.hasUntil {
+			minYear = min(minYear, zone.untilYear)
+			maxYear = max(maxYear, zone.untilYear+1)
+		}
+		for _, rule := range zone.rules {
+			if rule.loYear != zicMinYear {
+				minYear = min(minYear, rule.loYear)
+				maxYear = max(maxYear, rule.loYear+1)
+			}
+			if rule.hiYear != zicMaxYear {
+				maxYear = max(maxYear, rule.hiYear+1)
+			}
+		}
+	}
+	if minYear == math.MaxInt64 {
+		minYear = 1900
+	}
+	return minYear, maxYear
+}
+
+// outzone generates the transitions of a zone, following zic's algorithm.
+// It returns the type that is in effect before the first transition.
+func (src *TzSource) outzone(c *zicCompiler, zones []zicZone) (int, error) {
+	minYear, maxYear := src.zicYearRange(zones)
+
+	defaultType := -1
+	var startTime int64
+	startTTisStd, startTTisUT := false, false
+
+	for i := range zones {
+		zone := &zones[i]
+		save := int64(0)
+		useStart := i > 0
+		useUntil := i < len(zones)-1
+		stdoff := zone.stdoff
+		startBuf := ""
+		startBufSet := false
+		startOff := zone.stdoff
+		var untilTime int64
+
+		if len(zone.rules) == 0 {
+			save = zone.save
+			abbr := zicAbbreviation(zone, "", zone.isDst, save, false)
+			typ := c.addType(stdoff+save, abbr, zone.isDst)
+			if useStart {
+				c.transitions = append(c.transitions, zicTransition{startTime, typ})
+				useStart = false
+			} else {
+				defaultType = typ
+			}
+		} else {
+			for year := minYear; year <= maxYear; year++ {
+				if useUntil && year > zone.untilYear {
+					break
+				}
+
+				// Mark the rules to apply in this year
+				for j := range zone.rules {
+					rule := &zone.rules[j]
+					rule.todo = year >= rule.loYear && year <= rule.hiYear
+					if rule.todo {
+						rule.temp = zicRuleTime(rule, year)
+					}
+				}
+
+				for {
+					if useUntil {
+						// Turn the until time into UT with the current offsets
+						untilTime = zone.untilTime
+						if !zone.untilRule.todIsUT {
+							untilTime -= stdoff
+						}
+						if !zone.untilRule.todIsStd {
+							untilTime -= save
+						}
+					}
+
+					// Find the rule that takes effect earliest in the year
+					k := -1
+					var ktime int64
+					for j := range zone.rules {
+						rule := &zone.rules[j]
+						if !rule.todo {
+							continue
+						}
+						offset := int64(0)
+						if !rule.todIsUT {
+							offset = stdoff
+						}
+						if !rule.todIsStd {
+							offset += save
+						}
+						jtime := rule.temp - offset
+						if k < 0 || jtime < ktime {
+							k = j
+							ktime = jtime
+						} else if jtime == ktime {
+							return 0, fmt.Errorf("%s:%d: two rules for same instant", zone.filename, zone.line)
+						}
+					}
+					if k < 0 {
+						break
+					}
+
+					rule := &zone.rules[k]
+					rule.todo = false
+					if useUntil && ktime >= untilTime {
+						if !startBufSet && stdoff+rule.save == startOff {
+							startBuf = zicAbbreviation(zone, rule.letters, rule.isDst, rule.save, false)
+							startBufSet = true
+						}
+						break
+					}
+
+					save = rule.save
+					if useStart && ktime == startTime {
+						useStart = false
+					}
+					if useStart {
+						if ktime < startTime {
+							startOff = stdoff + save
+							startBuf = zicAbbreviation(zone, rule.letters, rule.isDst, rule.save, false)
+							startBufSet = true
+							continue
+						}
+						if !startBufSet && startOff == stdoff+save {
+							startBuf = zicAbbreviation(zone, rule.letters, rule.isDst, rule.save, false)
+							startBufSet = true
+						}
+					}
+
+					abbr := zicAbbreviation(zone, rule.letters, rule.isDst, rule.save, false)
+					typ := c.addType(stdoff+rule.save, abbr, rule.isDst)
+					if defaultType < 0 && !rule.isDst {
+						defaultType = typ
+					}
+					c.transitions = append(c.transitions, zicTransition{ktime, typ})
+				}
+			}
+		}
+
+		if useStart {
+			isDst := startOff != zone.stdoff
+			if !startBufSet && zone.formatSpec != 's' {
+				startBuf = zicAbbreviation(zone, "", isDst, save, false)
+				startBufSet = true
+			}
+			if !startBufSet {
+				return 0, fmt.Errorf("%s:%d: can't determine time zone abbreviation to use just after until time", zone.filename, zone.line)
+			}
+			typ := c.addType(startOff, startBuf, isDst)
+			if defaultType < 0 && !isDst {
+				defaultType = typ
+			}
+			c.transitions = append(c.transitions, zicTransition{startTime, typ})
+		}
+
+		// Set the start time for the next zone line
+		if useUntil {
+			startTTisStd = zone.untilRule.todIsStd
+			startTTisUT = zone.untilRule.todIsUT
+			startTime = zone.untilTime
+			if !startTTisStd {
+				startTime -= save
+			}
+			if !startTTisUT {
+				startTime -= stdoff
+			}
+		}
+	}
+
+	if defaultType < 0 {
+		defaultType = 0
+	}
+	return defaultType, nil
+}
+
+// build sorts and optimizes the transitions like zic's writezone does and
+// returns the resulting TzInfo, with the default type as type 0
+func (c *zicCompiler) build(name string, defaultType int) *TzInfo {
+	sort.SliceStable(c.transitions, func(i, j int) bool {
+		return c.transitions[i].at < c.transitions[j].at
+	})
+
+	// Drop transitions that are overridden by a later one taking effect at
+	// the same or an earlier local time, and those that do not change anything
+	trans := make([]zicTransition, 0, len(c.transitions))
+	for _, tr := range c.transitions {
+		if n := len(trans); n > 0 {
+			prevType := defaultType
+			if n > 1 {
+				prevType = trans[n-2].typ
+			}
+			if tr.at+int64(c.types[trans[n-1].typ].Offset) <= trans[n-1].at+int64(c.types[prevType].Offset) {
+				trans[n-1].typ = tr.typ
+				continue
+			}
+		}
+
+		prevType := defaultType
+		if n := len(trans); n > 0 {
+			prevType = trans[n-1].typ
+		}
+		if len(trans) == 0 && tr.typ == defaultType {
+			continue
+		}
+		if len(trans) > 0 && c.types[prevType].Offset == c.types[tr.typ].Offset &&
+			c.types[prevType].IsDst == c.types[tr.typ].IsDst && c.abbrs[prevType] == c.abbrs[tr.typ] {
+			continue
+		}
+		trans = append(trans, tr)
+	}
+
+	// Renumber the types in order of use, with the default type first
+	mapping := make(map[int]int)
+	tz := &TzInfo{Name: name}
+	var abbrs strings.Builder
+	abbrIdx := make(map[string]int)
+
+	useType := func(old int) uint8 {
+		if idx, ok := mapping[old]; ok {
+			return uint8(idx)
+		}
+		abbr := c.abbrs[old]
+		pos, ok := abbrIdx[abbr]
+		if !ok {
+			pos = abbrs.Len()
+			abbrIdx[abbr] = pos
+			abbrs.WriteString(abbr)
+			abbrs.WriteByte(0)
+		}
+		tt := c.types[old]
+		tt.AbbrIdx = pos
+		tz.Type = append(tz.Type, tt)
+		mapping[old] = len(tz.Type) - 1
+		return uint8(len(tz.Type) - 1)
+	}
+
+	useType(defaultType)
+	for _, tr := range trans {
+		tz.Trans = append(tz.Trans, tr.at)
+		tz.TransIdx = append(tz.TransIdx, useType(tr.typ))
+	}
+	tz.TimezoneAbbr = abbrs.String()
+
+	tz.Bit64.Timecnt = uint64(len(tz.Trans))
+	tz.Bit64.Typecnt = uint64(len(tz.Type))
+	tz.Bit64.Charcnt = uint64(len(tz.TimezoneAbbr))
+
+	return tz
+}
+
+// zicRuleCmp orders rules by their last year and start date, like zic's
+// rule_cmp; nil sorts first
+func zicRuleCmp(a, b *zicRule) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	if a.hiYear != b.hiYear {
+		if a.hiYear < b.hiYear {
+			return -1
+		}
+		return 1
+	}
+	if a.hiYear == zicMaxYear {
+		return 0
+	}
+	if a.month != b.month {
+		return a.month - b.month
+	}
+	return a.dayOfMonth - b.dayOfMonth
+}
+
+// zicPosixString returns the POSIX TZ string for the last line of a zone,
+// or an empty string if the zone's future cannot be expressed as one
+func zicPosixString(zone *zicZone) string {
+	var lastRules [2]*zicRule
+	for i := range zone.rules {
+		rule := &zone.rules[i]
+		dst := 0
+		if rule.isDst {
+			dst = 1
+		}
+		cmp := zicRuleCmp(lastRules[dst], rule)
+		if cmp < 0 {
+			lastRules[dst] = rule
+		} else if cmp == 0 {
+			return ""
+		}
+	}
+
+	stdRule, dstRule := lastRules[0], lastRules[1]
+	var dstCmp int
+	switch {
+	case len(zone.rules) > 0:
+		dstCmp = zicRuleCmp(dstRule, stdRule)
+	case zone.isDst:
+		dstCmp = 1
+	default:
+		dstCmp = -1
+	}
+
+	stdZone, dstZone := zone, zone
+	if dstCmp < 0 {
+		// Standard time all year
+		dstRule = nil
+	} else if dstCmp > 0 {
+		// DST all year, expressed as a fake zone with negative DST
+		save := zone.save
+		if dstRule != nil {
+			save = dstRule.save
+		}
+		if save >= 0 {
+			stdZone = &zicZone{stdoff: zone.stdoff + 2*save, format: "XXX"}
+			dstZone = &zicZone{stdoff: stdZone.stdoff, format: zone.format, formatSpec: zone.formatSpec}
+		}
+
+		dstr := &zicRule{month: 0, dayCode: zicDayOfMonth, dayOfMonth: 1, isDst: true}
+		if save < 0 {
+			dstr.save = save
+		} else {
+			dstr.save = -save
+		}
+		if dstRule != nil {
+			dstr.letters = dstRule.letters
+		}
+		stdr := &zicRule{month: 11, dayCode: zicDayOfMonth, dayOfMonth: 31, tod: SECS_PER_DAY + dstr.save}
+		if save < 0 && stdRule != nil {
+			stdr.letters = stdRule.letters
+		}
+		dstRule, stdRule = dstr, stdr
+	}
+
+	stdLetters := ""
+	if stdRule != nil {
+		stdLetters = stdRule.letters
+	}
+
+	var result strings.Builder
+	result.WriteString(zicAbbreviation(stdZone, stdLetters, false, 0, true))
+	offset, ok := zicPosixOffset(-stdZone.stdoff)
+	if !ok {
+		return ""
+	}
+	result.WriteString(offset)
+	if dstRule == nil {
+		return result.String()
+	}
+
+	result.WriteString(zicAbbreviation(dstZone, dstRule.letters, dstRule.isDst, dstRule.save, true))
+	if dstRule.save != 3600 {
+		offset, ok := zicPosixOffset(-(dstZone.stdoff + dstRule.save))
+		if !ok {
+			return ""
+		}
+		result.WriteString(offset)
+	}
+
+	for _, rule := range []*zicRule{dstRule, stdRule} {
+		spec, ok := zicPosixRule(rule, dstRule.save, stdZone.stdoff)
+		if !ok {
+			return ""
+		}
+		result.WriteByte(',')
+		result.WriteString(spec)
+	}
+	return result.String()
+}
+
+// zicPosixOffset formats an offset for a POSIX TZ string
+func zicPosixOffset(offset int64) (string, bool) {
+	sign := ""
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+
+	seconds := offset % 60
+	minutes := offset / 60 % 60
+	hours := offset / 3600
+	if hours >= 24*7 {
+		return "", false
+	}
+
+	result := fmt.Sprintf("%s%d", sign, hours)
+	if minutes != 0 || seconds != 0 {
+		result += fmt.Sprintf(":%02d", minutes)
+		if seconds != 0 {
+			result += fmt.Sprintf(":%02d", seconds)
+		}
+	}
+	return result, true
+}
+
+// zicPosixRule formats the date and time of a rule for a POSIX TZ string
+func zicPosixRule(rule *zicRule, save, stdoff int64) (string, bool) {
+	tod := rule.tod
+	var result string
+
+	if rule.dayCode == zicDayOfMonth {
+		if rule.dayOfMonth == 29 && rule.month == 1 {
+			return "", false
+		}
+		total := 0
+		for m := 0; m < rule.month; m++ {
+			total += zicMonthLengths[0][m]
+		}
+		// Omit the "J" in Jan and Feb, as that's shorter
+		if rule.month <= 1 {
+			result = strconv.Itoa(total + rule.dayOfMonth - 1)
+		} else {
+			result = "J" + strconv.Itoa(total+rule.dayOfMonth)
+		}
+	} else {
+		var week int
+		wday := rule.weekday
+		if rule.dayCode == zicDowGEQ {
+			wdayoff := (rule.dayOfMonth - 1) % 7
+			wday -= wdayoff
+			tod += int64(wdayoff) * SECS_PER_DAY
+			week = 1 + (rule.dayOfMonth-1)/7
+		} else if rule.dayOfMonth == zicMonthLengths[1][rule.month] {
+			week = 5
+		} else {
+			wdayoff := rule.dayOfMonth % 7
+			wday -= wdayoff
+			tod += int64(wdayoff) * SECS_PER_DAY
+			week = rule.dayOfMonth / 7
+		}
+		if wday < 0 {
+			wday += 7
+		}
+		result = fmt.Sprintf("M%d.%d.%d", rule.month+1, week, wday)
+	}
+
+	if rule.todIsUT {
+		tod += stdoff
+	}
+	if rule.todIsStd && !rule.isDst {
+		tod += save
+	}
+	if tod != 2*3600 {
+		offset, ok := zicPosixOffset(tod)
+		if !ok {
+			return "", false
+		}
+		result += "/" + offset
+	}
+	return result, true
+}
+
+// CompileDB compiles all zones and links into a timezone database. The zone
+// data is stored in the same format as the builtin database.
+func (src *TzSource) CompileDB() (*TzDB, error) {
+	names := src.ZoneNames()
+	sort.SliceStable(names, func(i, j int) bool {
+		return strings.ToLower(names[i]) < strings.ToLower(names[j])
+	})
+
+	version := src.Version
+	if version == "" {
+		version = "custom"
+	}
+	tzdb := &TzDB{Version: version}
+
+	var data []byte
+	for _, name := range names {
+		tz, err := src.Compile(name)
+		if err != nil {
+			return nil, err
+		}
+		encoded, err := EncodePHPTzfile(tz, tz.Bc == 1)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+
+		tzdb.Index = append(tzdb.Index, TzDBIndexEntry{ID: name, Pos: len(data)})
+		data = append(data, encoded...)
+	}
+
+	tzdb.Data = data
+	tzdb.IndexSize = len(tzdb.Index)
+	return tzdb, nil
+}
+
+// CompileTzSourceDir compiles the IANA source files in a directory into a
+// timezone database, see LoadTzSourceDir
+func CompileTzSourceDir(directory string) (*TzDB, error) {
+	src, err := LoadTzSourceDir(directory)
+	if err != nil {
+		return nil, err
+	}
+	return src.CompileDB()
+}
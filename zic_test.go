@@ -0,0 +1,204 @@
+package timelib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const zicSourceDir = "/usr/share/zoneinfo"
+
+// zicTestSource is a small source file using both the long and the compact
+// tzdata.zi syntax
+const zicTestSource = `# version 2099z
+# Long form
+Rule	US	1967	2006	-	Oct	lastSun	2:00	0	S
+Rule	US	1967	1973	-	Apr	lastSun	2:00	1:00	D
+Rule	US	1974	only	-	Jan	6	2:00	1:00	D
+Rule	US	1975	only	-	Feb	lastSun	2:00	1:00	D
+Rule	US	1976	1986	-	Apr	lastSun	2:00	1:00	D
+Rule	US	1987	2006	-	Apr	Sun>=1	2:00	1:00	D
+Rule	US	2007	max	-	Mar	Sun>=8	2:00	1:00	D
+Rule	US	2007	max	-	Nov	Sun>=1	2:00	0	S
+Zone	Test/Eastern	-5:00	-	EST	1967
+			-5:00	US	E%sT
+Link	Test/Eastern	Test/Alias
+# Compact form
+R E 1981 ma - Mar lastSu 1u 1 S
+R E 1996 ma - O lastSu 1u 0 -
+Z Test/Central 1 - CET 1980
+1 E CE%sT
+Z Test/Fixed 5:30 - +0530
+L Test/Central Test/CentralAlias
+`
+
+// TestTzSourceParse tests parsing and compiling an inline source
+func TestTzSourceParse(t *testing.T) {
+	src := NewTzSource()
+	if err := src.Parse(strings.NewReader(zicTestSource), "test.zi"); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if src.Version != "2099z" {
+		t.Errorf("Expected version 2099z, got %q", src.Version)
+	}
+
+	names := src.ZoneNames()
+	want := []string{"Test/Alias", "Test/Central", "Test/CentralAlias", "Test/Eastern", "Test/Fixed"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("ZoneNames = %v, want %v", names, want)
+	}
+
+	tests := []struct {
+		zone   string
+		posix  string
+		ts     int64
+		offset int32
+		isDst  int
+		abbr   string
+	}{
+		{"Test/Eastern", "EST5EDT,M3.2.0,M11.1.0", 1720000000, -14400, 1, "EDT"},
+		{"Test/Eastern", "EST5EDT,M3.2.0,M11.1.0", 1700000000, -18000, 0, "EST"},
+		{"Test/Eastern", "EST5EDT,M3.2.0,M11.1.0", 110000000, -14400, 1, "EDT"},
+		{"Test/Alias", "EST5EDT,M3.2.0,M11.1.0", 1720000000, -14400, 1, "EDT"},
+		{"Test/Central", "CET-1CEST,M3.5.0,M10.5.0/3", 1720000000, 7200, 1, "CEST"},
+		{"Test/Central", "CET-1CEST,M3.5.0,M10.5.0/3", 1700000000, 3600, 0, "CET"},
+		{"Test/CentralAlias", "CET-1CEST,M3.5.0,M10.5.0/3", 1700000000, 3600, 0, "CET"},
+		{"Test/Fixed", "<+0530>-5:30", 1700000000, 19800, 0, "+0530"},
+	}
+
+	for _, tt := range tests {
+		tz, err := src.Compile(tt.zone)
+		if err != nil {
+			t.Errorf("Compile(%s) failed: %v", tt.zone, err)
+			continue
+		}
+		if tz.PosixString != tt.posix {
+			t.Errorf("%s: POSIX string %q, want %q", tt.zone, tz.PosixString, tt.posix)
+		}
+		to := GetTimeZoneInfo(tt.ts, tz)
+		if to.Offset != tt.offset || to.IsDst != tt.isDst || to.Abbr != tt.abbr {
+			t.Errorf("%s at %d: got %d/%d/%s, want %d/%d/%s", tt.zone, tt.ts, to.Offset, to.IsDst, to.Abbr, tt.offset, tt.isDst, tt.abbr)
+		}
+	}
+
+	if _, err := src.Compile("Test/Missing"); err == nil {
+		t.Error("Expected error for unknown zone")
+	}
+}
+
+// TestTzSourceParseErrors tests rejection of malformed lines
+func TestTzSourceParseErrors(t *testing.T) {
+	for _, input := range []string{
+		"Rule US 1967 2006 - Oct lastSun 2:00\n",
+		"Rule US 1967 2006 - Foo lastSun 2:00 0 S\n",
+		"Zone Test/Bad\n",
+		"Zone Test/Bad -5:00 - EST 1967\n",
+		"Bogus line here\n",
+		"Link Test/Eastern\n",
+	} {
+		if err := NewTzSource().Parse(strings.NewReader(input), "bad.zi"); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
+
+// TestCompileSystemTzdata compiles the system tzdata.zi and compares every
+// zone with the installed binary files
+func TestCompileSystemTzdata(t *testing.T) {
+	if _, err := os.Stat(filepath.Join(zicSourceDir, "tzdata.zi")); err != nil {
+		t.Skip("tzdata.zi not available")
+	}
+
+	src, err := LoadTzSourceDir(zicSourceDir)
+	if err != nil {
+		t.Fatalf("LoadTzSourceDir failed: %v", err)
+	}
+	if src.Version == "" {
+		t.Error("Expected version from tzdata.zi")
+	}
+
+	for _, name := range src.ZoneNames() {
+		path := filepath.Join(zicSourceDir, name)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		want, err := ParseTzfileFromFile(path, nil)
+		if err != nil {
+			continue
+		}
+		got, err := src.Compile(name)
+		if err != nil {
+			t.Errorf("%s: Compile failed: %v", name, err)
+			continue
+		}
+		if got.PosixString != want.PosixString {
+			t.Errorf("%s: POSIX string %q, want %q", name, got.PosixString, want.PosixString)
+		}
+		compareTzOffsets(t, name, want, got, tzSampleInstants(want))
+	}
+}
+
+// TestCompileDB tests building a database from source
+func TestCompileDB(t *testing.T) {
+	src := NewTzSource()
+	if err := src.Parse(strings.NewReader(zicTestSource), "test.zi"); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	tzdb, err := src.CompileDB()
+	if err != nil {
+		t.Fatalf("CompileDB failed: %v", err)
+	}
+	if tzdb.Version != "2099z" {
+		t.Errorf("Expected version 2099z, got %q", tzdb.Version)
+	}
+	if len(tzdb.Index) != 5 {
+		t.Errorf("Expected 5 entries, got %d", len(tzdb.Index))
+	}
+
+	tz, err := ParseTzfile("Test/Eastern", tzdb, nil)
+	if err != nil {
+		t.Fatalf("ParseTzfile failed: %v", err)
+	}
+	if tz.PosixString != "EST5EDT,M3.2.0,M11.1.0" {
+		t.Errorf("Unexpected POSIX string %q", tz.PosixString)
+	}
+	if tz.Bc != 1 {
+		t.Error("Expected zone to be canonical")
+	}
+	if alias, _ := ParseTzfile("Test/Alias", tzdb, nil); alias == nil || alias.Bc != 0 {
+		t.Error("Expected link to be backwards compatible")
+	}
+}
+
+// TestEncodePHPTzfile tests that the PHP variant keeps the location data
+func TestEncodePHPTzfile(t *testing.T) {
+	orig, err := ParseTzfile("Europe/Amsterdam", BuiltinDB(), nil)
+	if err != nil {
+		t.Fatalf("cannot load: %v", err)
+	}
+
+	data, err := EncodePHPTzfile(orig, true)
+	if err != nil {
+		t.Fatalf("EncodePHPTzfile failed: %v", err)
+	}
+	parsed, err := ParseTzfileData("Europe/Amsterdam", data, nil)
+	if err != nil {
+		t.Fatalf("cannot parse written data: %v", err)
+	}
+
+	if parsed.Bc != 1 {
+		t.Error("Expected canonical flag")
+	}
+	if parsed.Location.CountryCode != orig.Location.CountryCode {
+		t.Errorf("Country code %q, want %q", parsed.Location.CountryCode[:2], orig.Location.CountryCode[:2])
+	}
+	if parsed.Location.Comments != orig.Location.Comments {
+		t.Errorf("Comments %q, want %q", parsed.Location.Comments, orig.Location.Comments)
+	}
+	if d := parsed.Location.Latitude - orig.Location.Latitude; d > 1e-5 || d < -1e-5 {
+		t.Errorf("Latitude %f, want %f", parsed.Location.Latitude, orig.Location.Latitude)
+	}
+	compareTzOffsets(t, "Europe/Amsterdam", orig, parsed, tzSampleInstants(orig))
+}